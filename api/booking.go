@@ -0,0 +1,130 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/monoMonu/travel-itinerary-pdf/imaging"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// bookingField is the multipart form field holding the booking JSON.
+const bookingField = "booking"
+
+// bindBooking reads the booking from a JSON body, or from a multipart form
+// with the JSON in the "booking" field and images as files named by the JSON
+// path they fill: "coverImage", "hotels.0.image", "days.1.activities.0.image".
+func bindBooking(c *gin.Context) (types.BookingData, error) {
+	var data types.BookingData
+	if c.ContentType() != binding.MIMEMultipartPOSTForm {
+		err := c.ShouldBindJSON(&data)
+		return data, err
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return data, err
+	}
+
+	// The JSON may come as a plain field or as an uploaded file.
+	var body []byte
+	switch values, files := form.Value[bookingField], form.File[bookingField]; {
+	case len(values) == 1 && len(files) == 0:
+		body = []byte(values[0])
+	case len(values) == 0 && len(files) == 1:
+		if body, err = readUpload(files[0]); err != nil {
+			return data, err
+		}
+	default:
+		return data, fmt.Errorf("multipart form needs exactly one %q field", bookingField)
+	}
+	if err := binding.JSON.BindBody(body, &data); err != nil {
+		return data, err
+	}
+
+	for name, files := range form.File {
+		if name == bookingField {
+			continue
+		}
+		slot, err := imageSlot(&data, name)
+		if err != nil {
+			return data, err
+		}
+		if *slot, err = readUpload(files[0]); err != nil {
+			return data, err
+		}
+	}
+	return data, nil
+}
+
+func readUpload(header *multipart.FileHeader) ([]byte, error) {
+	f, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
+
+// imageSlot resolves an upload field name to the image it fills.
+func imageSlot(data *types.BookingData, name string) (*types.Image, error) {
+	parts := strings.Split(name, ".")
+	index := func(s string, n int) (int, bool) {
+		i, err := strconv.Atoi(s)
+		return i, err == nil && i >= 0 && i < n
+	}
+
+	switch {
+	case len(parts) == 1 && parts[0] == "coverImage":
+		return &data.CoverImage, nil
+	case len(parts) == 3 && parts[0] == "hotels" && parts[2] == "image":
+		if i, ok := index(parts[1], len(data.Hotels)); ok {
+			return &data.Hotels[i].Image, nil
+		}
+	case len(parts) == 5 && parts[0] == "days" && parts[2] == "activities" && parts[4] == "image":
+		if d, ok := index(parts[1], len(data.Days)); ok {
+			if a, ok := index(parts[3], len(data.Days[d].Activities)); ok {
+				return &data.Days[d].Activities[a].Image, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("upload %q does not name an image in the booking", name)
+}
+
+// prepareImages checks every attached image and shrinks it for the PDF.
+func prepareImages(data *types.BookingData) error {
+	prepare := func(img *types.Image, maxSide int, what string) error {
+		if len(*img) == 0 {
+			return nil
+		}
+		out, err := imaging.Prepare(*img, maxSide)
+		if err != nil {
+			return fmt.Errorf("%s: %w", what, err)
+		}
+		*img = out
+		return nil
+	}
+
+	if err := prepare(&data.CoverImage, imaging.CoverSide, "coverImage"); err != nil {
+		return err
+	}
+	for i := range data.Hotels {
+		if err := prepare(&data.Hotels[i].Image, imaging.ThumbnailSide, fmt.Sprintf("hotels.%d.image", i)); err != nil {
+			return err
+		}
+	}
+	for d := range data.Days {
+		for a := range data.Days[d].Activities {
+			what := fmt.Sprintf("days.%d.activities.%d.image", d, a)
+			if err := prepare(&data.Days[d].Activities[a].Image, imaging.ThumbnailSide, what); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monoMonu/travel-itinerary-pdf/config"
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/ical"
+	"github.com/monoMonu/travel-itinerary-pdf/render"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+)
+
+// GenerateCalendar answers with the booking as an iCalendar file to add to
+// a phone or desktop calendar.
+func GenerateCalendar(c *gin.Context) {
+	data, err := bindBooking(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: " + err.Error()})
+		return
+	}
+
+	th, err := clientTheme(c, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: " + err.Error()})
+		return
+	}
+
+	now := time.Now()
+	if data.Options.Deterministic {
+		now = render.ReproducibleDate
+	}
+	var body bytes.Buffer
+	err = ical.Write(&body, data, ical.Config{
+		Company: th.Company.Name,
+		Domain:  calendarDomain(c),
+		Now:     now,
+		Locale:  i18n.Get(data.Options.Locale),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to generate calendar: " + err.Error()})
+		return
+	}
+
+	fileName := fmt.Sprintf("%s_%s_itinerary.ics",
+		utils.SanitizeFileName(data.CustomerName),
+		utils.SanitizeFileName(data.Destination))
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", body.Bytes())
+}
+
+// calendarDomain ends event UIDs. It is the host of the API client's
+// itinerary link, which does not change between requests the way a
+// booking's own link or the request's host might, and the request's host
+// only when there is no such link.
+func calendarDomain(c *gin.Context) string {
+	client, _ := config.ClientByKey(c.GetHeader(config.APIKeyHeader))
+	links := client.Links.Or(config.DefaultLinks)
+	if u, err := url.Parse(links.ItineraryURL); err == nil && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	if host, _, err := net.SplitHostPort(c.Request.Host); err == nil {
+		return host
+	}
+	return c.Request.Host
+}
@@ -0,0 +1,243 @@
+package api
+
+import (
+	"bufio"
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monoMonu/travel-itinerary-pdf/config"
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/mail"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+)
+
+// deliveryDir holds the delivery log, a JSON Lines file per booking
+// reference.
+const deliveryDir = "./deliveries"
+
+// deliveryLog serialises appends to the log files.
+var deliveryLog sync.Mutex
+
+// deliveryRecord is a line of the log. Client is the API client that asked
+// for the delivery, and the only one that may read it back.
+type deliveryRecord struct {
+	Client string `json:"client,omitempty"`
+	types.DeliveryStatus
+}
+
+// emailSettings checks, before anything is generated, that a booking asking
+// for delivery can be emailed, and returns how.
+func emailSettings(c *gin.Context, data types.BookingData) (config.Email, error) {
+	if data.Delivery == nil {
+		return config.Email{}, nil
+	}
+	if !config.DefaultSMTP.Enabled() {
+		return config.Email{}, errors.New("email delivery is not configured")
+	}
+	if utils.SanitizeFileName(data.BookingReference) == "" {
+		return config.Email{}, errors.New("delivery needs a bookingReference")
+	}
+	if len(data.BookingReference) > maxReferenceLength {
+		return config.Email{}, fmt.Errorf("delivery needs a bookingReference of at most %d bytes", maxReferenceLength)
+	}
+
+	client, _ := config.ClientByKey(c.GetHeader(config.APIKeyHeader))
+	email := client.Email.Or(config.DefaultEmail)
+	if _, err := email.Sender(); err != nil {
+		return config.Email{}, err
+	}
+	if _, err := mail.GetTemplate(email.Template); err != nil {
+		return config.Email{}, err
+	}
+	return email, nil
+}
+
+// deliver emails the PDF at fileName and logs the outcome. Failing to send
+// does not fail the request, since the PDF was generated all the same; the
+// status says what went wrong.
+func deliver(c *gin.Context, data types.BookingData, th theme.Theme, email config.Email, fileName string) types.DeliveryStatus {
+	d := data.Delivery
+	l := i18n.Get(data.Options.Locale)
+
+	subject := l.T("Your %s itinerary", data.Destination)
+	if email.Subject != "" {
+		subject = email.SubjectFor(data.Destination, data.CustomerName, data.BookingReference)
+	}
+	status := types.DeliveryStatus{To: d.To, Subject: subject}
+
+	from, _ := email.Sender()
+	msg := &mail.Message{From: from, Subject: subject, Date: time.Now()}
+	for _, to := range d.To {
+		msg.To = append(msg.To, &netmail.Address{Address: to})
+	}
+	if d.AgentEmail != "" {
+		agent := &netmail.Address{Name: d.AgentName, Address: d.AgentEmail}
+		msg.Cc = []*netmail.Address{agent}
+		msg.ReplyTo = agent
+		status.Cc = []string{d.AgentEmail}
+	}
+
+	summary := mail.NewSummary(data, l, th)
+	summary.Agent = d.AgentName
+	err := func() error {
+		pdf, err := os.ReadFile(fileName)
+		if err != nil {
+			return err
+		}
+		if int64(len(pdf)) <= config.DefaultSMTP.AttachmentLimit {
+			summary.Attached = true
+			msg.Attachments = []mail.Attachment{{
+				Name: fmt.Sprintf("%s_%s_itinerary.pdf",
+					utils.SanitizeFileName(data.CustomerName),
+					utils.SanitizeFileName(data.Destination)),
+				ContentType: "application/pdf",
+				Data:        pdf,
+			}}
+		} else if summary.URL, err = publishPDF(c, data, pdf); err != nil {
+			return err
+		}
+
+		tpl, err := mail.GetTemplate(email.Template)
+		if err != nil {
+			return err
+		}
+		if msg.HTML, err = mail.Body(tpl, summary); err != nil {
+			return err
+		}
+		return mail.Send(config.DefaultSMTP, msg)
+	}()
+
+	status.Time = msg.Date.UTC().Format(time.RFC3339)
+	status.Attached = summary.Attached
+	status.URL = summary.URL
+	status.Status = types.DeliverySent
+	status.MessageID = msg.ID
+	if err != nil {
+		log.Printf("delivery of %s: %v", data.BookingReference, err)
+		status.Status = types.DeliveryFailed
+		status.Error = err.Error()
+		status.MessageID = ""
+	}
+
+	client, _ := config.ClientByKey(c.GetHeader(config.APIKeyHeader))
+	if err := recordDelivery(data.BookingReference, deliveryRecord{Client: client.Name, DeliveryStatus: status}); err != nil {
+		log.Printf("delivery log of %s: %v", data.BookingReference, err)
+	}
+	return status
+}
+
+// publishPDF keeps a PDF too large to attach with the published HTML
+// itineraries, since ./pdfs is cleared by the next request, and returns its
+// URL.
+func publishPDF(c *gin.Context, data types.BookingData, pdf []byte) (string, error) {
+	if err := os.MkdirAll(htmlDir, os.ModePerm); err != nil {
+		return "", err
+	}
+	fileBase, err := sharedFileName(data, ".pdf")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(htmlDir, fileBase), pdf, 0o644); err != nil {
+		return "", err
+	}
+	return baseURL(c) + "/itineraries/" + fileBase, nil
+}
+
+// maxReferenceLength keeps the log file names of delivered bookings within
+// what file systems allow.
+const maxReferenceLength = 100
+
+// logName encodes references without loss, so that "AB-1" and "AB_1" get
+// logs of their own, in letters and digits any file system takes.
+var logName = base32.HexEncoding.WithPadding(base32.NoPadding)
+
+func deliveryLogFile(reference string) string {
+	return filepath.Join(deliveryDir, logName.EncodeToString([]byte(reference))+".jsonl")
+}
+
+func recordDelivery(reference string, record deliveryRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	deliveryLog.Lock()
+	defer deliveryLog.Unlock()
+	if err := os.MkdirAll(deliveryDir, os.ModePerm); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(deliveryLogFile(reference), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(line, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// ListDeliveries answers with the delivery attempts the calling API client
+// logged for a booking reference, oldest first. The log holds recipients'
+// addresses, so it needs a key, and deliveries made without one are never
+// listed.
+func ListDeliveries(c *gin.Context) {
+	reference := c.Param("reference")
+	if utils.SanitizeFileName(reference) == "" || len(reference) > maxReferenceLength {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: no booking reference"})
+		return
+	}
+	client, ok := config.ClientByKey(c.GetHeader(config.APIKeyHeader))
+	if !ok {
+		c.JSON(http.StatusUnauthorized, types.ErrorResponse{Error: "Deliveries can only be read with an API key"})
+		return
+	}
+
+	deliveries := []types.DeliveryStatus{}
+	file, err := os.Open(deliveryLogFile(reference))
+	if errors.Is(err, os.ErrNotExist) {
+		c.JSON(http.StatusOK, types.DeliveriesResponse{Deliveries: deliveries})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to read deliveries: " + err.Error()})
+		return
+	}
+	defer file.Close()
+
+	lines := bufio.NewReader(file)
+	for {
+		line, err := lines.ReadBytes('\n')
+		if len(line) > 0 {
+			var record deliveryRecord
+			if err := json.Unmarshal(line, &record); err != nil {
+				c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to read deliveries: " + err.Error()})
+				return
+			}
+			if record.Client == client.Name {
+				deliveries = append(deliveries, record.DeliveryStatus)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to read deliveries: " + err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, types.DeliveriesResponse{Deliveries: deliveries})
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"mime/quotedprintable"
+	"net/http"
+	"net/http/httptest"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monoMonu/travel-itinerary-pdf/config"
+	"github.com/monoMonu/travel-itinerary-pdf/mail/mailtest"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+func TestDeliverAttachesOrLinks(t *testing.T) {
+	pdf := []byte("%PDF-1.4 a small itinerary")
+	tests := []struct {
+		name     string
+		limit    int64
+		attached bool
+	}{
+		{"at the limit", int64(len(pdf)), true},
+		{"over the limit", int64(len(pdf)) - 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			srv := mailtest.NewServer(t)
+			smtp := config.DefaultSMTP
+			config.DefaultSMTP = config.SMTP{Host: srv.Host, Port: srv.Port, Security: config.SMTPNone, AttachmentLimit: tt.limit}
+			t.Cleanup(func() { config.DefaultSMTP = smtp })
+
+			if err := os.WriteFile("itinerary.pdf", pdf, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "http://itin.test/generate-itinerary", nil)
+			data := types.BookingData{
+				BookingReference: "VG1",
+				CustomerName:     "Rahul Sharma",
+				Destination:      "Singapore",
+				DepartureDate:    "2025-06-15",
+				ReturnDate:       "2025-06-17",
+				Delivery:         &types.Delivery{To: []string{"rahul@example.com"}, AgentEmail: "asha@agency.test"},
+			}
+			status := deliver(c, data, theme.Default, config.Email{From: "Trips <trips@vigovia.test>"}, "itinerary.pdf")
+
+			if status.Status != types.DeliverySent || status.Error != "" {
+				t.Fatalf("status %+v", status)
+			}
+			msgs := srv.Messages()
+			if len(msgs) != 1 {
+				t.Fatalf("server got %d messages, want 1", len(msgs))
+			}
+			hasAttachment := strings.Contains(msgs[0].Data, "filename=Rahul_Sharma_Singapore_itinerary.pdf")
+			if status.Attached != tt.attached || hasAttachment != tt.attached {
+				t.Fatalf("attached = %v, message has attachment = %v, want %v", status.Attached, hasAttachment, tt.attached)
+			}
+			if tt.attached {
+				if status.URL != "" {
+					t.Fatalf("attached PDF also linked at %s", status.URL)
+				}
+				return
+			}
+			msg, err := netmail.ReadMessage(strings.NewReader(msgs[0].Data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, err := io.ReadAll(quotedprintable.NewReader(msg.Body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(status.URL, "http://itin.test/itineraries/") || !strings.Contains(string(body), status.URL) {
+				t.Fatalf("link %q missing from the message", status.URL)
+			}
+			published, err := os.ReadFile(filepath.Join(htmlDir, strings.TrimPrefix(status.URL, "http://itin.test/itineraries/")))
+			if err != nil || string(published) != string(pdf) {
+				t.Fatalf("published PDF: %q, %v", published, err)
+			}
+		})
+	}
+}
+
+func TestListDeliveries(t *testing.T) {
+	t.Chdir(t.TempDir())
+	loadClients := func(list string) {
+		t.Helper()
+		if err := os.WriteFile("clients.json", []byte(list), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := config.LoadClients("clients.json"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() { loadClients("[]") })
+
+	for _, client := range []string{"acme", "", "other"} {
+		record := deliveryRecord{Client: client, DeliveryStatus: types.DeliveryStatus{Status: types.DeliverySent, Subject: "by " + client}}
+		if err := recordDelivery("VG-1", record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A reference that the file name sanitizer would have merged with VG-1.
+	if err := recordDelivery("VG_1", deliveryRecord{Client: "acme", DeliveryStatus: types.DeliveryStatus{Subject: "VG_1 by acme"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.GET("/deliveries/:reference", ListDeliveries)
+	listReference := func(reference, key string) (int, []string) {
+		req := httptest.NewRequest(http.MethodGet, "/deliveries/"+reference, nil)
+		if key != "" {
+			req.Header.Set(config.APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resp types.DeliveriesResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		var subjects []string
+		for _, d := range resp.Deliveries {
+			subjects = append(subjects, d.Subject)
+		}
+		return w.Code, subjects
+	}
+	list := func(key string) (int, []string) {
+		return listReference("VG-1", key)
+	}
+
+	loadClients(`[{"name": "acme", "key": "k1"}, {"name": "other", "key": "k2"}]`)
+	tests := []struct {
+		key      string
+		code     int
+		subjects string
+	}{
+		{"", http.StatusUnauthorized, ""},
+		{"wrong", http.StatusUnauthorized, ""},
+		{"k1", http.StatusOK, "by acme"},
+		{"k2", http.StatusOK, "by other"},
+	}
+	for _, tt := range tests {
+		code, subjects := list(tt.key)
+		if code != tt.code || strings.Join(subjects, ",") != tt.subjects {
+			t.Errorf("key %q: %d %v, want %d %q", tt.key, code, subjects, tt.code, tt.subjects)
+		}
+	}
+
+	if code, subjects := listReference("VG_1", "k1"); code != http.StatusOK || strings.Join(subjects, ",") != "VG_1 by acme" {
+		t.Errorf("VG_1: %d %v, want only its own delivery", code, subjects)
+	}
+	if code, _ := listReference(strings.Repeat("A", maxReferenceLength+1), "k1"); code != http.StatusBadRequest {
+		t.Errorf("long reference: %d, want 400", code)
+	}
+
+	loadClients("[]")
+	if code, subjects := list(""); code != http.StatusUnauthorized || len(subjects) != 0 {
+		t.Errorf("without clients: %d %v, want 401", code, subjects)
+	}
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monoMonu/travel-itinerary-pdf/schema"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+const apiVersion = "1.0.0"
+
+func OpenAPI(c *gin.Context) {
+	routes := Routes()
+	ops := make([]schema.Operation, len(routes))
+	for i, route := range routes {
+		ops[i] = route.Operation
+	}
+	c.JSON(http.StatusOK, schema.OpenAPI("Vigovia Itinerary API", apiVersion, ops, types.ErrorResponse{}))
+}
+
+func BookingSchema(c *gin.Context) {
+	doc := schema.Of(types.BookingData{})
+	doc["$id"] = "/schema/booking.json"
+	c.JSON(http.StatusOK, doc)
+}
@@ -0,0 +1,27 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monoMonu/travel-itinerary-pdf/pnr"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// ParseFlights reads flights from pasted GDS segment lines or e-ticket text,
+// for a form to be filled in from. Lines that cannot be read for certain are
+// reported rather than guessed.
+func ParseFlights(c *gin.Context) {
+	var req types.FlightTextRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: " + err.Error()})
+		return
+	}
+
+	reference := time.Now()
+	if req.ReferenceDate != "" {
+		reference, _ = time.Parse(time.DateOnly, req.ReferenceDate)
+	}
+	c.JSON(http.StatusOK, pnr.Parse(req.Text, reference))
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monoMonu/travel-itinerary-pdf/config"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+func testBooking() types.BookingData {
+	return types.BookingData{
+		BookingReference: "VG1",
+		CustomerName:     "Rahul Sharma",
+		Destination:      "Singapore",
+		DepartureDate:    "2025-06-15",
+		ReturnDate:       "2025-06-17",
+		Days: []types.Day{{Date: "2025-06-15", Activities: []types.Activity{
+			{Time: "09:00", Title: "Arrival", Duration: 60},
+		}}},
+	}
+}
+
+// generate posts data to /generate-itinerary from a fresh directory and
+// returns the status and the error message, if any.
+func generate(t *testing.T, data types.BookingData, headers map[string]string) (int, string) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	router := gin.New()
+	router.POST("/generate-itinerary", GeneratePDF)
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/generate-itinerary", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	var resp types.ErrorResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp.Error
+}
+
+func TestDeterministicQuoteNeedsIssueDate(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    string
+		issueDate string
+		err       string
+	}{
+		{"quote without issueDate", types.StatusQuote, "", "deterministic quotes need an issueDate"},
+		{"quote with issueDate", types.StatusQuote, "2025-06-01", ""},
+		{"confirmed without issueDate", types.StatusConfirmed, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := testBooking()
+			data.Status, data.IssueDate = tt.status, tt.issueDate
+			data.Options.Deterministic = true
+			code, msg := generate(t, data, nil)
+			if tt.err == "" && code != http.StatusOK {
+				t.Fatalf("got %d %q", code, msg)
+			}
+			if tt.err != "" && (code != http.StatusBadRequest || !strings.Contains(msg, tt.err)) {
+				t.Fatalf("got %d %q, want 400 with %q", code, msg, tt.err)
+			}
+		})
+	}
+}
+
+// generatedPDF is the PDF the last call to generate wrote.
+func generatedPDF(t *testing.T) []byte {
+	t.Helper()
+	files, _ := filepath.Glob("pdfs/*.pdf")
+	if len(files) != 1 {
+		t.Fatalf("got PDFs %v", files)
+	}
+	pdf, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pdf
+}
+
+func TestRequestPassword(t *testing.T) {
+	secret := config.OwnerPasswordSecret
+	t.Cleanup(func() { config.OwnerPasswordSecret = secret })
+
+	tests := []struct {
+		name          string
+		secret        string
+		deterministic bool
+		archival      bool
+		err           string
+	}{
+		{"random owner password", "", false, false, ""},
+		{"derived owner password", "s3cret", true, false, ""},
+		{"deterministic without a secret", "", true, false, "deterministic password protected PDFs need the server to set PDF_OWNER_SECRET"},
+		{"archival", "s3cret", false, true, "archival PDFs cannot be password protected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.OwnerPasswordSecret = tt.secret
+			data := testBooking()
+			data.Options.Password = "VG1"
+			data.Options.Deterministic, data.Options.Archival = tt.deterministic, tt.archival
+
+			code, msg := generate(t, data, nil)
+			if tt.err != "" {
+				if code != http.StatusBadRequest || msg != "Invalid input: "+tt.err {
+					t.Fatalf("got %d %q, want 400 with %q", code, msg, tt.err)
+				}
+				return
+			}
+			if code != http.StatusOK {
+				t.Fatalf("got %d %q", code, msg)
+			}
+			first := generatedPDF(t)
+			if !bytes.Contains(first, []byte("/Encrypt ")) {
+				t.Fatal("PDF is not encrypted")
+			}
+			generate(t, data, nil)
+			if same := bytes.Equal(first, generatedPDF(t)); same != tt.deterministic {
+				t.Errorf("two requests gave the same bytes: %v, want %v", same, tt.deterministic)
+			}
+		})
+	}
+}
+
+func TestSummaryTooLongStatus(t *testing.T) {
+	data := testBooking()
+	data.Options.Variant = types.VariantSummary
+	for i := range 120 {
+		data.Days = append(data.Days, types.Day{Date: "2025-06-16", Activities: []types.Activity{{Time: "10:00", Title: fmt.Sprint("Stop ", i)}}})
+	}
+	code, msg := generate(t, data, nil)
+	if code != http.StatusUnprocessableEntity || !strings.Contains(msg, "trip does not fit on one page") {
+		t.Fatalf("got %d %q, want 422", code, msg)
+	}
+
+	data.Days = data.Days[:3]
+	if code, msg := generate(t, data, nil); code != http.StatusOK {
+		t.Fatalf("short trip: got %d %q", code, msg)
+	}
+}
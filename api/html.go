@@ -0,0 +1,108 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monoMonu/travel-itinerary-pdf/render"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+	"github.com/monoMonu/travel-itinerary-pdf/web"
+)
+
+// htmlDir holds the published HTML itineraries. Unlike ./pdfs it is not
+// cleared between requests, since its links are meant to be shared.
+const htmlDir = "./itineraries"
+
+// GenerateHTML publishes the booking as a responsive web page and answers
+// with its URL. Bookings whose PDFs would be password protected are
+// refused.
+func GenerateHTML(c *gin.Context) {
+	data, err := bindBooking(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: " + err.Error()})
+		return
+	}
+
+	if err := prepareImages(&data); err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: " + err.Error()})
+		return
+	}
+
+	// The page is not encrypted, so it must not publish what the client
+	// keeps behind a PDF password.
+	if data.Options.Password != "" || pdfProtection(c).UserPassword != "" {
+		c.JSON(http.StatusForbidden, types.ErrorResponse{Error: "Password protected itineraries cannot be published as web pages"})
+		return
+	}
+
+	cfg, err := documentConfig(c, data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: " + err.Error()})
+		return
+	}
+
+	if _, err := cfg.Template.Resolve(data.Options.Sections); err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: " + err.Error()})
+		return
+	}
+
+	fileBase, err := generateHTML(data, cfg)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to generate HTML: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, types.GenerateResponse{
+		Message: "HTML generated successfully",
+		URL:     baseURL(c) + "/itineraries/" + fileBase,
+	})
+}
+
+// generateHTML writes the page and returns its file name.
+func generateHTML(data types.BookingData, cfg render.Config) (string, error) {
+	doc, err := render.NewDocument(data, cfg)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(htmlDir, os.ModePerm); err != nil {
+		return "", err
+	}
+
+	fileBase, err := sharedFileName(data, ".html")
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Create(filepath.Join(htmlDir, fileBase))
+	if err != nil {
+		return "", err
+	}
+	err = web.Write(file, doc)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+	return fileBase, nil
+}
+
+// sharedFileName names a file in htmlDir. The name ends in random hex so
+// that links cannot be guessed from a customer's name.
+func sharedFileName(data types.BookingData, ext string) (string, error) {
+	token := make([]byte, 8)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s_%s_%s%s",
+		utils.SanitizeFileName(data.CustomerName),
+		utils.SanitizeFileName(data.Destination),
+		hex.EncodeToString(token), ext), nil
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monoMonu/travel-itinerary-pdf/config"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// Pages are not encrypted, so bookings that would get a password
+// protected PDF are not published.
+func TestGenerateHTMLProtected(t *testing.T) {
+	defaults := config.DefaultProtection
+	t.Cleanup(func() { config.DefaultProtection = defaults })
+
+	tests := []struct {
+		name       string
+		password   string
+		protection config.Protection
+		code       int
+	}{
+		{"open", "", config.Protection{}, http.StatusOK},
+		{"request password", "VG1", config.Protection{}, http.StatusForbidden},
+		{"server password", "", config.Protection{UserPassword: "s3cret"}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(t.TempDir())
+			config.DefaultProtection = tt.protection
+			data := testBooking()
+			data.Options.Password = tt.password
+
+			router := gin.New()
+			router.POST("/generate-html", GenerateHTML)
+			body, err := json.Marshal(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := httptest.NewRequest(http.MethodPost, "/generate-html", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Fatalf("got %d %s, want %d", w.Code, w.Body, tt.code)
+			}
+			pages, _ := filepath.Glob(filepath.Join(htmlDir, "*.html"))
+			if published := len(pages) > 0; published != (tt.code == http.StatusOK) {
+				t.Errorf("published %v", pages)
+			}
+			if tt.code == http.StatusOK {
+				var resp types.GenerateResponse
+				json.Unmarshal(w.Body.Bytes(), &resp)
+				if _, err := os.Stat(filepath.Join(htmlDir, filepath.Base(resp.URL))); err != nil {
+					t.Errorf("URL %q: %v", resp.URL, err)
+				}
+			}
+		})
+	}
+}
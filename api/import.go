@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monoMonu/travel-itinerary-pdf/spreadsheet"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// importFiles are the upload fields of POST /import. "file" may be a
+// workbook; the others are CSV files of the rows they are named after.
+var importFiles = []string{"file", spreadsheet.ImportActivities, spreadsheet.ImportFlights, spreadsheet.ImportHotels}
+
+// ImportSpreadsheet reads days, flights and hotels from uploaded
+// spreadsheets, for a form to be filled in from. Rows that cannot be read
+// are reported rather than failing the request.
+func ImportSpreadsheet(c *gin.Context) {
+	sheets, mapping, departureDate, err := bindImport(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: " + err.Error()})
+		return
+	}
+
+	resp, err := spreadsheet.Import(sheets, mapping, departureDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func bindImport(c *gin.Context) (sheets []spreadsheet.Sheet, mapping types.ImportMapping, departureDate string, err error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		return nil, mapping, "", err
+	}
+
+	for name, values := range form.Value {
+		if len(values) > 1 {
+			return nil, mapping, "", fmt.Errorf("more than one %q field", name)
+		}
+		switch name {
+		case "mapping":
+			decoder := json.NewDecoder(bytes.NewReader([]byte(values[0])))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(&mapping); err != nil {
+				return nil, mapping, "", fmt.Errorf("mapping: %w", err)
+			}
+		case "departureDate":
+			departureDate = values[0]
+		default:
+			return nil, mapping, "", fmt.Errorf("unknown form field %q", name)
+		}
+	}
+
+	for name, files := range form.File {
+		if !slices.Contains(importFiles, name) {
+			return nil, mapping, "", fmt.Errorf("unknown upload %q, want one of file, activities, flights, hotels", name)
+		}
+		if len(files) > 1 {
+			return nil, mapping, "", fmt.Errorf("more than one %q upload", name)
+		}
+	}
+	for _, name := range importFiles {
+		files := form.File[name]
+		if len(files) == 0 {
+			continue
+		}
+		data, err := readUpload(files[0])
+		if err != nil {
+			return nil, mapping, "", err
+		}
+
+		if spreadsheet.IsXLSX(data) {
+			workbook, err := spreadsheet.ReadXLSX(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				return nil, mapping, "", fmt.Errorf("%s: %w", name, err)
+			}
+			sheets = append(sheets, workbook...)
+			continue
+		}
+		sheetName := name
+		if name == "file" {
+			sheetName = spreadsheet.ImportActivities
+		}
+		s, err := spreadsheet.ReadCSV(bytes.NewReader(data), sheetName)
+		if err != nil {
+			return nil, mapping, "", err
+		}
+		sheets = append(sheets, s)
+	}
+	if len(sheets) == 0 {
+		return nil, mapping, "", errors.New("upload a spreadsheet as file, or CSV files as activities, flights or hotels")
+	}
+	return sheets, mapping, departureDate, nil
+}
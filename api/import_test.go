@@ -0,0 +1,62 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+func TestImportRejectsRepeatedFields(t *testing.T) {
+	router := gin.New()
+	router.POST("/import", ImportSpreadsheet)
+
+	tests := []struct {
+		name   string
+		fields [][2]string
+		files  []string
+		err    string
+	}{
+		{"one of each", [][2]string{{"departureDate", "2025-06-15"}, {"mapping", "{}"}}, []string{"activities"}, ""},
+		{"repeated departureDate", [][2]string{{"departureDate", "2025-06-15"}, {"departureDate", "2025-06-16"}}, []string{"activities"}, `more than one "departureDate" field`},
+		{"repeated mapping", [][2]string{{"mapping", "{}"}, {"mapping", `{"hotels": {}}`}}, []string{"activities"}, `more than one "mapping" field`},
+		{"repeated upload", nil, []string{"activities", "activities"}, `more than one "activities" upload`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			form := multipart.NewWriter(&body)
+			for _, f := range tt.fields {
+				form.WriteField(f[0], f[1])
+			}
+			for _, name := range tt.files {
+				w, _ := form.CreateFormFile(name, name+".csv")
+				w.Write([]byte("Date,Title\n2025-06-15,Fort\n"))
+			}
+			form.Close()
+
+			req := httptest.NewRequest(http.MethodPost, "/import", &body)
+			req.Header.Set("Content-Type", form.FormDataContentType())
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if tt.err == "" {
+				if w.Code != http.StatusOK {
+					t.Fatalf("%d %s", w.Code, w.Body)
+				}
+				return
+			}
+			var resp types.ErrorResponse
+			json.Unmarshal(w.Body.Bytes(), &resp)
+			if w.Code != http.StatusBadRequest || !strings.Contains(resp.Error, tt.err) {
+				t.Fatalf("%d %s, want 400 with %s", w.Code, w.Body, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monoMonu/travel-itinerary-pdf/schema"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+type Route struct {
+	schema.Operation
+	Handler gin.HandlerFunc
+}
+
+// Routes is the single list both the router and the OpenAPI document are built
+// from, so a handler cannot be added without being documented.
+func Routes() []Route {
+	return []Route{
+		{
+			Operation: schema.Operation{
+				Method:   http.MethodGet,
+				Path:     "/",
+				Summary:  "Health check",
+				Response: "",
+			},
+			Handler: func(reqCtx *gin.Context) {
+				reqCtx.JSON(http.StatusOK, "Hello World")
+			},
+		},
+		{
+			Operation: schema.Operation{
+				Method:  http.MethodPost,
+				Path:    "/generate-itinerary",
+				Summary: "Generate an itinerary PDF",
+				Description: "Accepts the booking as JSON, or as multipart/form-data with the JSON in the \"booking\" field " +
+					"and images uploaded as files named by the field they fill, e.g. \"coverImage\", \"hotels.0.image\" " +
+					"or \"days.1.activities.0.image\". With \"delivery\" set, the PDF is also emailed; the response " +
+					"then says whether that worked, and the attempt is logged under the booking reference.",
+				Request:   types.BookingData{},
+				Multipart: bookingField,
+				Response:  types.GenerateResponse{},
+				Errors:    []int{http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusInternalServerError},
+			},
+			Handler: GeneratePDF,
+		},
+		{
+			Operation: schema.Operation{
+				Method:  http.MethodGet,
+				Path:    "/deliveries/:reference",
+				Summary: "List the email deliveries of a booking",
+				Description: "Answers with every attempt to email the itinerary with this bookingReference, oldest first. " +
+					"Only the deliveries the calling API client requested are listed, so the endpoint needs a key.",
+				Response: types.DeliveriesResponse{},
+				Errors:   []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusInternalServerError},
+			},
+			Handler: ListDeliveries,
+		},
+		{
+			Operation: schema.Operation{
+				Method:  http.MethodPost,
+				Path:    "/generate-calendar",
+				Summary: "Export an itinerary as an iCalendar file",
+				Description: "Takes the same body as /generate-itinerary and answers with an RFC 5545 calendar of its " +
+					"flights, hotel stays and activities. Event UIDs are stable, so importing a revised itinerary " +
+					"updates the events instead of adding them again.",
+				Request:     types.BookingData{},
+				Multipart:   bookingField,
+				ContentType: "text/calendar",
+				Errors:      []int{http.StatusBadRequest, http.StatusInternalServerError},
+			},
+			Handler: GenerateCalendar,
+		},
+		{
+			Operation: schema.Operation{
+				Method:  http.MethodPost,
+				Path:    "/generate-spreadsheet",
+				Summary: "Export an itinerary's tables as an XLSX workbook",
+				Description: "Takes the same body as /generate-itinerary and answers with a workbook with a sheet each " +
+					"for the activities, hotel stays and payment plan. Dates are date cells and amounts number cells " +
+					"in rupees, so they sort and add up.",
+				Request:     types.BookingData{},
+				Multipart:   bookingField,
+				ContentType: xlsxContentType,
+				Errors:      []int{http.StatusBadRequest, http.StatusInternalServerError},
+			},
+			Handler: GenerateSpreadsheet,
+		},
+		{
+			Operation: schema.Operation{
+				Method:  http.MethodPost,
+				Path:    "/generate-csv/:section",
+				Summary: "Export one of an itinerary's tables as CSV",
+				Description: "Takes the same body as /generate-itinerary and answers with one sheet of " +
+					"/generate-spreadsheet as CSV: section is \"activities\", \"hotels\" or \"payment\". Dates are " +
+					"YYYY-MM-DD and amounts plain numbers.",
+				Request:     types.BookingData{},
+				Multipart:   bookingField,
+				ContentType: "text/csv",
+				Errors:      []int{http.StatusBadRequest, http.StatusInternalServerError},
+			},
+			Handler: GenerateCSV,
+		},
+		{
+			Operation: schema.Operation{
+				Method:  http.MethodPost,
+				Path:    "/generate-html",
+				Summary: "Publish an itinerary as a responsive web page",
+				Description: "Takes the same body as /generate-itinerary and answers with the URL of an HTML page " +
+					"with the same sections, content and theme colours as the PDF, laid out for phones as well as " +
+					"desktops. Pages are kept, so the URL can be shared. Since pages are not encrypted, bookings " +
+					"whose PDF would be password protected, by options.password or the API client's rule, are " +
+					"refused with 403.",
+				Request:   types.BookingData{},
+				Multipart: bookingField,
+				Response:  types.GenerateResponse{},
+				Errors:    []int{http.StatusBadRequest, http.StatusForbidden, http.StatusInternalServerError},
+			},
+			Handler: GenerateHTML,
+		},
+		{
+			Operation: schema.Operation{
+				Method:  http.MethodPost,
+				Path:    "/import",
+				Summary: "Read days, flights and hotels from spreadsheets",
+				Description: "Takes an XLSX workbook, or CSV files, and answers with the days, flights and hotels of " +
+					"a booking, for a form to be filled in from. Columns are found by their headers, or as mapped. " +
+					"Rows that cannot be read are left out and listed in errors with their row numbers.",
+				Form:     types.ImportForm{},
+				Response: types.ImportResponse{},
+				Errors:   []int{http.StatusBadRequest},
+			},
+			Handler: ImportSpreadsheet,
+		},
+		{
+			Operation: schema.Operation{
+				Method:  http.MethodPost,
+				Path:    "/parse-flights",
+				Summary: "Read flights from pasted PNR or e-ticket text",
+				Description: "Takes GDS itinerary segment lines or the flight lines of an e-ticket and answers with " +
+					"flights, with airports named with their IATA codes and times as HH:MM. Lines that could be read more than " +
+					"one way, like 05/06/2025, or that miss a date, airport or time, are left out and listed in " +
+					"problems with their line numbers.",
+				Request:  types.FlightTextRequest{},
+				Response: types.FlightTextResponse{},
+				Errors:   []int{http.StatusBadRequest},
+			},
+			Handler: ParseFlights,
+		},
+		{
+			Operation: schema.Operation{
+				Method:   http.MethodGet,
+				Path:     "/sections",
+				Summary:  "List the section names accepted in options.sections",
+				Response: types.SectionsResponse{},
+			},
+			Handler: ListSections,
+		},
+		{
+			Operation: schema.Operation{
+				Method:   http.MethodGet,
+				Path:     "/themes",
+				Summary:  "List the theme names accepted in options.theme",
+				Response: types.ThemesResponse{},
+			},
+			Handler: ListThemes,
+		},
+		{
+			Operation: schema.Operation{
+				Method:   http.MethodGet,
+				Path:     "/templates",
+				Summary:  "List the layout templates accepted in options.template, with their versions",
+				Response: types.TemplatesResponse{},
+			},
+			Handler: ListTemplates,
+		},
+		{
+			Operation: schema.Operation{
+				Method:      http.MethodGet,
+				Path:        "/pdfs/*filepath",
+				Summary:     "Download a generated PDF",
+				ContentType: "application/pdf",
+			},
+			Handler: staticPDFs,
+		},
+		{
+			Operation: schema.Operation{
+				Method:      http.MethodGet,
+				Path:        "/itineraries/*filepath",
+				Summary:     "View a published HTML itinerary",
+				ContentType: "text/html",
+			},
+			Handler: staticHTML,
+		},
+		{
+			Operation: schema.Operation{
+				Method:   http.MethodGet,
+				Path:     "/openapi.json",
+				Summary:  "OpenAPI description of this API",
+				Response: map[string]any{},
+			},
+			Handler: OpenAPI,
+		},
+		{
+			Operation: schema.Operation{
+				Method:   http.MethodGet,
+				Path:     "/schema/booking.json",
+				Summary:  "JSON Schema of the itinerary request body",
+				Response: map[string]any{},
+			},
+			Handler: BookingSchema,
+		},
+	}
+}
+
+func RegisterRoutes(app *gin.Engine) {
+	for _, route := range Routes() {
+		app.Handle(route.Method, route.Path, route.Handler)
+	}
+}
+
+var staticPDFs = func() gin.HandlerFunc {
+	fileServer := http.StripPrefix("/pdfs", http.FileServer(gin.Dir("./pdfs", false)))
+	return func(c *gin.Context) {
+		fileServer.ServeHTTP(c.Writer, c.Request)
+	}
+}()
+
+var staticHTML = func() gin.HandlerFunc {
+	fileServer := http.StripPrefix("/itineraries", http.FileServer(gin.Dir(htmlDir, false)))
+	return func(c *gin.Context) {
+		fileServer.ServeHTTP(c.Writer, c.Request)
+	}
+}()
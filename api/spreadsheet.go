@@ -0,0 +1,71 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/spreadsheet"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// GenerateSpreadsheet answers with the booking's activities, hotel stays
+// and payment plan as an XLSX workbook with a sheet each.
+func GenerateSpreadsheet(c *gin.Context) {
+	data, err := bindBooking(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: " + err.Error()})
+		return
+	}
+
+	var body bytes.Buffer
+	sheets := spreadsheet.Build(data, i18n.Get(data.Options.Locale))
+	if err := spreadsheet.WriteXLSX(&body, sheets); err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to generate spreadsheet: " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+spreadsheetFileName(data, ".xlsx")+`"`)
+	c.Data(http.StatusOK, xlsxContentType, body.Bytes())
+}
+
+// GenerateCSV answers with one of the workbook's sheets, named by the
+// section parameter, as CSV.
+func GenerateCSV(c *gin.Context) {
+	section := c.Param("section")
+	if !slices.Contains(spreadsheet.Names, section) {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: fmt.Sprintf(
+			"Invalid input: unknown section %q, expected one of %s", section, strings.Join(spreadsheet.Names, ", "))})
+		return
+	}
+
+	data, err := bindBooking(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{Error: "Invalid input: " + err.Error()})
+		return
+	}
+
+	sheet, _ := spreadsheet.Get(spreadsheet.Build(data, i18n.Get(data.Options.Locale)), section)
+	var body bytes.Buffer
+	if err := spreadsheet.WriteCSV(&body, sheet); err != nil {
+		c.JSON(http.StatusInternalServerError, types.ErrorResponse{Error: "Failed to generate CSV: " + err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+spreadsheetFileName(data, "_"+section+".csv")+`"`)
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", body.Bytes())
+}
+
+// spreadsheetFileName names a download after the booking, ending in suffix.
+func spreadsheetFileName(data types.BookingData, suffix string) string {
+	return fmt.Sprintf("%s_%s_itinerary%s",
+		utils.SanitizeFileName(data.CustomerName),
+		utils.SanitizeFileName(data.Destination), suffix)
+}
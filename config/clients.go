@@ -0,0 +1,229 @@
+package config
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKeyHeader identifies the calling API client.
+const APIKeyHeader = "X-API-Key"
+
+// Client holds the per-partner settings applied to every request made with
+// its API key.
+type Client struct {
+	Name  string `json:"name"`
+	Key   string `json:"key"`
+	Theme string `json:"theme,omitempty"`
+	// Template is the client's layout, used unless a request picks one.
+	Template string `json:"template,omitempty"`
+	// QuoteValidityDays is how long the client's quotes stay valid.
+	// Defaults to DefaultQuoteValidityDays.
+	QuoteValidityDays int `json:"quoteValidityDays,omitempty"`
+	Links
+	Payment
+	Protection
+	Email
+}
+
+// DefaultQuoteValidityDays applies to clients that do not set their own.
+var DefaultQuoteValidityDays = 7
+
+// Links are the URLs behind the clickable parts of the PDF. Empty fields fall
+// back to DefaultLinks.
+type Links struct {
+	TermsURL    string `json:"termsUrl,omitempty"`
+	CheckoutURL string `json:"checkoutUrl,omitempty"`
+	// ItineraryURL is the online version of a booking. A {reference}
+	// placeholder is replaced with the booking reference.
+	ItineraryURL string `json:"itineraryUrl,omitempty"`
+}
+
+// DefaultLinks applies to requests whose client does not set its own.
+var DefaultLinks Links
+
+// Or returns l with its empty fields taken from fallback.
+func (l Links) Or(fallback Links) Links {
+	if l.TermsURL == "" {
+		l.TermsURL = fallback.TermsURL
+	}
+	if l.CheckoutURL == "" {
+		l.CheckoutURL = fallback.CheckoutURL
+	}
+	if l.ItineraryURL == "" {
+		l.ItineraryURL = fallback.ItineraryURL
+	}
+	return l
+}
+
+// Payment identifies who installments are paid to. Without a UPI ID the
+// payment plan has no QR codes.
+type Payment struct {
+	UPIID string `json:"upiId,omitempty"`
+	// PayeeName defaults to the theme's company name.
+	PayeeName string `json:"payeeName,omitempty"`
+}
+
+// DefaultPayment applies to requests whose client does not set a UPI ID.
+var DefaultPayment Payment
+
+// Protection encrypts PDFs. UserPassword is a rule rather than a literal:
+// {reference} is replaced with the booking reference and {dob} with the lead
+// traveller's date of birth as DDMMYYYY, so travellers can work it out from
+// their booking.
+type Protection struct {
+	UserPassword string `json:"userPassword,omitempty"`
+	// OwnerPassword lifts the restrictions. Left empty, one is derived from
+	// the user password with OwnerPasswordSecret.
+	OwnerPassword string `json:"ownerPassword,omitempty"`
+	// Permissions lists what readers may do; see PermissionNames. Without
+	// the field they may do everything, with an empty list nothing.
+	Permissions []string `json:"permissions"`
+}
+
+// PermissionNames are the values accepted in Protection.Permissions.
+var PermissionNames = []string{"print", "copy", "modify", "annotate"}
+
+// DefaultProtection applies to the fields of a client's protection that the
+// client leaves empty.
+var DefaultProtection Protection
+
+// OwnerPasswordSecret derives the owner password of PDFs whose protection
+// has none, so that the agency can work it out and the same booking always
+// encrypts to the same bytes.
+var OwnerPasswordSecret string
+
+// Or returns p with its empty fields taken from fallback.
+func (p Protection) Or(fallback Protection) Protection {
+	if p.UserPassword == "" {
+		p.UserPassword = fallback.UserPassword
+	}
+	if p.OwnerPassword == "" {
+		p.OwnerPassword = fallback.OwnerPassword
+	}
+	if p.Permissions == nil {
+		p.Permissions = fallback.Permissions
+	}
+	return p
+}
+
+// Validate reports unknown permission names.
+func (p Protection) Validate() error {
+	for _, name := range p.Permissions {
+		if !slices.Contains(PermissionNames, name) {
+			return fmt.Errorf("unknown permission %q, want one of %s", name, strings.Join(PermissionNames, ", "))
+		}
+	}
+	return nil
+}
+
+// Owner is the owner password of a PDF whose user password is user: p's
+// own, or else the first 32 hex digits of the HMAC-SHA256 of user keyed
+// with OwnerPasswordSecret.
+func (p Protection) Owner(user string) (string, error) {
+	if p.OwnerPassword != "" {
+		return p.OwnerPassword, nil
+	}
+	if OwnerPasswordSecret == "" {
+		return "", errors.New("a user password needs an ownerPassword, or PDF_OWNER_SECRET to derive one")
+	}
+	mac := hmac.New(sha256.New, []byte(OwnerPasswordSecret))
+	mac.Write([]byte(user))
+	return hex.EncodeToString(mac.Sum(nil))[:32], nil
+}
+
+// Password expands the user password rule for a booking. It fails when the
+// rule needs a field the booking does not have.
+func (p Protection) Password(reference, dob string) (string, error) {
+	password := p.UserPassword
+	if strings.Contains(password, "{reference}") {
+		if reference == "" {
+			return "", errors.New("the password needs a bookingReference")
+		}
+		password = strings.ReplaceAll(password, "{reference}", reference)
+	}
+	if strings.Contains(password, "{dob}") {
+		date, err := time.Parse(time.DateOnly, dob)
+		if err != nil {
+			return "", errors.New("the password needs the traveller's date of birth as travelerDob")
+		}
+		password = strings.ReplaceAll(password, "{dob}", date.Format("02012006"))
+	}
+	return password, nil
+}
+
+var (
+	mu      sync.RWMutex
+	clients = map[string]Client{}
+)
+
+// LoadClients reads a JSON array of clients from path. A missing file leaves
+// the server without registered clients.
+func LoadClients(path string) error {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var list []Client
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	byKey := make(map[string]Client, len(list))
+	for _, client := range list {
+		if client.Key == "" {
+			return fmt.Errorf("%s: client %q has no key", path, client.Name)
+		}
+		if _, dup := byKey[client.Key]; dup {
+			return fmt.Errorf("%s: duplicate key for client %q", path, client.Name)
+		}
+		if err := client.Protection.Validate(); err != nil {
+			return fmt.Errorf("%s: client %q: %w", path, client.Name, err)
+		}
+		if err := client.Email.Validate(); err != nil {
+			return fmt.Errorf("%s: client %q: %w", path, client.Name, err)
+		}
+		byKey[client.Key] = client
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	clients = byKey
+	return nil
+}
+
+// ClientByKey returns the client registered for key, or the zero Client.
+func ClientByKey(key string) (Client, bool) {
+	if key == "" {
+		return Client{}, false
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	client, ok := clients[key]
+	return client, ok
+}
+
+// Clients returns every registered client, for startup validation.
+func Clients() []Client {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	list := make([]Client, 0, len(clients))
+	for _, client := range clients {
+		list = append(list, client)
+	}
+	return list
+}
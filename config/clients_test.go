@@ -0,0 +1,47 @@
+package config
+
+import "testing"
+
+func TestProtectionOr(t *testing.T) {
+	fallback := Protection{UserPassword: "{reference}", OwnerPassword: "agency", Permissions: []string{"print"}}
+	tests := []struct {
+		name   string
+		client Protection
+		want   Protection
+	}{
+		{"empty", Protection{}, fallback},
+		{"permissions only", Protection{Permissions: []string{}}, Protection{UserPassword: "{reference}", OwnerPassword: "agency", Permissions: []string{}}},
+		{"owner only", Protection{OwnerPassword: "client"}, Protection{UserPassword: "{reference}", OwnerPassword: "client", Permissions: []string{"print"}}},
+		{"user only", Protection{UserPassword: "{dob}"}, Protection{UserPassword: "{dob}", OwnerPassword: "agency", Permissions: []string{"print"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.client.Or(fallback)
+			if got.UserPassword != tt.want.UserPassword || got.OwnerPassword != tt.want.OwnerPassword ||
+				(got.Permissions == nil) != (tt.want.Permissions == nil) || len(got.Permissions) != len(tt.want.Permissions) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProtectionOwner(t *testing.T) {
+	secret := OwnerPasswordSecret
+	t.Cleanup(func() { OwnerPasswordSecret = secret })
+
+	OwnerPasswordSecret = ""
+	if got, err := (Protection{OwnerPassword: "agency"}).Owner("user"); err != nil || got != "agency" {
+		t.Fatalf("Owner = %q, %v; want the set owner password", got, err)
+	}
+	if _, err := (Protection{}).Owner("user"); err == nil {
+		t.Fatal("Owner without a secret succeeded")
+	}
+
+	OwnerPasswordSecret = "secret"
+	a, _ := Protection{}.Owner("user")
+	b, _ := Protection{}.Owner("user")
+	c, _ := Protection{}.Owner("other")
+	if len(a) != 32 || a != b || a == c {
+		t.Fatalf("derived owner passwords %q, %q, %q: want 32 hex digits, the same for the same user password", a, b, c)
+	}
+}
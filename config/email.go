@@ -0,0 +1,111 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+// SMTP is the mail server itineraries are delivered through. Without a Host
+// email delivery is turned off.
+type SMTP struct {
+	Host string
+	Port int
+	// Security is SMTPStartTLS, SMTPTLS or SMTPNone.
+	Security string
+	Username string
+	Password string
+	// AttachmentLimit is the largest PDF, in bytes, attached to an email.
+	// Larger ones are linked instead.
+	AttachmentLimit int64
+}
+
+// SMTP security modes. StartTLS upgrades a plain connection and fails when
+// the server cannot; TLS connects over TLS from the start, usually on port
+// 465; None sends in the clear, for local mail sinks.
+const (
+	SMTPStartTLS = "starttls"
+	SMTPTLS      = "tls"
+	SMTPNone     = "none"
+)
+
+// DefaultAttachmentLimit keeps emails under the 10 MB many mail servers
+// accept, allowing for base64 growing the PDF by a third.
+const DefaultAttachmentLimit = 7 << 20
+
+// DefaultSMTP is the server's mail server, shared by all clients.
+var DefaultSMTP SMTP
+
+// Enabled reports whether a mail server is configured.
+func (s SMTP) Enabled() bool {
+	return s.Host != ""
+}
+
+// Validate reports an unknown security mode.
+func (s SMTP) Validate() error {
+	switch s.Security {
+	case SMTPStartTLS, SMTPTLS, SMTPNone:
+		return nil
+	}
+	return fmt.Errorf("unknown security %q, want one of %s, %s, %s", s.Security, SMTPStartTLS, SMTPTLS, SMTPNone)
+}
+
+// Email is how a client's itineraries are emailed. Empty fields fall back
+// to DefaultEmail.
+type Email struct {
+	// From is the sender, like "Vigovia <trips@vigovia.com>".
+	From string `json:"emailFrom,omitempty"`
+	// Subject is a rule like the user password's: {destination},
+	// {customer} and {reference} are replaced with the booking's. Left
+	// empty, the subject is "Your <destination> itinerary", translated.
+	Subject string `json:"emailSubject,omitempty"`
+	// Template names the email body; see mail.LoadTemplates.
+	Template string `json:"emailTemplate,omitempty"`
+}
+
+// DefaultEmail applies to requests whose client does not set its own.
+var DefaultEmail Email
+
+// Or returns e with its empty fields taken from fallback.
+func (e Email) Or(fallback Email) Email {
+	if e.From == "" {
+		e.From = fallback.From
+	}
+	if e.Subject == "" {
+		e.Subject = fallback.Subject
+	}
+	if e.Template == "" {
+		e.Template = fallback.Template
+	}
+	return e
+}
+
+// Validate reports a sender that is not an email address.
+func (e Email) Validate() error {
+	if e.From == "" {
+		return nil
+	}
+	if _, err := mail.ParseAddress(e.From); err != nil {
+		return fmt.Errorf("sender %q: %w", e.From, err)
+	}
+	return nil
+}
+
+// Sender parses From, failing when neither the client nor the server set
+// one.
+func (e Email) Sender() (*mail.Address, error) {
+	if e.From == "" {
+		return nil, errors.New("no email sender is configured")
+	}
+	return mail.ParseAddress(e.From)
+}
+
+// SubjectFor expands the subject rule for a booking.
+func (e Email) SubjectFor(destination, customer, reference string) string {
+	return strings.NewReplacer(
+		"{destination}", destination,
+		"{customer}", customer,
+		"{reference}", reference,
+	).Replace(e.Subject)
+}
@@ -0,0 +1,167 @@
+package fonts
+
+import (
+	"encoding/binary"
+	"errors"
+	"unicode"
+)
+
+const unicodeMax = unicode.MaxRune
+
+var errNoCmap = errors.New("font has no usable Unicode cmap")
+
+// parseCmap reads the Unicode cmap (format 4 or 12) of a TrueType font into a
+// code point to glyph id map. Code points mapped to glyph 0 are left out.
+func parseCmap(font []byte) (map[rune]uint16, error) {
+	table, ok := findTable(font, "cmap")
+	if !ok || len(table) < 4 {
+		return nil, errNoCmap
+	}
+
+	numTables := int(u16(table, 2))
+	var best []byte
+	bestFormat := uint16(0)
+	for i := 0; i < numTables; i++ {
+		rec := 4 + i*8
+		if rec+8 > len(table) {
+			break
+		}
+		platform, encoding := u16(table, rec), u16(table, rec+2)
+		unicode := platform == 0 || (platform == 3 && (encoding == 1 || encoding == 10))
+		if !unicode {
+			continue
+		}
+		offset := int(u32(table, rec+4))
+		if offset+2 > len(table) {
+			continue
+		}
+		sub := table[offset:]
+		format := u16(sub, 0)
+		if (format == 4 || format == 12) && format > bestFormat {
+			best, bestFormat = sub, format
+		}
+	}
+
+	switch bestFormat {
+	case 4:
+		return parseFormat4(best), nil
+	case 12:
+		return parseFormat12(best), nil
+	}
+	return nil, errNoCmap
+}
+
+func parseFormat4(sub []byte) map[rune]uint16 {
+	segX2 := int(u16(sub, 6))
+	ends := 14
+	starts := ends + segX2 + 2
+	deltas := starts + segX2
+	offsets := deltas + segX2
+
+	glyphs := map[rune]uint16{}
+	for i := 0; i < segX2/2; i++ {
+		end := rune(u16(sub, ends+2*i))
+		start := rune(u16(sub, starts+2*i))
+		if start == 0xFFFF {
+			continue
+		}
+		delta := u16(sub, deltas+2*i)
+		rangeOffset := int(u16(sub, offsets+2*i))
+		for r := start; r <= end; r++ {
+			glyph := uint16(r) + delta
+			if rangeOffset != 0 {
+				at := offsets + 2*i + rangeOffset + 2*int(r-start)
+				if at+2 > len(sub) {
+					break
+				}
+				if glyph = u16(sub, at); glyph != 0 {
+					glyph += delta
+				}
+			}
+			if glyph != 0 {
+				glyphs[r] = glyph
+			}
+		}
+	}
+	return glyphs
+}
+
+func parseFormat12(sub []byte) map[rune]uint16 {
+	groups := int(u32(sub, 12))
+	glyphs := map[rune]uint16{}
+	for i := 0; i < groups; i++ {
+		at := 16 + 12*i
+		if at+12 > len(sub) {
+			break
+		}
+		start, end, glyph := rune(u32(sub, at)), rune(u32(sub, at+4)), u32(sub, at+8)
+		for r := start; r <= end && r <= unicodeMax; r++ {
+			if g := glyph + uint32(r-start); g != 0 && g <= 0xFFFF {
+				glyphs[r] = uint16(g)
+			}
+		}
+	}
+	return glyphs
+}
+
+// parseAdvances returns the advance width of every glyph and the font's
+// units per em.
+func parseAdvances(font []byte) ([]uint16, int, error) {
+	head, ok1 := findTable(font, "head")
+	hhea, ok2 := findTable(font, "hhea")
+	hmtx, ok3 := findTable(font, "hmtx")
+	maxp, ok4 := findTable(font, "maxp")
+	if !ok1 || !ok2 || !ok3 || !ok4 {
+		return nil, 0, errors.New("font is missing head, hhea, hmtx or maxp")
+	}
+
+	unitsPerEm := int(u16(head, 18))
+	numMetrics := int(u16(hhea, 34))
+	numGlyphs := int(u16(maxp, 4))
+	if unitsPerEm == 0 || numMetrics == 0 {
+		return nil, 0, errors.New("font has invalid metrics")
+	}
+
+	advances := make([]uint16, numGlyphs)
+	for g := range advances {
+		// Glyphs past numMetrics repeat the last advance.
+		advances[g] = u16(hmtx, 4*min(g, numMetrics-1))
+	}
+	return advances, unitsPerEm, nil
+}
+
+func findTable(font []byte, tag string) ([]byte, bool) {
+	if len(font) < 12 {
+		return nil, false
+	}
+	numTables := int(u16(font, 4))
+	for i := 0; i < numTables; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(font) {
+			return nil, false
+		}
+		if string(font[rec:rec+4]) != tag {
+			continue
+		}
+		offset, length := int(u32(font, rec+8)), int(u32(font, rec+12))
+		if offset+length > len(font) {
+			return nil, false
+		}
+		return font[offset : offset+length], true
+	}
+	return nil, false
+}
+
+func u16(b []byte, at int) uint16 {
+	if at+2 > len(b) {
+		return 0
+	}
+	return binary.BigEndian.Uint16(b[at:])
+}
+
+func u32(b []byte, at int) uint32 {
+	if at+4 > len(b) {
+		return 0
+	}
+	return binary.BigEndian.Uint32(b[at:])
+}
@@ -0,0 +1,235 @@
+package fonts
+
+import (
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+//go:embed ttf/*.ttf
+var embedded embed.FS
+
+// Face is one TrueType file: a family in a single style.
+type Face struct {
+	Family string
+	// Style is "", "B", "I" or "BI", as gofpdf expects.
+	Style string
+	Data  []byte
+
+	glyphs     map[rune]uint16
+	advances   []uint16
+	unitsPerEm int
+}
+
+// Has reports whether the face has a glyph for r.
+func (f *Face) Has(r rune) bool {
+	_, ok := f.glyphs[r]
+	return ok
+}
+
+// Advance returns the advance width of r in thousandths of the font size,
+// the unit gofpdf uses for character widths.
+func (f *Face) Advance(r rune) float64 {
+	glyph := f.glyphs[r]
+	if int(glyph) >= len(f.advances) {
+		return 0
+	}
+	return float64(f.advances[glyph]) * 1000 / float64(f.unitsPerEm)
+}
+
+func newFace(family, style string, data []byte) (*Face, error) {
+	glyphs, err := parseCmap(data)
+	if err != nil {
+		return nil, err
+	}
+	advances, unitsPerEm, err := parseAdvances(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Face{
+		Family:     family,
+		Style:      style,
+		Data:       data,
+		glyphs:     glyphs,
+		advances:   advances,
+		unitsPerEm: unitsPerEm,
+	}, nil
+}
+
+// Family groups the faces of one typeface by style.
+type Family struct {
+	Name  string
+	faces map[string]*Face
+}
+
+// Face returns the face for style, falling back to the closest available one.
+func (f *Family) Face(style string) *Face {
+	for _, s := range []string{style, strings.ReplaceAll(style, "I", ""), ""} {
+		if face, ok := f.faces[s]; ok {
+			return face
+		}
+	}
+	for _, face := range f.faces {
+		return face
+	}
+	return nil
+}
+
+const (
+	Sans       = "DejaVuSans"
+	Devanagari = "NotoSansDevanagari"
+	Serif      = "FreeSerif"
+)
+
+// Fallbacks are tried in order for any character the requested family has no
+// glyph for.
+var Fallbacks = []string{Sans, Devanagari, Serif}
+
+// aliases keep the core PDF font names working in themes.
+var aliases = map[string]string{
+	"arial":     Sans,
+	"helvetica": Sans,
+	"times":     Serif,
+}
+
+var (
+	mu       sync.RWMutex
+	families = map[string]*Family{}
+)
+
+func init() {
+	builtin := []struct{ family, style, file string }{
+		{Sans, "", "DejaVuSansCondensed.ttf"},
+		{Sans, "B", "DejaVuSansCondensed-Bold.ttf"},
+		{Sans, "I", "DejaVuSansCondensed-Oblique.ttf"},
+		{Sans, "BI", "DejaVuSansCondensed-BoldOblique.ttf"},
+		{Devanagari, "", "NotoSansDevanagari-Regular.ttf"},
+		{Serif, "", "FreeSerif.ttf"},
+	}
+	for _, font := range builtin {
+		data, err := embedded.ReadFile("ttf/" + font.file)
+		if err != nil {
+			panic(err)
+		}
+		if err := Register(font.family, font.style, data); err != nil {
+			panic(fmt.Sprintf("fonts: %s: %v", font.file, err))
+		}
+	}
+}
+
+// Register adds a TrueType face, creating its family on first use.
+func Register(family, style string, data []byte) error {
+	face, err := newFace(family, style, data)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	fam, ok := families[family]
+	if !ok {
+		fam = &Family{Name: family, faces: map[string]*Face{}}
+		families[family] = fam
+	}
+	fam.faces[style] = face
+	return nil
+}
+
+// Lookup finds a family by name or alias, ignoring case.
+func Lookup(name string) (*Family, bool) {
+	if alias, ok := aliases[strings.ToLower(name)]; ok {
+		name = alias
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	if fam, ok := families[name]; ok {
+		return fam, true
+	}
+	for key, fam := range families {
+		if strings.EqualFold(key, name) {
+			return fam, true
+		}
+	}
+	return nil, false
+}
+
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(families))
+	for name := range families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FaceFor picks the face used to draw r: the requested family if it has the
+// glyph, otherwise the first fallback that does, otherwise the requested
+// family anyway so the missing glyph box shows up in its style.
+func FaceFor(r rune, primary *Family, style string) *Face {
+	face := primary.Face(style)
+	if face.Has(r) || isControl(r) {
+		return face
+	}
+	for _, name := range Fallbacks {
+		if name == primary.Name {
+			continue
+		}
+		if fam, ok := Lookup(name); ok {
+			if fallback := fam.Face(style); fallback.Has(r) {
+				return fallback
+			}
+		}
+	}
+	return face
+}
+
+// Run is a stretch of text drawn with a single face.
+type Run struct {
+	Face *Face
+	Text string
+}
+
+// Runs splits text into runs so every character uses a face that has it.
+// Combining marks stay with the run of the character they attach to.
+func Runs(text string, primary *Family, style string) []Run {
+	var runs []Run
+	var current *Face
+	var b strings.Builder
+
+	for _, r := range text {
+		face := current
+		if current == nil || !(isMark(r) && current.Has(r)) {
+			face = FaceFor(r, primary, style)
+		}
+		if face != current && b.Len() > 0 {
+			runs = append(runs, Run{Face: current, Text: b.String()})
+			b.Reset()
+		}
+		current = face
+		b.WriteRune(r)
+	}
+	if b.Len() > 0 {
+		runs = append(runs, Run{Face: current, Text: b.String()})
+	}
+	return runs
+}
+
+// Width returns the width of text in thousandths of the font size, accounting
+// for per-character fallback.
+func Width(text string, primary *Family, style string) float64 {
+	w := 0.0
+	for _, r := range text {
+		w += FaceFor(r, primary, style).Advance(r)
+	}
+	return w
+}
+
+func isControl(r rune) bool {
+	return r < 0x20
+}
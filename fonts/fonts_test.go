@@ -0,0 +1,92 @@
+package fonts
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRuns(t *testing.T) {
+	sans, ok := Lookup("helvetica")
+	if !ok || sans.Name != Sans {
+		t.Fatalf("helvetica is not an alias of %s", Sans)
+	}
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"Marina Bay", []string{Sans + ":Marina Bay"}},
+		// The vowel signs and virama stay with the Devanagari face.
+		{"Day 1: नमस्ते Singapore", []string{Sans + ":Day 1: ", Devanagari + ":नमस्ते", Sans + ": Singapore"}},
+		// Spaces are the requested face's, which has them.
+		{"राहुल शर्मा", []string{Devanagari + ":राहुल", Sans + ": ", Devanagari + ":शर्मा"}},
+		// A combining accent stays with the letter it is on.
+		{"Café ✈", []string{Sans + ":Café ✈"}},
+		// With no face for a character, the requested one shows its box.
+		{"a\U0001F600", []string{Sans + ":a\U0001F600"}},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, run := range Runs(tt.text, sans, "B") {
+			if run.Face.Style != "B" && run.Face.Family == Sans {
+				t.Errorf("%q: bold drawn with style %q", tt.text, run.Face.Style)
+			}
+			got = append(got, run.Face.Family+":"+run.Text)
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("Runs(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestWidth(t *testing.T) {
+	sans, _ := Lookup(Sans)
+	deva, _ := Lookup(Devanagari)
+	if w := Width("", sans, ""); w != 0 {
+		t.Errorf("empty text is %v wide", w)
+	}
+	if Width("MM", sans, "") != 2*Width("M", sans, "") {
+		t.Error("width does not add up")
+	}
+	// A Devanagari name is measured in the face that draws it.
+	if got, want := Width("नमस्ते", sans, ""), Width("नमस्ते", deva, ""); got != want || got == 0 {
+		t.Errorf("Devanagari through the fallback is %v wide, want %v", got, want)
+	}
+}
+
+func TestShape(t *testing.T) {
+	tests := []struct {
+		name, text, want string
+	}{
+		{"initial, lam-alef final and isolated", "سلام", "ﺳﻼﻡ"},
+		{"right-joining letter breaks the word", "مرحبا", "ﻣﺮﺣﺒﺎ"},
+		{"isolated lam-alef", "لا", "ﻻ"},
+		{"mark between letters", "بَب", "ﺑَﺐ"},
+		{"not Arabic", "Marina नमस्ते", "Marina नमस्ते"},
+	}
+	for _, tt := range tests {
+		got := Shape(tt.text)
+		if got != tt.want {
+			t.Errorf("%s: Shape(%q) = %+q, want %+q", tt.name, tt.text, got, tt.want)
+		}
+		if again := Shape(got); again != got {
+			t.Errorf("%s: shaping twice gave %+q", tt.name, again)
+		}
+	}
+}
+
+func TestVisual(t *testing.T) {
+	tests := []struct {
+		name, line, want string
+	}{
+		{"left to right", "Day 1: Marina Bay", "Day 1: Marina Bay"},
+		{"short i before its consonant", "किला", "िकला"},
+		{"short i before a conjunct", "स्थिति", "िस्थित"},
+		{"Hebrew run", "Hotel שלום", "Hotel םולש"},
+		{"digits keep their order", "שלום 42 עולם!", "םלוע 42 םולש!"},
+	}
+	for _, tt := range tests {
+		if got := Visual(tt.line); got != tt.want {
+			t.Errorf("%s: Visual(%q) = %+q, want %+q", tt.name, tt.line, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package fonts
+
+import (
+	"strings"
+	"unicode"
+)
+
+// gofpdf draws one glyph per code point, left to right, with no OpenType
+// shaping. Shape and Visual do the minimum needed for Arabic and Devanagari
+// names to read correctly: Arabic letters are swapped for their contextual
+// presentation forms and right-to-left runs are reversed, and the Devanagari
+// short i is moved in front of the consonant it follows. Conjunct ligatures
+// are not formed; clusters show with an explicit virama instead.
+
+// arabicForms lists isolated, final, initial and medial presentation forms.
+// Letters that only join to the right have no initial or medial form.
+var arabicForms = map[rune][4]rune{
+	0x0621: {0xFE80, 0, 0, 0},
+	0x0622: {0xFE81, 0xFE82, 0, 0},
+	0x0623: {0xFE83, 0xFE84, 0, 0},
+	0x0624: {0xFE85, 0xFE86, 0, 0},
+	0x0625: {0xFE87, 0xFE88, 0, 0},
+	0x0626: {0xFE89, 0xFE8A, 0xFE8B, 0xFE8C},
+	0x0627: {0xFE8D, 0xFE8E, 0, 0},
+	0x0628: {0xFE8F, 0xFE90, 0xFE91, 0xFE92},
+	0x0629: {0xFE93, 0xFE94, 0, 0},
+	0x062A: {0xFE95, 0xFE96, 0xFE97, 0xFE98},
+	0x062B: {0xFE99, 0xFE9A, 0xFE9B, 0xFE9C},
+	0x062C: {0xFE9D, 0xFE9E, 0xFE9F, 0xFEA0},
+	0x062D: {0xFEA1, 0xFEA2, 0xFEA3, 0xFEA4},
+	0x062E: {0xFEA5, 0xFEA6, 0xFEA7, 0xFEA8},
+	0x062F: {0xFEA9, 0xFEAA, 0, 0},
+	0x0630: {0xFEAB, 0xFEAC, 0, 0},
+	0x0631: {0xFEAD, 0xFEAE, 0, 0},
+	0x0632: {0xFEAF, 0xFEB0, 0, 0},
+	0x0633: {0xFEB1, 0xFEB2, 0xFEB3, 0xFEB4},
+	0x0634: {0xFEB5, 0xFEB6, 0xFEB7, 0xFEB8},
+	0x0635: {0xFEB9, 0xFEBA, 0xFEBB, 0xFEBC},
+	0x0636: {0xFEBD, 0xFEBE, 0xFEBF, 0xFEC0},
+	0x0637: {0xFEC1, 0xFEC2, 0xFEC3, 0xFEC4},
+	0x0638: {0xFEC5, 0xFEC6, 0xFEC7, 0xFEC8},
+	0x0639: {0xFEC9, 0xFECA, 0xFECB, 0xFECC},
+	0x063A: {0xFECD, 0xFECE, 0xFECF, 0xFED0},
+	0x0641: {0xFED1, 0xFED2, 0xFED3, 0xFED4},
+	0x0642: {0xFED5, 0xFED6, 0xFED7, 0xFED8},
+	0x0643: {0xFED9, 0xFEDA, 0xFEDB, 0xFEDC},
+	0x0644: {0xFEDD, 0xFEDE, 0xFEDF, 0xFEE0},
+	0x0645: {0xFEE1, 0xFEE2, 0xFEE3, 0xFEE4},
+	0x0646: {0xFEE5, 0xFEE6, 0xFEE7, 0xFEE8},
+	0x0647: {0xFEE9, 0xFEEA, 0xFEEB, 0xFEEC},
+	0x0648: {0xFEED, 0xFEEE, 0, 0},
+	0x0649: {0xFEEF, 0xFEF0, 0, 0},
+	0x064A: {0xFEF1, 0xFEF2, 0xFEF3, 0xFEF4},
+	0x067E: {0xFB56, 0xFB57, 0xFB58, 0xFB59},
+	0x0686: {0xFB7A, 0xFB7B, 0xFB7C, 0xFB7D},
+	0x0698: {0xFB8A, 0xFB8B, 0, 0},
+	0x06A9: {0xFB8E, 0xFB8F, 0xFB90, 0xFB91},
+	0x06AF: {0xFB92, 0xFB93, 0xFB94, 0xFB95},
+	0x06CC: {0xFBFC, 0xFBFD, 0xFBFE, 0xFBFF},
+}
+
+// lamAlef maps the alef following a lam to the isolated and final forms of
+// the mandatory ligature.
+var lamAlef = map[rune][2]rune{
+	0x0622: {0xFEF5, 0xFEF6},
+	0x0623: {0xFEF7, 0xFEF8},
+	0x0625: {0xFEF9, 0xFEFA},
+	0x0627: {0xFEFB, 0xFEFC},
+}
+
+const (
+	tatweel        = 0x0640
+	lam            = 0x0644
+	devanagariI    = 0x093F
+	devanagariHalf = 0x094D
+)
+
+func joinsBothSides(r rune) bool {
+	forms, ok := arabicForms[r]
+	return r == tatweel || (ok && forms[2] != 0)
+}
+
+func joins(r rune) bool {
+	_, ok := arabicForms[r]
+	return ok || r == tatweel
+}
+
+// isMark reports whether r is a combining mark that is transparent to joining
+// and belongs to the preceding character.
+func isMark(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc)
+}
+
+// Shape replaces Arabic letters with their contextual presentation forms. It
+// works on logical order and is safe to apply more than once.
+func Shape(text string) string {
+	if !strings.ContainsFunc(text, func(r rune) bool { return joins(r) }) {
+		return text
+	}
+
+	in := []rune(text)
+	out := make([]rune, 0, len(in))
+
+	neighbour := func(i, step int) rune {
+		for j := i + step; j >= 0 && j < len(in); j += step {
+			if !isMark(in[j]) {
+				return in[j]
+			}
+		}
+		return 0
+	}
+
+	for i := 0; i < len(in); i++ {
+		r := in[i]
+		forms, ok := arabicForms[r]
+		if !ok {
+			out = append(out, r)
+			continue
+		}
+
+		joinPrev := joinsBothSides(neighbour(i, -1))
+
+		if r == lam {
+			if next := neighbour(i, 1); next != 0 {
+				if lig, ok := lamAlef[next]; ok {
+					form := lig[0]
+					if joinPrev {
+						form = lig[1]
+					}
+					out = append(out, form)
+					// Drop the alef, keeping any marks between the two.
+					for i++; in[i] != next; i++ {
+						out = append(out, in[i])
+					}
+					continue
+				}
+			}
+		}
+
+		joinNext := forms[2] != 0 && joins(neighbour(i, 1))
+
+		switch {
+		case joinPrev && joinNext:
+			out = append(out, forms[3])
+		case joinNext:
+			out = append(out, forms[2])
+		case joinPrev && forms[1] != 0:
+			out = append(out, forms[1])
+		default:
+			out = append(out, forms[0])
+		}
+	}
+	return string(out)
+}
+
+// Visual turns one line of logical text into drawing order. Call it on each
+// line after wrapping, never on text that will be processed again.
+func Visual(line string) string {
+	in := []rune(line)
+	if !strings.ContainsFunc(line, func(r rune) bool { return isRTL(r) || r == devanagariI }) {
+		return line
+	}
+
+	reorderDevanagari(in)
+	reverseRTLRuns(in)
+	return string(in)
+}
+
+func reorderDevanagari(in []rune) {
+	for i := 1; i < len(in); i++ {
+		if in[i] != devanagariI {
+			continue
+		}
+		// Walk back over the consonant cluster: C (virama C)* with nuktas.
+		start := i - 1
+		for start > 0 && in[start] == 0x093C {
+			start--
+		}
+		if !isDevanagariConsonant(in[start]) {
+			continue
+		}
+		for start >= 2 && in[start-1] == devanagariHalf && isDevanagariConsonant(in[start-2]) {
+			start -= 2
+		}
+		copy(in[start+1:i+1], in[start:i])
+		in[start] = devanagariI
+	}
+}
+
+func isDevanagariConsonant(r rune) bool {
+	return (r >= 0x0915 && r <= 0x0939) || (r >= 0x0958 && r <= 0x095F)
+}
+
+func isRTL(r rune) bool {
+	return unicode.In(r, unicode.Arabic, unicode.Hebrew)
+}
+
+// reverseRTLRuns reverses every run of right-to-left characters, including
+// the spaces and marks between them, while keeping digits inside the run in
+// their reading order. The line itself stays left-to-right.
+func reverseRTLRuns(in []rune) {
+	for i := 0; i < len(in); {
+		if !isRTL(in[i]) {
+			i++
+			continue
+		}
+		end := i
+		for j := i; j < len(in); j++ {
+			if isRTL(in[j]) || isMark(in[j]) {
+				end = j + 1
+			} else if !unicode.IsSpace(in[j]) && !unicode.IsDigit(in[j]) && !unicode.IsPunct(in[j]) {
+				break
+			}
+		}
+
+		reverse(in[i:end])
+		for k := i; k < end; {
+			if !unicode.IsDigit(in[k]) {
+				k++
+				continue
+			}
+			d := k
+			for d < end && unicode.IsDigit(in[d]) {
+				d++
+			}
+			reverse(in[k:d])
+			k = d
+		}
+		i = end
+	}
+}
+
+func reverse(s []rune) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
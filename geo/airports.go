@@ -0,0 +1,125 @@
+package geo
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Airport is a commercial airport and the place it serves.
+type Airport struct {
+	// Code is the IATA airport code.
+	Code string
+	City string
+	// Aliases are other names the city goes by.
+	Aliases []string
+	// TimeZone is the IANA time zone name.
+	TimeZone string
+	Point
+}
+
+// airports are the airports trips sold from India most often use. Bookings
+// for anywhere else can still give time zones and coordinates themselves.
+var airports = []Airport{
+	{Code: "DEL", City: "Delhi", Aliases: []string{"New Delhi"}, TimeZone: "Asia/Kolkata", Point: Point{28.56, 77.10}},
+	{Code: "BOM", City: "Mumbai", Aliases: []string{"Bombay"}, TimeZone: "Asia/Kolkata", Point: Point{19.09, 72.87}},
+	{Code: "BLR", City: "Bengaluru", Aliases: []string{"Bangalore"}, TimeZone: "Asia/Kolkata", Point: Point{13.20, 77.71}},
+	{Code: "MAA", City: "Chennai", Aliases: []string{"Madras"}, TimeZone: "Asia/Kolkata", Point: Point{12.99, 80.17}},
+	{Code: "CCU", City: "Kolkata", Aliases: []string{"Calcutta"}, TimeZone: "Asia/Kolkata", Point: Point{22.65, 88.45}},
+	{Code: "HYD", City: "Hyderabad", TimeZone: "Asia/Kolkata", Point: Point{17.24, 78.43}},
+	{Code: "COK", City: "Kochi", Aliases: []string{"Cochin"}, TimeZone: "Asia/Kolkata", Point: Point{10.15, 76.40}},
+	{Code: "GOI", City: "Goa", TimeZone: "Asia/Kolkata", Point: Point{15.38, 73.83}},
+	{Code: "AMD", City: "Ahmedabad", TimeZone: "Asia/Kolkata", Point: Point{23.07, 72.63}},
+	{Code: "PNQ", City: "Pune", TimeZone: "Asia/Kolkata", Point: Point{18.58, 73.92}},
+	{Code: "JAI", City: "Jaipur", TimeZone: "Asia/Kolkata", Point: Point{26.82, 75.81}},
+	{Code: "ATQ", City: "Amritsar", TimeZone: "Asia/Kolkata", Point: Point{31.71, 74.80}},
+	{Code: "TRV", City: "Thiruvananthapuram", Aliases: []string{"Trivandrum"}, TimeZone: "Asia/Kolkata", Point: Point{8.48, 76.92}},
+	{Code: "SXR", City: "Srinagar", TimeZone: "Asia/Kolkata", Point: Point{33.99, 74.77}},
+	{Code: "IXL", City: "Leh", TimeZone: "Asia/Kolkata", Point: Point{34.14, 77.55}},
+
+	{Code: "SIN", City: "Singapore", TimeZone: "Asia/Singapore", Point: Point{1.36, 103.99}},
+	{Code: "DXB", City: "Dubai", TimeZone: "Asia/Dubai", Point: Point{25.25, 55.36}},
+	{Code: "AUH", City: "Abu Dhabi", TimeZone: "Asia/Dubai", Point: Point{24.43, 54.65}},
+	{Code: "DOH", City: "Doha", TimeZone: "Asia/Qatar", Point: Point{25.27, 51.61}},
+	{Code: "MCT", City: "Muscat", TimeZone: "Asia/Muscat", Point: Point{23.59, 58.28}},
+	{Code: "BKK", City: "Bangkok", TimeZone: "Asia/Bangkok", Point: Point{13.69, 100.75}},
+	{Code: "DMK", City: "Bangkok", TimeZone: "Asia/Bangkok", Point: Point{13.91, 100.61}},
+	{Code: "HKT", City: "Phuket", TimeZone: "Asia/Bangkok", Point: Point{8.11, 98.32}},
+	{Code: "KUL", City: "Kuala Lumpur", TimeZone: "Asia/Kuala_Lumpur", Point: Point{2.75, 101.71}},
+	{Code: "DPS", City: "Denpasar", Aliases: []string{"Bali"}, TimeZone: "Asia/Makassar", Point: Point{-8.75, 115.17}},
+	{Code: "MLE", City: "Malé", Aliases: []string{"Male", "Maldives"}, TimeZone: "Indian/Maldives", Point: Point{4.19, 73.53}},
+	{Code: "CMB", City: "Colombo", TimeZone: "Asia/Colombo", Point: Point{7.18, 79.88}},
+	{Code: "KTM", City: "Kathmandu", TimeZone: "Asia/Kathmandu", Point: Point{27.70, 85.36}},
+	{Code: "PBH", City: "Paro", TimeZone: "Asia/Thimphu", Point: Point{27.40, 89.42}},
+	{Code: "HKG", City: "Hong Kong", TimeZone: "Asia/Hong_Kong", Point: Point{22.31, 113.91}},
+	{Code: "NRT", City: "Tokyo", TimeZone: "Asia/Tokyo", Point: Point{35.77, 140.39}},
+	{Code: "HND", City: "Tokyo", TimeZone: "Asia/Tokyo", Point: Point{35.55, 139.78}},
+	{Code: "ICN", City: "Seoul", TimeZone: "Asia/Seoul", Point: Point{37.46, 126.44}},
+	{Code: "SGN", City: "Ho Chi Minh City", Aliases: []string{"Saigon"}, TimeZone: "Asia/Ho_Chi_Minh", Point: Point{10.82, 106.66}},
+	{Code: "HAN", City: "Hanoi", TimeZone: "Asia/Ho_Chi_Minh", Point: Point{21.22, 105.81}},
+	{Code: "GYD", City: "Baku", TimeZone: "Asia/Baku", Point: Point{40.47, 50.05}},
+	{Code: "TBS", City: "Tbilisi", TimeZone: "Asia/Tbilisi", Point: Point{41.67, 44.95}},
+	{Code: "ALA", City: "Almaty", TimeZone: "Asia/Almaty", Point: Point{43.35, 77.04}},
+
+	{Code: "IST", City: "Istanbul", TimeZone: "Europe/Istanbul", Point: Point{41.26, 28.74}},
+	{Code: "LHR", City: "London", TimeZone: "Europe/London", Point: Point{51.47, -0.45}},
+	{Code: "CDG", City: "Paris", TimeZone: "Europe/Paris", Point: Point{49.01, 2.55}},
+	{Code: "FRA", City: "Frankfurt", TimeZone: "Europe/Berlin", Point: Point{50.03, 8.56}},
+	{Code: "ZRH", City: "Zurich", Aliases: []string{"Zürich"}, TimeZone: "Europe/Zurich", Point: Point{47.46, 8.55}},
+	{Code: "AMS", City: "Amsterdam", TimeZone: "Europe/Amsterdam", Point: Point{52.31, 4.76}},
+	{Code: "FCO", City: "Rome", TimeZone: "Europe/Rome", Point: Point{41.80, 12.25}},
+	{Code: "VIE", City: "Vienna", TimeZone: "Europe/Vienna", Point: Point{48.11, 16.57}},
+	{Code: "MAD", City: "Madrid", TimeZone: "Europe/Madrid", Point: Point{40.49, -3.57}},
+	{Code: "BCN", City: "Barcelona", TimeZone: "Europe/Madrid", Point: Point{41.30, 2.08}},
+
+	{Code: "JFK", City: "New York", TimeZone: "America/New_York", Point: Point{40.64, -73.78}},
+	{Code: "ORD", City: "Chicago", TimeZone: "America/Chicago", Point: Point{41.98, -87.90}},
+	{Code: "SFO", City: "San Francisco", TimeZone: "America/Los_Angeles", Point: Point{37.62, -122.38}},
+	{Code: "LAX", City: "Los Angeles", TimeZone: "America/Los_Angeles", Point: Point{33.94, -118.41}},
+	{Code: "YYZ", City: "Toronto", TimeZone: "America/Toronto", Point: Point{43.68, -79.63}},
+
+	{Code: "SYD", City: "Sydney", TimeZone: "Australia/Sydney", Point: Point{-33.95, 151.18}},
+	{Code: "MEL", City: "Melbourne", TimeZone: "Australia/Melbourne", Point: Point{-37.67, 144.84}},
+	{Code: "AKL", City: "Auckland", TimeZone: "Pacific/Auckland", Point: Point{-37.01, 174.79}},
+
+	{Code: "MRU", City: "Mauritius", TimeZone: "Indian/Mauritius", Point: Point{-20.43, 57.68}},
+	{Code: "SEZ", City: "Mahé", Aliases: []string{"Mahe", "Seychelles"}, TimeZone: "Indian/Mahe", Point: Point{-4.67, 55.52}},
+	{Code: "CAI", City: "Cairo", TimeZone: "Africa/Cairo", Point: Point{30.12, 31.41}},
+	{Code: "NBO", City: "Nairobi", TimeZone: "Africa/Nairobi", Point: Point{-1.32, 36.93}},
+	{Code: "JNB", City: "Johannesburg", TimeZone: "Africa/Johannesburg", Point: Point{-26.14, 28.25}},
+}
+
+var codeInName = regexp.MustCompile(`\(([A-Z]{3})\)`)
+
+// FindAirport looks a place up by IATA code, like "SIN", by a name with the
+// code in brackets, like "Singapore (SIN)", or by city name. Codes must be
+// upper case so that a city like Goa is not taken for the code GOA. A city
+// with several airports gives the first one listed.
+func FindAirport(name string) (Airport, bool) {
+	name = strings.TrimSpace(name)
+	code := name
+	if m := codeInName.FindStringSubmatch(name); m != nil {
+		code = m[1]
+	}
+	for _, a := range airports {
+		if a.Code == code {
+			return a, true
+		}
+	}
+	for _, a := range airports {
+		if strings.EqualFold(a.City, name) {
+			return a, true
+		}
+		for _, alias := range a.Aliases {
+			if strings.EqualFold(alias, name) {
+				return a, true
+			}
+		}
+	}
+	return Airport{}, false
+}
+
+// TimeZone returns the IANA time zone of a place FindAirport knows, or "".
+func TimeZone(place string) string {
+	a, _ := FindAirport(place)
+	return a.TimeZone
+}
@@ -0,0 +1,126 @@
+// Package geo has the little spherical geometry the route map needs: great
+// circle paths between points and a projection onto a flat box. It also
+// knows the codes, positions and time zones of common airports.
+package geo
+
+import "math"
+
+// Point is a position in degrees.
+type Point struct {
+	Lat, Lon float64
+}
+
+const earthRadiusKm = 6371.0
+
+func radians(deg float64) float64 { return deg * math.Pi / 180 }
+func degrees(rad float64) float64 { return rad * 180 / math.Pi }
+
+func (p Point) vector() [3]float64 {
+	lat, lon := radians(p.Lat), radians(p.Lon)
+	return [3]float64{math.Cos(lat) * math.Cos(lon), math.Cos(lat) * math.Sin(lon), math.Sin(lat)}
+}
+
+// angle returns the central angle between a and b in radians.
+func angle(a, b Point) float64 {
+	va, vb := a.vector(), b.vector()
+	dot := va[0]*vb[0] + va[1]*vb[1] + va[2]*vb[2]
+	return math.Acos(math.Max(-1, math.Min(1, dot)))
+}
+
+// DistanceKm is the great circle distance between a and b.
+func DistanceKm(a, b Point) float64 {
+	return angle(a, b) * earthRadiusKm
+}
+
+// GreatCircle returns n+1 points along the shortest path from a to b,
+// including both ends. Longitudes are unwrapped, starting from a.Lon, so
+// consecutive points never jump across the antimeridian.
+func GreatCircle(a, b Point, n int) []Point {
+	omega := angle(a, b)
+	if omega < 1e-9 || n < 1 {
+		return []Point{a, {Lat: b.Lat, Lon: Unwrap(b.Lon, a.Lon)}}
+	}
+
+	va, vb := a.vector(), b.vector()
+	points := make([]Point, 0, n+1)
+	for i := 0; i <= n; i++ {
+		t := float64(i) / float64(n)
+		wa := math.Sin((1-t)*omega) / math.Sin(omega)
+		wb := math.Sin(t*omega) / math.Sin(omega)
+		x := wa*va[0] + wb*vb[0]
+		y := wa*va[1] + wb*vb[1]
+		z := wa*va[2] + wb*vb[2]
+		p := Point{
+			Lat: degrees(math.Atan2(z, math.Hypot(x, y))),
+			Lon: degrees(math.Atan2(y, x)),
+		}
+		ref := a.Lon
+		if len(points) > 0 {
+			ref = points[len(points)-1].Lon
+		}
+		p.Lon = Unwrap(p.Lon, ref)
+		points = append(points, p)
+	}
+	return points
+}
+
+// Unwrap shifts lon by whole turns so it is within 180 degrees of ref.
+func Unwrap(lon, ref float64) float64 {
+	for lon-ref > 180 {
+		lon -= 360
+	}
+	for lon-ref < -180 {
+		lon += 360
+	}
+	return lon
+}
+
+// Projection maps points into a box with an equirectangular projection
+// centred on the points' mean latitude, which keeps shapes right for the
+// regional trips the map is used for.
+type Projection struct {
+	minLon, maxLat float64
+	scale, kx      float64
+	x, y           float64
+}
+
+// minSpan stops a trip within one city from being blown up to fill the box.
+const minSpan = 0.5
+
+// Fit returns a projection that shows every point inside the w by h box at
+// x, y, centred and with margin left free on each side.
+func Fit(points []Point, x, y, w, h, margin float64) Projection {
+	minLat, maxLat := math.Inf(1), math.Inf(-1)
+	minLon, maxLon := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		minLat, maxLat = math.Min(minLat, p.Lat), math.Max(maxLat, p.Lat)
+		minLon, maxLon = math.Min(minLon, p.Lon), math.Max(maxLon, p.Lon)
+	}
+	if grow := minSpan - (maxLat - minLat); grow > 0 {
+		minLat, maxLat = minLat-grow/2, maxLat+grow/2
+	}
+	if grow := minSpan - (maxLon - minLon); grow > 0 {
+		minLon, maxLon = minLon-grow/2, maxLon+grow/2
+	}
+
+	kx := math.Cos(radians((minLat + maxLat) / 2))
+	spanX := math.Max((maxLon-minLon)*kx, 1e-6)
+	spanY := math.Max(maxLat-minLat, 1e-6)
+	scale := math.Min((w-2*margin)/spanX, (h-2*margin)/spanY)
+
+	// Centre the drawing in the box.
+	offX := x + (w-spanX*scale)/2
+	offY := y + (h-spanY*scale)/2
+	return Projection{minLon: minLon, maxLat: maxLat, scale: scale, kx: kx, x: offX, y: offY}
+}
+
+// Project returns the page position of p. Its longitude must already be
+// unwrapped the same way as the points the projection was fitted to.
+func (pr Projection) Project(p Point) (x, y float64) {
+	return pr.x + (p.Lon-pr.minLon)*pr.kx*pr.scale, pr.y + (pr.maxLat-p.Lat)*pr.scale
+}
+
+// Unproject is the inverse of Project.
+func (pr Projection) Unproject(x, y float64) Point {
+	return Point{Lat: pr.maxLat - (y-pr.y)/pr.scale, Lon: pr.minLon + (x-pr.x)/(pr.kx*pr.scale)}
+}
@@ -0,0 +1,176 @@
+// Package i18n has the message catalogs the PDF is written from and the
+// locale rules for dates, durations and numbers. English text doubles as the
+// message key, so an untranslated message reads as English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+//go:embed catalogs/*.json
+var catalogs embed.FS
+
+// Default is the locale used when a request does not pick one.
+const Default = "en"
+
+// Locale is one catalog with its formatting rules.
+type Locale struct {
+	Tag  string `json:"-"`
+	Name string `json:"name"`
+
+	// Months are the abbreviated month names, January first.
+	Months []string `json:"months"`
+	// Date lays out a date from {d} or {dd}, {month} and {yyyy}.
+	Date string `json:"date"`
+
+	Decimal string `json:"decimal"`
+	Group   string `json:"group"`
+	// Grouping is the size of the first digit group left of the decimal
+	// separator, then of every further group: [3] for 1,000,000 and [3, 2]
+	// for the Indian 10,00,000.
+	Grouping []int `json:"grouping"`
+
+	Messages map[string]string `json:"messages"`
+}
+
+var locales = map[string]*Locale{}
+
+func init() {
+	files, err := catalogs.ReadDir("catalogs")
+	if err != nil {
+		panic(err)
+	}
+	for _, file := range files {
+		raw, err := catalogs.ReadFile(path.Join("catalogs", file.Name()))
+		if err != nil {
+			panic(err)
+		}
+		l := &Locale{Tag: strings.TrimSuffix(file.Name(), ".json")}
+		if err := json.Unmarshal(raw, l); err != nil {
+			panic(fmt.Sprintf("i18n: %s: %v", file.Name(), err))
+		}
+		if len(l.Months) != 12 || l.Date == "" || len(l.Grouping) == 0 {
+			panic(fmt.Sprintf("i18n: %s: needs 12 months, a date layout and a grouping", file.Name()))
+		}
+		locales[l.Tag] = l
+	}
+	if locales[Default] == nil {
+		panic("i18n: no catalog for the default locale")
+	}
+}
+
+// Tags returns the available locales, sorted.
+func Tags() []string {
+	tags := make([]string, 0, len(locales))
+	for tag := range locales {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+// Get returns the locale for tag, or the default locale when there is no
+// catalog for it.
+func Get(tag string) *Locale {
+	if l, ok := locales[tag]; ok {
+		return l
+	}
+	return locales[Default]
+}
+
+// T translates msg and formats it with args like fmt.Sprintf. Translations
+// may reorder arguments with %[n]d verbs.
+func (l *Locale) T(msg string, args ...any) string {
+	if translated, ok := l.Messages[msg]; ok {
+		msg = translated
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// FormatDate formats a YYYY-MM-DD date, or returns "" when it does not
+// parse.
+func (l *Locale) FormatDate(date string) string {
+	t, err := time.Parse(time.DateOnly, date)
+	if err != nil {
+		return ""
+	}
+	return l.FormatTime(t)
+}
+
+// FormatTime formats the date part of t.
+func (l *Locale) FormatTime(t time.Time) string {
+	return strings.NewReplacer(
+		"{dd}", fmt.Sprintf("%02d", t.Day()),
+		"{d}", fmt.Sprint(t.Day()),
+		"{month}", l.Months[t.Month()-1],
+		"{yyyy}", fmt.Sprint(t.Year()),
+	).Replace(l.Date)
+}
+
+// FormatDuration describes an activity length in minutes. Without one it
+// guesses from the time slot the activity was given, like "Morning" or
+// "Full day".
+func (l *Locale) FormatDuration(minutes int, slot string) string {
+	if minutes > 0 {
+		hours, rest := minutes/60, minutes%60
+		switch {
+		case hours == 0:
+			return l.T("%d Minutes", minutes)
+		case rest != 0:
+			return l.T("%d:%02d Hours", hours, rest)
+		case hours == 1:
+			return l.T("1 Hour")
+		default:
+			return l.T("%d Hours", hours)
+		}
+	}
+
+	slot = strings.ToLower(slot)
+	switch {
+	case strings.Contains(slot, "morning") || strings.Contains(slot, "afternoon") || strings.Contains(slot, "evening"):
+		return l.T("2-3 Hours")
+	case strings.Contains(slot, "full day") || strings.Contains(slot, "all day"):
+		return l.T("Full Day")
+	case strings.Contains(slot, "half day"):
+		return l.T("Half Day")
+	}
+	return l.T("2-3 Hours")
+}
+
+// FormatNumber formats v with the locale's separators and the given number
+// of decimals.
+func (l *Locale) FormatNumber(v float64, decimals int) string {
+	s := fmt.Sprintf("%.*f", decimals, math.Abs(v))
+	whole, frac, _ := strings.Cut(s, ".")
+
+	var groups []string
+	for i := 0; len(whole) > 0; i++ {
+		size := l.Grouping[min(i, len(l.Grouping)-1)]
+		if len(whole) <= size {
+			groups = append(groups, whole)
+			break
+		}
+		groups = append(groups, whole[len(whole)-size:])
+		whole = whole[:len(whole)-size]
+	}
+	slices.Reverse(groups)
+
+	s = strings.Join(groups, l.Group)
+	if frac != "" {
+		s += l.Decimal + frac
+	}
+	if v < 0 && strings.Trim(s, "0"+l.Group+l.Decimal) != "" {
+		s = "-" + s
+	}
+	return s
+}
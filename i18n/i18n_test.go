@@ -0,0 +1,134 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// sourceMessages returns the string literals the module passes to a T
+// method, by file and line.
+func sourceMessages(t *testing.T) map[string]string {
+	t.Helper()
+	messages := map[string]string{}
+	fset := token.NewFileSet()
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == "testdata" || strings.HasPrefix(d.Name(), ".")) && path != ".." {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			if sel, ok := call.Fun.(*ast.SelectorExpr); !ok || sel.Sel.Name != "T" {
+				return true
+			}
+			if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				msg, _ := strconv.Unquote(lit.Value)
+				messages[msg] = fset.Position(lit.Pos()).String()
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) < 50 {
+		t.Fatalf("found only %d messages in the source", len(messages))
+	}
+	return messages
+}
+
+func TestCatalogsComplete(t *testing.T) {
+	en := Get(Default)
+	if len(en.Messages) != 0 {
+		t.Errorf("%s.json has messages; English text is the key", Default)
+	}
+	source := sourceMessages(t)
+	for _, tag := range Tags() {
+		l := Get(tag)
+		if l.Tag != tag || l.Name == "" || l.Decimal == "" || l.Decimal == l.Group {
+			t.Errorf("%s: incomplete formatting rules", tag)
+		}
+		for _, field := range []string{"{month}", "{yyyy}"} {
+			if !strings.Contains(l.Date, field) {
+				t.Errorf("%s: date layout %q has no %s", tag, l.Date, field)
+			}
+		}
+		if tag == Default {
+			continue
+		}
+		for msg, at := range source {
+			if _, ok := l.Messages[msg]; !ok {
+				t.Errorf("%s: no translation of %q, used at %s", tag, msg, at)
+			}
+		}
+		// Every catalog translates the same messages.
+		for _, other := range Tags() {
+			if other == Default || other == tag {
+				continue
+			}
+			for msg := range Get(other).Messages {
+				if _, ok := l.Messages[msg]; !ok {
+					t.Errorf("%s: no translation of %q, which %s has", tag, msg, other)
+				}
+			}
+		}
+	}
+}
+
+var verbRe = regexp.MustCompile(`%(?:\[(\d+)\])?[-+# 0]*\d*(?:\.\d+)?([a-zA-Z%])`)
+
+// verbs lists the verb of each argument a format uses, by argument
+// position, following fmt's rules for explicit indexes.
+func verbs(format string) []string {
+	var args []string
+	next := 0
+	for _, m := range verbRe.FindAllStringSubmatch(format, -1) {
+		if m[2] == "%" {
+			continue
+		}
+		if m[1] != "" {
+			next, _ = strconv.Atoi(m[1])
+			next--
+		}
+		for len(args) <= next {
+			args = append(args, "")
+		}
+		args[next] = m[2]
+		next++
+	}
+	return args
+}
+
+func TestCatalogVerbs(t *testing.T) {
+	if got := verbs("%[3]s a %[1]d b %s %%"); !slices.Equal(got, []string{"d", "s", "s"}) {
+		t.Fatalf("verbs = %q", got)
+	}
+	for _, tag := range Tags() {
+		for msg, translated := range Get(tag).Messages {
+			if want, got := verbs(msg), verbs(translated); !slices.Equal(got, want) {
+				t.Errorf("%s: %q has verbs %q, want %q as in %q", tag, translated, got, want, msg)
+			}
+		}
+	}
+}
@@ -0,0 +1,356 @@
+// Package ical writes a booking as an RFC 5545 calendar that travellers can
+// add to their phone: flights as timed events in the time zones of their
+// airports, hotel stays as all-day events spanning the nights booked and
+// activities at their time on the day.
+package ical
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	// Time zones must resolve the same on servers without a zoneinfo
+	// database.
+	_ "time/tzdata"
+
+	"github.com/monoMonu/travel-itinerary-pdf/geo"
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/markdown"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+)
+
+// Config is what the calendar needs besides the booking.
+type Config struct {
+	// Company names the calendar's producer.
+	Company string
+	// Domain ends every UID. It must stay the same for a deployment, or
+	// calendars will add re-imported events a second time instead of
+	// updating them.
+	Domain string
+	// Now is the time the calendar is written, stamped on every event.
+	Now    time.Time
+	Locale *i18n.Locale
+}
+
+// defaultActivityLength is used for activities with a time but no duration,
+// matching the "2-3 Hours" the PDF shows for them.
+const defaultActivityLength = 2 * time.Hour
+
+// stamp is a point in time in an event: a date, a wall-clock time in a time
+// zone, or a floating wall-clock time when the zone is not known.
+type stamp struct {
+	t    time.Time
+	zone string
+	date bool
+}
+
+func (s stamp) property(name string) string {
+	switch {
+	case s.date:
+		return name + ";VALUE=DATE:" + s.t.Format("20060102")
+	case s.zone != "":
+		return name + ";TZID=" + s.zone + ":" + s.t.Format("20060102T150405")
+	}
+	return name + ":" + s.t.Format("20060102T150405")
+}
+
+type event struct {
+	uid                            string
+	summary, description, location string
+	start                          stamp
+	// end is left zero for an event that is an instant, like a flight
+	// without an arrival time.
+	end         stamp
+	geo         *types.Location
+	transparent bool
+}
+
+// Write writes data as a calendar to w.
+func Write(w io.Writer, data types.BookingData, cfg Config) error {
+	l := cfg.Locale
+	if l == nil {
+		l = i18n.Get(i18n.Default)
+	}
+	c := calendar{data: data, cfg: cfg, l: l, key: bookingKey(data), zones: map[string][]time.Time{}}
+	c.addFlights()
+	c.addHotels()
+	c.addActivities()
+	return c.write(w)
+}
+
+type calendar struct {
+	data   types.BookingData
+	cfg    Config
+	l      *i18n.Locale
+	key    string
+	events []event
+	// zones holds the times each time zone is used at, for its VTIMEZONE.
+	zones map[string][]time.Time
+}
+
+// bookingKey identifies the booking in UIDs: its reference, or failing that
+// the customer, destination and dates, which do not change when the
+// itinerary is revised.
+func bookingKey(data types.BookingData) string {
+	if ref := utils.SanitizeFileName(data.BookingReference); ref != "" {
+		return strings.ToLower(ref)
+	}
+	sum := sha1.Sum([]byte(strings.Join([]string{data.CustomerName, data.Destination, data.DepartureDate}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// uid names the n-th item of a kind on a date. Positions rather than
+// contents are used, so editing an item updates it in place.
+func (c *calendar) uid(kind string, date time.Time, n int) string {
+	return fmt.Sprintf("%s-%s-%s-%d@%s", c.key, kind, date.Format("20060102"), n, c.cfg.Domain)
+}
+
+// at is the wall-clock time on date in zone, which is remembered for the
+// calendar's time zone definitions.
+func (c *calendar) at(date time.Time, clock time.Duration, zone string) stamp {
+	local := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).Add(clock)
+	if zone != "" {
+		c.zones[zone] = append(c.zones[zone], local)
+	}
+	return stamp{t: local, zone: zone}
+}
+
+// instant is when a stamp happens, taking a floating time as UTC.
+func instant(s stamp) time.Time {
+	loc := time.UTC
+	if zone, err := time.LoadLocation(s.zone); err == nil && s.zone != "" {
+		loc = zone
+	}
+	t := s.t
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, loc)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func (c *calendar) addFlights() {
+	perDay := map[time.Time]int{}
+	for _, f := range c.data.Flights {
+		date, err := utils.ConvertStringToTime(f.Date)
+		if err != nil {
+			continue
+		}
+		perDay[date]++
+		e := event{
+			uid:      c.uid("flight", date, perDay[date]),
+			summary:  strings.TrimPrefix(f.Airline+": "+f.From+" → "+f.To, ": "),
+			location: f.From,
+		}
+		if ref := c.data.BookingReference; ref != "" {
+			e.description = c.l.T("Booking reference") + ": " + ref
+		}
+
+		departs, ok := utils.ParseClock(f.Departure)
+		if !ok {
+			e.start, e.end = stamp{t: date, date: true}, stamp{t: date.AddDate(0, 0, 1), date: true}
+			c.events = append(c.events, e)
+			continue
+		}
+		fromZone := firstNonEmpty(f.FromTimeZone, geo.TimeZone(f.From))
+		toZone := firstNonEmpty(f.ToTimeZone, geo.TimeZone(f.To))
+		e.start = c.at(date, departs, fromZone)
+
+		if arrives, ok := utils.ParseClock(f.Arrival); ok {
+			// Arrival is on the departure date or, for overnight and
+			// long-haul flights, a day or two later: the first that puts it
+			// after the departure. Without both zones the clocks are
+			// compared as they are.
+			when := func(s stamp) time.Time {
+				if fromZone == "" || toZone == "" {
+					s.zone = ""
+				}
+				return instant(s)
+			}
+			day := date
+			for i := 0; i < 2 && !when(stamp{t: day.Add(arrives), zone: toZone}).After(when(e.start)); i++ {
+				day = day.AddDate(0, 0, 1)
+			}
+			e.end = c.at(day, arrives, toZone)
+		}
+		c.events = append(c.events, e)
+	}
+}
+
+func (c *calendar) addHotels() {
+	perDay := map[time.Time]int{}
+	for _, h := range c.data.Hotels {
+		in, err1 := utils.ConvertStringToTime(h.CheckIn)
+		out, err2 := utils.ConvertStringToTime(h.CheckOut)
+		if err1 != nil || err2 != nil || !out.After(in) {
+			continue
+		}
+		perDay[in]++
+		nights := int(out.Sub(in).Hours() / 24)
+		c.events = append(c.events, event{
+			uid:      c.uid("hotel", in, perDay[in]),
+			summary:  h.Name,
+			location: strings.Trim(h.Name+", "+h.City, ", "),
+			description: strings.Join([]string{
+				c.l.T("Check In") + ": " + c.l.FormatDate(h.CheckIn),
+				c.l.T("Check Out") + ": " + c.l.FormatDate(h.CheckOut),
+				c.l.T("Nights") + ": " + c.l.FormatNumber(float64(nights), 0),
+			}, "\n"),
+			// The end date is exclusive, so the stay covers the nights booked.
+			start:       stamp{t: in, date: true},
+			end:         stamp{t: out, date: true},
+			geo:         h.Location,
+			transparent: true,
+		})
+	}
+}
+
+// zoneOn is the time zone and city for activities on date. They happen
+// where the hotel for the night is or, on the day of check-out, where the
+// last night was. A zone the booking gives wins over one looked up by city.
+func (c *calendar) zoneOn(date string) (zone, city string) {
+	var stay *types.Hotel
+	for i, h := range c.data.Hotels {
+		if h.CheckIn <= date && date < h.CheckOut {
+			stay = &c.data.Hotels[i]
+			break
+		}
+		if h.CheckOut == date && stay == nil {
+			stay = &c.data.Hotels[i]
+		}
+	}
+	if stay == nil {
+		return firstNonEmpty(c.data.TimeZone, geo.TimeZone(c.data.Destination)), c.data.Destination
+	}
+	return firstNonEmpty(stay.TimeZone, c.data.TimeZone, geo.TimeZone(stay.City), geo.TimeZone(c.data.Destination)), stay.City
+}
+
+func (c *calendar) addActivities() {
+	for _, day := range c.data.Days {
+		date, err := utils.ConvertStringToTime(day.Date)
+		if err != nil {
+			continue
+		}
+		zone, city := c.zoneOn(day.Date)
+		for i, a := range day.Activities {
+			e := event{
+				uid:         c.uid("activity", date, i+1),
+				summary:     markdown.PlainText(a.Title),
+				description: markdown.Lines(a.Description),
+				location:    city,
+			}
+			if clock, ok := utils.ParseClock(a.Time); ok {
+				length := time.Duration(a.Duration) * time.Minute
+				if length <= 0 {
+					length = defaultActivityLength
+				}
+				e.start = c.at(date, clock, zone)
+				e.end = c.at(date, clock+length, zone)
+			} else {
+				// "Full day" and other slots without a clock time.
+				e.start, e.end = stamp{t: date, date: true}, stamp{t: date.AddDate(0, 0, 1), date: true}
+				e.transparent = true
+			}
+			c.events = append(c.events, e)
+		}
+	}
+}
+
+func (c *calendar) write(w io.Writer) error {
+	out := &writer{w: w}
+	out.line("BEGIN:VCALENDAR")
+	out.line("VERSION:2.0")
+	out.line("PRODID:-//" + firstNonEmpty(c.cfg.Company, "Travel Itinerary") + "//Itinerary//" + strings.ToUpper(c.l.Tag))
+	out.line("CALSCALE:GREGORIAN")
+	out.line("METHOD:PUBLISH")
+	out.line("X-WR-CALNAME:" + escape(c.l.T("%s Itinerary", c.data.Destination)))
+
+	zones := make([]string, 0, len(c.zones))
+	for zone := range c.zones {
+		zones = append(zones, zone)
+	}
+	sort.Strings(zones)
+	for _, zone := range zones {
+		if err := writeZone(out, zone, c.zones[zone]); err != nil {
+			return err
+		}
+	}
+
+	status := "CONFIRMED"
+	switch c.data.Status {
+	case types.StatusQuote, types.StatusProvisional:
+		status = "TENTATIVE"
+	case types.StatusCancelled:
+		status = "CANCELLED"
+	}
+	stamp := c.cfg.Now.UTC().Format("20060102T150405Z")
+	for _, e := range c.events {
+		out.line("BEGIN:VEVENT")
+		out.line("UID:" + escape(e.uid))
+		out.line("DTSTAMP:" + stamp)
+		out.line(e.start.property("DTSTART"))
+		if !e.end.t.IsZero() {
+			out.line(e.end.property("DTEND"))
+		}
+		out.line("SUMMARY:" + escape(e.summary))
+		if e.description != "" {
+			out.line("DESCRIPTION:" + escape(e.description))
+		}
+		if e.location != "" {
+			out.line("LOCATION:" + escape(e.location))
+		}
+		if e.geo != nil {
+			out.line(fmt.Sprintf("GEO:%.6f;%.6f", e.geo.Lat, e.geo.Lon))
+		}
+		out.line("STATUS:" + status)
+		if e.transparent {
+			out.line("TRANSP:TRANSPARENT")
+		}
+		out.line("END:VEVENT")
+	}
+	out.line("END:VCALENDAR")
+	return out.err
+}
+
+// escape quotes TEXT values as RFC 5545 section 3.3.11 asks.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// writer writes content lines, folded at 75 octets without splitting a
+// character, with CRLF line ends. The first error sticks.
+type writer struct {
+	w   io.Writer
+	err error
+}
+
+const maxLineOctets = 75
+
+func (w *writer) line(s string) {
+	if w.err != nil {
+		return
+	}
+	var b strings.Builder
+	width := 0
+	for _, r := range s {
+		size := len(string(r))
+		if width+size > maxLineOctets {
+			b.WriteString("\r\n ")
+			// The leading space counts towards the folded line.
+			width = 1
+		}
+		b.WriteRune(r)
+		width += size
+	}
+	b.WriteString("\r\n")
+	_, w.err = io.WriteString(w.w, b.String())
+}
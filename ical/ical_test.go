@@ -0,0 +1,117 @@
+package ical
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// dstBooking is a trip to New York that starts while London and New York
+// are on summer time, and ends after each has gone back to standard time:
+// London on 26 October, New York on 2 November 2025.
+func dstBooking() types.BookingData {
+	return types.BookingData{
+		BookingReference: "VG-DST/1",
+		CustomerName:     "Rahul Sharma",
+		Destination:      "New York",
+		DepartureDate:    "2025-10-24",
+		ReturnDate:       "2025-11-04",
+		Flights: []types.Flight{
+			{Date: "2025-10-24", Airline: "British Airways BA117", From: "LHR", To: "JFK", Departure: "08:25", Arrival: "11:05"},
+			// Overnight, arriving the next morning in London.
+			{Date: "2025-11-03", Airline: "British Airways BA178", From: "JFK", To: "LHR", Departure: "21:30", Arrival: "09:35"},
+			{Date: "2025-11-04", Airline: "Air India", From: "Heathrow", To: "Mumbai"},
+		},
+		Hotels: []types.Hotel{
+			{City: "New York", Name: "The Plaza; Fifth Avenue", CheckIn: "2025-10-24", CheckOut: "2025-11-03", Nights: 10, Location: &types.Location{Lat: 40.764611, Lon: -73.974556}},
+		},
+		Days: []types.Day{
+			{Date: "2025-10-25", Activities: []types.Activity{
+				{Time: "10:00", Title: "**Statue of Liberty** ferry", Duration: 180, Description: "Ferry from Battery Park, then the pedestal, crown and the museum on Liberty Island; allow time for security."},
+			}},
+			{Date: "2025-11-02", Activities: []types.Activity{
+				// An hour that happens twice as the clocks go back.
+				{Time: "01:30", Title: "Late jazz, Village Vanguard", Duration: 60},
+				{Time: "Full day", Title: "New York City Marathon"},
+			}},
+		},
+	}
+}
+
+// sydneyBooking spans the end of a year in Sydney, where summer time is in
+// force on the first of January and starts again on 5 October 2025, and
+// gives the zone of an airport the airport list does not know.
+func sydneyBooking() types.BookingData {
+	return types.BookingData{
+		CustomerName:  "Priya Nair",
+		Destination:   "Sydney",
+		DepartureDate: "2025-12-30",
+		TimeZone:      "Australia/Sydney",
+		Status:        types.StatusQuote,
+		Flights: []types.Flight{
+			{Date: "2025-12-30", Airline: "Singapore Airlines SQ231", From: "SIN", To: "SYD", Departure: "21:10", Arrival: "07:35"},
+			{Date: "2026-01-02", Airline: "Qantas", From: "Sydney", To: "Hobart", ToTimeZone: "Australia/Hobart", Departure: "09:00", Arrival: "10:55"},
+		},
+		Days: []types.Day{
+			{Date: "2025-12-31", Activities: []types.Activity{
+				{Time: "21:00", Title: "Harbour fireworks", Duration: 240},
+			}},
+		},
+	}
+}
+
+func TestWriteGolden(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   types.BookingData
+		locale string
+	}{
+		{"dst", dstBooking(), "en"},
+		{"sydney", sydneyBooking(), "fr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			cfg := Config{
+				Company: "Vigovia",
+				Domain:  "itinerary.test",
+				Now:     time.Date(2025, time.October, 1, 12, 30, 0, 0, time.FixedZone("IST", 5*3600+1800)),
+				Locale:  i18n.Get(tt.locale),
+			}
+			if err := Write(&buf, tt.data, cfg); err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join("testdata", tt.name+".ics")
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("calendar differs from %s; run go test -update after checking it:\n%s", golden, buf.String())
+			}
+		})
+	}
+}
+
+func TestWriterFolds(t *testing.T) {
+	var buf bytes.Buffer
+	w := &writer{w: &buf}
+	w.line("DESCRIPTION:" + string(bytes.Repeat([]byte("a"), 61)) + "जयपुर")
+	want := "DESCRIPTION:" + string(bytes.Repeat([]byte("a"), 61)) + "\r\n जयपुर\r\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
@@ -0,0 +1,69 @@
+package ical
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// writeZone writes a VTIMEZONE for zone that covers the given wall-clock
+// times: the offset in force on the first of January before the earliest,
+// and every change until the end of the year of the latest. Go's zone data
+// has no recurrence rules, so each change is listed as it happens.
+func writeZone(out *writer, zone string, times []time.Time) error {
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		return err
+	}
+	first, last := slices.MinFunc(times, time.Time.Compare), slices.MaxFunc(times, time.Time.Compare)
+	start := time.Date(first.Year(), time.January, 1, 0, 0, 0, 0, loc)
+	end := time.Date(last.Year()+1, time.January, 1, 0, 0, 0, 0, loc)
+
+	out.line("BEGIN:VTIMEZONE")
+	out.line("TZID:" + zone)
+	name, offset := start.Zone()
+	observance(out, start.IsDST(), start, offset, offset, name)
+	for t := start; ; {
+		_, next := t.ZoneBounds()
+		if next.IsZero() || !next.Before(end) {
+			break
+		}
+		name, to := next.Zone()
+		// An observance starts at the local time of the change, on the
+		// clock in force before it.
+		onset := next.In(time.FixedZone("", offset))
+		observance(out, next.IsDST(), onset, offset, to, name)
+		t, offset = next, to
+	}
+	out.line("END:VTIMEZONE")
+	return nil
+}
+
+func observance(out *writer, dst bool, onset time.Time, from, to int, name string) {
+	kind := "STANDARD"
+	if dst {
+		kind = "DAYLIGHT"
+	}
+	out.line("BEGIN:" + kind)
+	out.line("DTSTART:" + onset.Format("20060102T150405"))
+	out.line("TZOFFSETFROM:" + utcOffset(from))
+	out.line("TZOFFSETTO:" + utcOffset(to))
+	if name != "" {
+		out.line("TZNAME:" + escape(name))
+	}
+	out.line("END:" + kind)
+}
+
+// utcOffset formats seconds east of UTC as +hhmm, or +hhmmss when there
+// are seconds.
+func utcOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign, seconds = "-", -seconds
+	}
+	s := fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+	if seconds%60 != 0 {
+		s += fmt.Sprintf("%02d", seconds%60)
+	}
+	return s
+}
@@ -0,0 +1,117 @@
+// Package imaging checks the pictures attached to a booking and shrinks them
+// before they are embedded in a PDF.
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/gabriel-vasile/mimetype"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// Longest side, in pixels, kept for each use. At the sizes the PDF prints
+// them this is roughly 200 dpi.
+const (
+	CoverSide     = 1600
+	ThumbnailSide = 600
+)
+
+// maxPixels guards against images that are small on the wire but huge once
+// decoded.
+const maxPixels = 50_000_000
+
+const jpegQuality = 80
+
+var accepted = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// Prepare decodes data, scales it down so its longest side is at most
+// maxSide pixels, and re-encodes it: as JPEG when it is opaque, as PNG when
+// transparency has to be kept.
+func Prepare(data []byte, maxSide int) ([]byte, error) {
+	kind := mimetype.Detect(data).String()
+	if !accepted[kind] {
+		return nil, fmt.Errorf("unsupported image type %s", kind)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("image is %dx%d pixels, too large", cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decoding image: %w", err)
+	}
+
+	opaque := isOpaque(img)
+	img = scale(img, maxSide, opaque)
+
+	var out bytes.Buffer
+	if opaque {
+		err = jpeg.Encode(&out, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = (&png.Encoder{CompressionLevel: png.BestCompression}).Encode(&out, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// Type returns the gofpdf image type for data ("JPG", "PNG" or "GIF"), or ""
+// when gofpdf cannot embed it.
+func Type(data []byte) string {
+	switch mimetype.Detect(data).String() {
+	case "image/jpeg":
+		return "JPG"
+	case "image/png":
+		return "PNG"
+	case "image/gif":
+		return "GIF"
+	}
+	return ""
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+func scale(img image.Image, maxSide int, opaque bool) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+	if w >= h {
+		w, h = maxSide, max(1, h*maxSide/w)
+	} else {
+		w, h = max(1, w*maxSide/h), maxSide
+	}
+
+	var dst draw.Image
+	if opaque {
+		dst = image.NewRGBA(image.Rect(0, 0, w, h))
+	} else {
+		dst = image.NewNRGBA(image.Rect(0, 0, w, h))
+	}
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, b, xdraw.Src, nil)
+	return dst
+}
@@ -0,0 +1,244 @@
+// Package mail emails itineraries: an HTML body summarising the trip, from
+// a template the API client can choose, with the PDF attached or linked,
+// sent through the configured SMTP server.
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/monoMonu/travel-itinerary-pdf/config"
+)
+
+// timeout bounds a whole delivery, from connecting to QUIT.
+const timeout = time.Minute
+
+// Message is an HTML email with attachments.
+type Message struct {
+	From    *mail.Address
+	To      []*mail.Address
+	Cc      []*mail.Address
+	ReplyTo *mail.Address
+	Subject string
+	HTML    string
+	Date    time.Time
+	// ID is the Message-ID without its angle brackets. Send sets one when
+	// it is empty.
+	ID          string
+	Attachments []Attachment
+}
+
+// Attachment is a file sent with a Message.
+type Attachment struct {
+	Name        string
+	ContentType string
+	Data        []byte
+}
+
+// Recipients are the envelope recipients, To then Cc.
+func (m *Message) Recipients() []string {
+	var list []string
+	for _, a := range append(m.To, m.Cc...) {
+		list = append(list, a.Address)
+	}
+	return list
+}
+
+// Bytes is the message in RFC 5322 form: the HTML body alone, or in a
+// multipart/mixed message when there are attachments.
+func (m *Message) Bytes() ([]byte, error) {
+	var b bytes.Buffer
+	header := func(key, value string) {
+		fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+	}
+	header("From", m.From.String())
+	header("To", addressList(m.To))
+	if len(m.Cc) > 0 {
+		header("Cc", addressList(m.Cc))
+	}
+	if m.ReplyTo != nil {
+		header("Reply-To", m.ReplyTo.String())
+	}
+	header("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header("Date", m.Date.Format(time.RFC1123Z))
+	header("Message-ID", "<"+m.ID+">")
+	header("MIME-Version", "1.0")
+
+	if len(m.Attachments) == 0 {
+		header("Content-Type", `text/html; charset="utf-8"`)
+		header("Content-Transfer-Encoding", "quoted-printable")
+		b.WriteString("\r\n")
+		if err := writeQuotedPrintable(&b, m.HTML); err != nil {
+			return nil, err
+		}
+		return b.Bytes(), nil
+	}
+
+	parts := multipart.NewWriter(&b)
+	header("Content-Type", `multipart/mixed; boundary="`+parts.Boundary()+`"`)
+	b.WriteString("\r\n")
+
+	body, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {`text/html; charset="utf-8"`},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := writeQuotedPrintable(body, m.HTML); err != nil {
+		return nil, err
+	}
+
+	for _, a := range m.Attachments {
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {mime.FormatMediaType(a.ContentType, map[string]string{"name": a.Name})},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Name})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(part, a.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func addressList(list []*mail.Address) string {
+	s := make([]string, len(list))
+	for i, a := range list {
+		s[i] = a.String()
+	}
+	return strings.Join(s, ", ")
+}
+
+func writeQuotedPrintable(w io.Writer, s string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := io.WriteString(qp, s); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeBase64 wraps lines at 76 characters, as MIME requires.
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := min(len(encoded), 76)
+		if _, err := io.WriteString(w, encoded[:n]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
+
+// newID is a Message-ID in the sender's domain.
+func newID(from *mail.Address) (string, error) {
+	token := make([]byte, 12)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	domain := "localhost"
+	if _, d, ok := strings.Cut(from.Address, "@"); ok {
+		domain = d
+	}
+	return hex.EncodeToString(token) + "@" + domain, nil
+}
+
+// Send delivers m through server, filling in its date and ID when they are
+// not set.
+func Send(server config.SMTP, m *Message) error {
+	if !server.Enabled() {
+		return errors.New("email delivery is not configured")
+	}
+	if m.Date.IsZero() {
+		m.Date = time.Now()
+	}
+	if m.ID == "" {
+		id, err := newID(m.From)
+		if err != nil {
+			return err
+		}
+		m.ID = id
+	}
+	raw, err := m.Bytes()
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(server.Host, strconv.Itoa(server.Port))
+	tlsConfig := &tls.Config{ServerName: server.Host}
+	dialer := &net.Dialer{Timeout: timeout}
+	var conn net.Conn
+	if server.Security == config.SMTPTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	c, err := smtp.NewClient(conn, server.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("smtp: %w", err)
+	}
+	defer c.Close()
+
+	if server.Security == config.SMTPStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("smtp: the server does not offer STARTTLS")
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("smtp: %w", err)
+		}
+	}
+	if server.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", server.Username, server.Password, server.Host)); err != nil {
+			return fmt.Errorf("smtp: %w", err)
+		}
+	}
+
+	if err := c.Mail(m.From.Address); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	for _, rcpt := range m.Recipients() {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("smtp: %s: %w", rcpt, err)
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	if _, err := w.Write(raw); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("smtp: %w", err)
+	}
+	return c.Quit()
+}
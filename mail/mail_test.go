@@ -0,0 +1,90 @@
+package mail
+
+import (
+	"encoding/base64"
+	"mime"
+	"net/mail"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/monoMonu/travel-itinerary-pdf/config"
+	"github.com/monoMonu/travel-itinerary-pdf/mail/mailtest"
+)
+
+func testMessage() *Message {
+	return &Message{
+		From:    &mail.Address{Name: "Vigovia Trips", Address: "trips@vigovia.test"},
+		To:      []*mail.Address{{Address: "rahul@example.com"}, {Address: "priya@example.com"}},
+		Cc:      []*mail.Address{{Name: "Asha Rao", Address: "asha@agency.test"}},
+		ReplyTo: &mail.Address{Name: "Asha Rao", Address: "asha@agency.test"},
+		Subject: "Your Singapore itinerary ✈",
+		HTML:    "<p>Your itinerary for Singapore is ready.</p>",
+		Attachments: []Attachment{
+			{Name: "Rahul_Sharma_Singapore_itinerary.pdf", ContentType: "application/pdf", Data: []byte("%PDF-1.4 test")},
+		},
+	}
+}
+
+func TestSend(t *testing.T) {
+	srv := mailtest.NewServer(t)
+	m := testMessage()
+	err := Send(config.SMTP{Host: srv.Host, Port: srv.Port, Security: config.SMTPNone}, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := srv.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("server got %d messages, want 1", len(msgs))
+	}
+	env := msgs[0]
+	if env.From != "trips@vigovia.test" {
+		t.Errorf("MAIL FROM %q", env.From)
+	}
+	if want := []string{"rahul@example.com", "priya@example.com", "asha@agency.test"}; !slices.Equal(env.To, want) {
+		t.Errorf("RCPT TO %v, want %v", env.To, want)
+	}
+
+	parsed, err := mail.ReadMessage(strings.NewReader(env.Data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"To":         "<rahul@example.com>, <priya@example.com>",
+		"Cc":         `"Asha Rao" <asha@agency.test>`,
+		"Reply-To":   `"Asha Rao" <asha@agency.test>`,
+		"Message-Id": "<" + m.ID + ">",
+	} {
+		if got := parsed.Header.Get(key); got != want {
+			t.Errorf("%s: %q, want %q", key, got, want)
+		}
+	}
+	if subject, err := new(mime.WordDecoder).DecodeHeader(parsed.Header.Get("Subject")); err != nil || subject != m.Subject {
+		t.Errorf("Subject: %q, %v", subject, err)
+	}
+	if m.ID == "" || !strings.HasSuffix(m.ID, "@vigovia.test") {
+		t.Errorf("Message-ID %q is not in the sender's domain", m.ID)
+	}
+	if !strings.Contains(env.Data, "filename=Rahul_Sharma_Singapore_itinerary.pdf") ||
+		!strings.Contains(env.Data, base64.StdEncoding.EncodeToString([]byte("%PDF-1.4 test"))) {
+		t.Error("the attachment is missing")
+	}
+}
+
+func TestSendRefusesWithoutStartTLS(t *testing.T) {
+	srv := mailtest.NewServer(t)
+	err := Send(config.SMTP{Host: srv.Host, Port: srv.Port, Security: config.SMTPStartTLS}, testMessage())
+	if err == nil || !strings.Contains(err.Error(), "does not offer STARTTLS") {
+		t.Fatalf("err = %v, want a refusal to send in the clear", err)
+	}
+	if slices.Contains(srv.Commands(), "MAIL") || len(srv.Messages()) > 0 {
+		t.Fatalf("the message was sent in the clear: %v", srv.Commands())
+	}
+}
+
+func TestSendNotConfigured(t *testing.T) {
+	if err := Send(config.SMTP{}, testMessage()); err == nil {
+		t.Fatal("Send without a host succeeded")
+	}
+}
@@ -0,0 +1,143 @@
+// Package mailtest runs a fake SMTP server that keeps what it is sent, for
+// testing code that emails without a real mail server.
+package mailtest
+
+import (
+	"io"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Envelope is a message as the server received it.
+type Envelope struct {
+	From string
+	To   []string
+	Data string
+}
+
+// Server is a fake SMTP server on a loopback port. It accepts every message
+// and never offers STARTTLS or authentication.
+type Server struct {
+	Host string
+	Port int
+
+	listener net.Listener
+	mu       sync.Mutex
+	messages []Envelope
+	commands []string
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a Server that is closed when the test ends.
+func NewServer(t testing.TB) *Server {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().(*net.TCPAddr)
+	s := &Server{Host: addr.IP.String(), Port: addr.Port, listener: l}
+	s.wg.Add(1)
+	go s.serve()
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Close stops the server and waits for its connections to end.
+func (s *Server) Close() {
+	s.listener.Close()
+	s.wg.Wait()
+}
+
+// Messages are the messages received so far.
+func (s *Server) Messages() []Envelope {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Envelope(nil), s.messages...)
+}
+
+// Commands are the verbs of every command received so far, like "EHLO" and
+// "MAIL".
+func (s *Server) Commands() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...)
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			s.session(textproto.NewConn(conn))
+		}()
+	}
+}
+
+func (s *Server) session(c *textproto.Conn) {
+	reply := func(code int, msg string) bool {
+		return c.PrintfLine("%d %s", code, msg) == nil
+	}
+	if !reply(220, "mailtest ready") {
+		return
+	}
+	var env Envelope
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		verb = strings.ToUpper(verb)
+		s.mu.Lock()
+		s.commands = append(s.commands, verb)
+		s.mu.Unlock()
+
+		switch verb {
+		case "EHLO", "HELO":
+			c.PrintfLine("250-mailtest")
+			reply(250, "8BITMIME")
+		case "MAIL":
+			env = Envelope{From: address(arg)}
+			reply(250, "ok")
+		case "RCPT":
+			env.To = append(env.To, address(arg))
+			reply(250, "ok")
+		case "DATA":
+			reply(354, "go ahead")
+			data, err := io.ReadAll(c.DotReader())
+			if err != nil {
+				return
+			}
+			env.Data = string(data)
+			s.mu.Lock()
+			s.messages = append(s.messages, env)
+			s.mu.Unlock()
+			reply(250, "queued as "+strconv.Itoa(len(s.Messages())))
+		case "RSET", "NOOP":
+			reply(250, "ok")
+		case "QUIT":
+			reply(221, "bye")
+			return
+		default:
+			reply(502, "not implemented")
+		}
+	}
+}
+
+// address is the address in "FROM:<a@b.c>" or "TO:<a@b.c> SIZE=1".
+func address(arg string) string {
+	_, rest, _ := strings.Cut(arg, "<")
+	addr, _, _ := strings.Cut(rest, ">")
+	return addr
+}
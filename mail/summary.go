@@ -0,0 +1,114 @@
+package mail
+
+import (
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/markdown"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+)
+
+// Summary is what an email template is executed with: the trip, formatted
+// for the booking's locale, and how the PDF comes with the email.
+type Summary struct {
+	Locale *i18n.Locale
+	Theme  theme.Theme
+
+	Greeting    string
+	Customer    string
+	Destination string
+	Reference   string
+	// Status is the translated status of quotes, provisional and cancelled
+	// itineraries, empty for confirmed ones.
+	Status        string
+	DepartureFrom string
+	Dates         string
+	Duration      string
+	Travelers     string
+	Total         string
+
+	Flights []FlightLine
+	Hotels  []HotelLine
+	Days    []DayLine
+
+	// Attached is set when the PDF is attached. Otherwise URL links to it.
+	Attached bool
+	URL      string
+	// Agent signs the email when set.
+	Agent string
+}
+
+// FlightLine is a flight, with its times as given.
+type FlightLine struct {
+	Date, Airline, From, To, Departure, Arrival string
+}
+
+// HotelLine is a hotel stay.
+type HotelLine struct {
+	City, Name, CheckIn, CheckOut, Nights string
+}
+
+// DayLine is a day's date and the titles of its activities.
+type DayLine struct {
+	Title      string
+	Activities []string
+}
+
+var statusNames = map[string]string{
+	types.StatusQuote:       "Quote",
+	types.StatusProvisional: "Provisional",
+	types.StatusCancelled:   "Cancelled",
+}
+
+// NewSummary summarises data in the language of l.
+func NewSummary(data types.BookingData, l *i18n.Locale, th theme.Theme) Summary {
+	nights := utils.CalculateNights(data.DepartureDate, data.ReturnDate)
+	s := Summary{
+		Locale:        l,
+		Theme:         th,
+		Greeting:      l.T("Hi, %s!", data.CustomerName),
+		Customer:      data.CustomerName,
+		Destination:   data.Destination,
+		Reference:     data.BookingReference,
+		DepartureFrom: data.DepartureFrom,
+		Dates:         l.FormatDate(data.DepartureDate) + " – " + l.FormatDate(data.ReturnDate),
+		Duration:      l.T("%d Days %d Nights", nights+1, nights),
+		Travelers:     l.FormatNumber(float64(data.Travelers), 0),
+		Total:         l.T("Rs. %s", l.FormatNumber(data.TotalAmount, 0)),
+	}
+	if name, ok := statusNames[data.Status]; ok {
+		s.Status = l.T(name)
+	}
+
+	for _, f := range data.Flights {
+		s.Flights = append(s.Flights, FlightLine{
+			Date:      l.FormatDate(f.Date),
+			Airline:   f.Airline,
+			From:      f.From,
+			To:        f.To,
+			Departure: f.Departure,
+			Arrival:   f.Arrival,
+		})
+	}
+	for _, h := range data.Hotels {
+		nights := l.T("%d Nights", h.Nights)
+		if h.Nights == 1 {
+			nights = l.T("1 Night")
+		}
+		s.Hotels = append(s.Hotels, HotelLine{
+			City:     h.City,
+			Name:     h.Name,
+			CheckIn:  l.FormatDate(h.CheckIn),
+			CheckOut: l.FormatDate(h.CheckOut),
+			Nights:   nights,
+		})
+	}
+	for i, day := range data.Days {
+		line := DayLine{Title: l.T("Day %d - %s", i+1, l.FormatDate(day.Date))}
+		for _, a := range day.Activities {
+			line.Activities = append(line.Activities, markdown.PlainText(a.Title))
+		}
+		s.Days = append(s.Days, line)
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+)
+
+// DefaultTemplate is the built-in email body, used when neither the request's
+// client nor the server name one.
+const DefaultTemplate = "default"
+
+//go:embed templates/default.html
+var files embed.FS
+
+// funcs are available to every email template.
+var funcs = template.FuncMap{
+	// t translates a message for the booking's locale: {{t .Locale "Flights"}}.
+	"t": func(l *i18n.Locale, msg string, args ...any) string {
+		return l.T(msg, args...)
+	},
+	"hex": func(c theme.Color) template.CSS {
+		return template.CSS(c.Hex())
+	},
+}
+
+var (
+	mu        sync.RWMutex
+	templates = map[string]*template.Template{
+		DefaultTemplate: template.Must(template.New("default.html").Funcs(funcs).ParseFS(files, "templates/default.html")),
+	}
+)
+
+// GetTemplate returns the named email template. An empty name returns the
+// built-in one.
+func GetTemplate(name string) (*template.Template, error) {
+	if name == "" {
+		name = DefaultTemplate
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	t, ok := templates[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
+	}
+	return t, nil
+}
+
+// TemplateNames lists the email templates, sorted.
+func TemplateNames() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadTemplates registers every *.html file in dir as an email template
+// named after the file, "agency" for agency.html. Templates are Go
+// html/template files executed with a Summary. A missing directory is not an
+// error.
+func LoadTemplates(dir string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		base := filepath.Base(path)
+		t, err := template.New(base).Funcs(funcs).ParseFiles(path)
+		if err != nil {
+			return fmt.Errorf("email template %s: %w", base, err)
+		}
+
+		mu.Lock()
+		templates[strings.TrimSuffix(base, ".html")] = t
+		mu.Unlock()
+	}
+	return nil
+}
+
+// Body executes t with s.
+func Body(t *template.Template, s Summary) (string, error) {
+	var b bytes.Buffer
+	if err := t.Execute(&b, s); err != nil {
+		return "", fmt.Errorf("email template: %w", err)
+	}
+	return b.String(), nil
+}
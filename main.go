@@ -1,13 +1,19 @@
 package main
 
 import (
-	"net/http"
+	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/monoMonu/travel-itinerary-pdf/api"
+	"github.com/monoMonu/travel-itinerary-pdf/config"
+	"github.com/monoMonu/travel-itinerary-pdf/mail"
+	"github.com/monoMonu/travel-itinerary-pdf/render"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
 )
 
 func main() {
@@ -17,24 +23,132 @@ func main() {
 		port = "3002"
 	}
 
+	if err := theme.LoadDir(envOr("THEMES_DIR", "./themes")); err != nil {
+		log.Fatal(err)
+	}
+	if err := render.LoadTemplates(envOr("TEMPLATES_DIR", "./templates")); err != nil {
+		log.Fatal(err)
+	}
+	if err := config.LoadClients(envOr("CLIENTS_FILE", "./clients.json")); err != nil {
+		log.Fatal(err)
+	}
+	if err := mail.LoadTemplates(envOr("EMAIL_TEMPLATES_DIR", "./email-templates")); err != nil {
+		log.Fatal(err)
+	}
+	config.DefaultLinks = config.Links{
+		TermsURL:     os.Getenv("TERMS_URL"),
+		CheckoutURL:  os.Getenv("CHECKOUT_URL"),
+		ItineraryURL: os.Getenv("ITINERARY_URL"),
+	}
+	config.DefaultPayment = config.Payment{
+		UPIID:     os.Getenv("UPI_ID"),
+		PayeeName: os.Getenv("UPI_PAYEE_NAME"),
+	}
+	if days := os.Getenv("QUOTE_VALIDITY_DAYS"); days != "" {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			log.Fatalf("QUOTE_VALIDITY_DAYS: want a positive number of days, got %q", days)
+		}
+		config.DefaultQuoteValidityDays = n
+	}
+	config.DefaultProtection = config.Protection{
+		UserPassword:  os.Getenv("PDF_USER_PASSWORD"),
+		OwnerPassword: os.Getenv("PDF_OWNER_PASSWORD"),
+	}
+	if permissions, ok := os.LookupEnv("PDF_PERMISSIONS"); ok {
+		config.DefaultProtection.Permissions = strings.FieldsFunc(permissions, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	if err := config.DefaultProtection.Validate(); err != nil {
+		log.Fatalf("PDF_PERMISSIONS: %v", err)
+	}
+	config.OwnerPasswordSecret = os.Getenv("PDF_OWNER_SECRET")
+	if config.DefaultProtection.UserPassword != "" {
+		if _, err := config.DefaultProtection.Owner(""); err != nil {
+			log.Fatalf("PDF_USER_PASSWORD: %v", err)
+		}
+	}
+	config.DefaultSMTP = config.SMTP{
+		Host:            os.Getenv("SMTP_HOST"),
+		Security:        envOr("SMTP_SECURITY", config.SMTPStartTLS),
+		Username:        os.Getenv("SMTP_USERNAME"),
+		Password:        os.Getenv("SMTP_PASSWORD"),
+		AttachmentLimit: config.DefaultAttachmentLimit,
+	}
+	if err := config.DefaultSMTP.Validate(); err != nil {
+		log.Fatalf("SMTP_SECURITY: %v", err)
+	}
+	config.DefaultSMTP.Port = 587
+	if config.DefaultSMTP.Security == config.SMTPTLS {
+		config.DefaultSMTP.Port = 465
+	}
+	if port := os.Getenv("SMTP_PORT"); port != "" {
+		n, err := strconv.Atoi(port)
+		if err != nil || n <= 0 || n > 65535 {
+			log.Fatalf("SMTP_PORT: want a port number, got %q", port)
+		}
+		config.DefaultSMTP.Port = n
+	}
+	if limit := os.Getenv("EMAIL_ATTACHMENT_LIMIT"); limit != "" {
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || n < 0 {
+			log.Fatalf("EMAIL_ATTACHMENT_LIMIT: want a number of bytes, got %q", limit)
+		}
+		config.DefaultSMTP.AttachmentLimit = n
+	}
+	config.DefaultEmail = config.Email{
+		From:     os.Getenv("EMAIL_FROM"),
+		Subject:  os.Getenv("EMAIL_SUBJECT"),
+		Template: os.Getenv("EMAIL_TEMPLATE"),
+	}
+	if err := config.DefaultEmail.Validate(); err != nil {
+		log.Fatalf("EMAIL_FROM: %v", err)
+	}
+	if _, err := mail.GetTemplate(config.DefaultEmail.Template); err != nil {
+		log.Fatalf("EMAIL_TEMPLATE: %v", err)
+	}
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			log.Fatalf("SOURCE_DATE_EPOCH: %v", err)
+		}
+		render.ReproducibleDate = time.Unix(seconds, 0).UTC()
+	}
+	for _, client := range config.Clients() {
+		if _, err := theme.Get(client.Theme); err != nil {
+			log.Fatalf("client %q: %v", client.Name, err)
+		}
+		if _, err := render.GetTemplate(client.Template, 0); err != nil {
+			log.Fatalf("client %q: %v", client.Name, err)
+		}
+		if _, err := mail.GetTemplate(client.Email.Template); err != nil {
+			log.Fatalf("client %q: %v", client.Name, err)
+		}
+		if protection := client.Protection.Or(config.DefaultProtection); protection.UserPassword != "" {
+			if _, err := protection.Owner(""); err != nil {
+				log.Fatalf("client %q: %v", client.Name, err)
+			}
+		}
+	}
+
 	app := gin.Default()
 
 	app.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173", "https://vigovia-assessment.netlify.app"},
 		AllowMethods:     []string{"GET", "POST", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type"},
+		AllowHeaders:     []string{"Origin", "Content-Type", config.APIKeyHeader},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
-	app.Static("/pdfs", "./pdfs")
-
-	app.GET("/", func(reqCtx *gin.Context) {
-		reqCtx.JSON(http.StatusOK, "Hello World")
-	})
-
-	app.POST("/generate-itinerary", api.GeneratePDF)
+	api.RegisterRoutes(app)
 
 	app.Run(":" + port)
 }
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,243 @@
+// Package markdown parses the small, safe Markdown subset that activity
+// descriptions and template notes may use: **bold**, *italic*, "- " and
+// "1. " list items and [links](https://...). Anything else, HTML included,
+// is text. Each line of the source is a block of its own, except that
+// indented lines continue the list item above them.
+package markdown
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Span is a run of text in one style.
+type Span struct {
+	Text         string
+	Bold, Italic bool
+	// Link is the target of a link, already checked with SafeLink.
+	Link string
+}
+
+// Block is a paragraph line or a list item. Marker is "•" for bullets, "3."
+// for numbered items and empty for paragraphs.
+type Block struct {
+	Marker string
+	Spans  []Span
+}
+
+// Bullet is the marker of unnumbered list items.
+const Bullet = "•"
+
+var (
+	bulletRe   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	numberedRe = regexp.MustCompile(`^\s*(\d{1,3})[.)]\s+(.*)$`)
+)
+
+// Parse splits s into blocks.
+func Parse(s string) []Block {
+	s = strings.TrimRight(strings.ReplaceAll(s, "\r", ""), "\n")
+	var blocks []Block
+	var source []string
+	for _, line := range strings.Split(s, "\n") {
+		last := len(blocks) - 1
+		switch m, n := bulletRe.FindStringSubmatch(line), numberedRe.FindStringSubmatch(line); {
+		case m != nil:
+			blocks, source = append(blocks, Block{Marker: Bullet}), append(source, m[1])
+		case n != nil:
+			blocks, source = append(blocks, Block{Marker: n[1] + "."}), append(source, n[2])
+		case last >= 0 && blocks[last].Marker != "" && strings.TrimSpace(line) != "" && unicode.IsSpace(rune(line[0])):
+			source[last] += " " + strings.TrimSpace(line)
+		default:
+			blocks, source = append(blocks, Block{}), append(source, line)
+		}
+	}
+	for i := range blocks {
+		blocks[i].Spans = parseInline(source[i], Span{})
+	}
+	return blocks
+}
+
+// parseInline splits s into spans, starting from the style of outer.
+// Delimiters without a partner are kept as text.
+func parseInline(s string, outer Span) []Span {
+	var spans []Span
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			span := outer
+			span.Text = text.String()
+			spans = append(spans, span)
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\*_[]()#+-.!`", s[i+1]) >= 0:
+			text.WriteByte(s[i+1])
+			i += 2
+			continue
+
+		case c == '[':
+			if label, target, end, ok := parseLink(s, i); ok {
+				flush()
+				inner := outer
+				if SafeLink(target) {
+					inner.Link = target
+				}
+				spans = append(spans, parseInline(label, inner)...)
+				i = end
+				continue
+			}
+
+		case c == '*' || c == '_':
+			delim := string(c)
+			if strings.HasPrefix(s[i:], delim+delim) {
+				delim += delim
+			}
+			if end, ok := closeEmphasis(s, i, delim); ok {
+				flush()
+				inner := outer
+				if len(delim) == 2 {
+					inner.Bold = true
+				} else {
+					inner.Italic = true
+				}
+				spans = append(spans, parseInline(s[i+len(delim):end], inner)...)
+				i = end + len(delim)
+				continue
+			}
+			text.WriteString(delim)
+			i += len(delim)
+			continue
+		}
+		text.WriteByte(c)
+		i++
+	}
+	flush()
+	return spans
+}
+
+// closeEmphasis finds the delimiter that closes the one at s[i:]. Openers
+// must be followed and closers preceded by text, and underscores inside a
+// word, as in snake_case, are not emphasis.
+func closeEmphasis(s string, i int, delim string) (int, bool) {
+	start := i + len(delim)
+	if start >= len(s) || s[start] == ' ' {
+		return 0, false
+	}
+	if delim[0] == '_' && i > 0 && isWordByte(s[i-1]) {
+		return 0, false
+	}
+	for j := start + 1; j+len(delim) <= len(s); j++ {
+		if s[j] == '\\' {
+			j++
+			continue
+		}
+		if !strings.HasPrefix(s[j:], delim) || s[j-1] == ' ' {
+			continue
+		}
+		// A single delimiter does not close on either half of a double one.
+		if len(delim) == 1 && s[j-1] == delim[0] {
+			continue
+		}
+		if len(delim) == 1 && j+1 < len(s) && s[j+1] == delim[0] {
+			j++
+			continue
+		}
+		if delim[0] == '_' && j+len(delim) < len(s) && isWordByte(s[j+len(delim)]) {
+			continue
+		}
+		return j, true
+	}
+	return 0, false
+}
+
+func isWordByte(b byte) bool {
+	return b >= utf8.RuneSelf || b == '_' || unicode.IsLetter(rune(b)) || unicode.IsDigit(rune(b))
+}
+
+// parseLink reads [label](target) at s[i:].
+func parseLink(s string, i int) (label, target string, end int, ok bool) {
+	closeLabel := strings.Index(s[i:], "](")
+	if closeLabel < 0 {
+		return "", "", 0, false
+	}
+	closeLabel += i
+	// Parentheses inside the target must balance, as in CommonMark.
+	closeTarget, depth := -1, 0
+	for j := closeLabel + 2; j < len(s) && closeTarget < 0; j++ {
+		switch s[j] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				closeTarget = j
+			}
+			depth--
+		}
+	}
+	if closeTarget < 0 {
+		return "", "", 0, false
+	}
+	label, target = s[i+1:closeLabel], strings.TrimSpace(s[closeLabel+2:closeTarget])
+	if label == "" || target == "" || strings.ContainsAny(target, " \t") {
+		return "", "", 0, false
+	}
+	return label, target, closeTarget + 1, true
+}
+
+// SafeLink allows web and mail links only, so a booking cannot smuggle in
+// javascript: or file: actions.
+func SafeLink(target string) bool {
+	u, err := url.Parse(target)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return u.Opaque != ""
+	}
+	return false
+}
+
+// Text is a block's text without its markup or marker.
+func (b Block) Text() string {
+	var text strings.Builder
+	for _, span := range b.Spans {
+		text.WriteString(span.Text)
+	}
+	return text.String()
+}
+
+// PlainText is s without its markup, on one line, for places that only
+// have room for a single line in one style.
+func PlainText(s string) string {
+	var parts []string
+	for _, block := range Parse(s) {
+		if text := strings.TrimSpace(block.Text()); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Lines is s without its markup, a line per block, with list markers kept,
+// for plain-text formats.
+func Lines(s string) string {
+	var lines []string
+	for _, block := range Parse(s) {
+		text := strings.TrimSpace(block.Text())
+		if block.Marker != "" {
+			text = block.Marker + " " + text
+		}
+		lines = append(lines, text)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,132 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+// spans writes spans as text in their styles, like "plain|B:bold|I:it|
+// BI:both|L(https://x):label", for compact expectations.
+func spans(ss []Span) string {
+	parts := make([]string, len(ss))
+	for i, s := range ss {
+		style := ""
+		if s.Bold {
+			style += "B"
+		}
+		if s.Italic {
+			style += "I"
+		}
+		if s.Link != "" {
+			style += "L(" + s.Link + ")"
+		}
+		if style != "" {
+			style += ":"
+		}
+		parts[i] = style + s.Text
+	}
+	return strings.Join(parts, "|")
+}
+
+func TestInline(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"plain", "Visit the fort", "Visit the fort"},
+		{"bold", "a **b** c", "a |B:b| c"},
+		{"italic", "a *b* _c_", "a |I:b| |I:c"},
+		{"italic in bold", "**bold *both* bold**", "B:bold |BI:both|B: bold"},
+		{"bold in italic", "*it **both** it*", "I:it |BI:both|I: it"},
+		{"bold in a link", "[**Book** now](https://vigovia.test)", "BL(https://vigovia.test):Book|L(https://vigovia.test): now"},
+		{"unclosed bold", "**not bold", "**not bold"},
+		{"unclosed italic", "a *b", "a *b"},
+		{"closer after a space", "a * b * c", "a * b * c"},
+		{"snake_case", "use snake_case_name here", "use snake_case_name here"},
+		{"escaped", `\*not\* \[a\](b)`, "*not* [a](b)"},
+		{"html is text", "<b>x</b>", "<b>x</b>"},
+		{"balanced parentheses", "[Wiki](https://en.wikipedia.org/wiki/Fort_(disambiguation)) after",
+			"L(https://en.wikipedia.org/wiki/Fort_(disambiguation)):Wiki| after"},
+		{"unbalanced parentheses", "[x](https://a.test/(b) c", "[x](https://a.test/(b) c"},
+		{"link closed early", "([map](https://maps.test/x)).", "(|L(https://maps.test/x):map|)."},
+		{"space in target", "[x](https://a.test/ b)", "[x](https://a.test/ b)"},
+		{"javascript link", "[x](javascript:alert(1))", "x"},
+		{"file link", "[x](file:///etc/passwd)", "x"},
+		{"relative link", "[x](/admin)", "x"},
+		{"mail link", "[mail](mailto:trips@vigovia.test)", "L(mailto:trips@vigovia.test):mail"},
+	}
+	for _, tt := range tests {
+		blocks := Parse(tt.in)
+		if len(blocks) != 1 || blocks[0].Marker != "" {
+			t.Errorf("%s: got %d blocks", tt.name, len(blocks))
+			continue
+		}
+		if got := spans(blocks[0].Spans); got != tt.want {
+			t.Errorf("%s: Parse(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSafeLink(t *testing.T) {
+	tests := []struct {
+		target string
+		safe   bool
+	}{
+		{"https://vigovia.test/book", true},
+		{"HTTP://vigovia.test", true},
+		{"mailto:trips@vigovia.test", true},
+		{"javascript:alert(1)", false},
+		{"JavaScript:alert(1)", false},
+		{" javascript:alert(1)", false},
+		{"file:///etc/passwd", false},
+		{"data:text/html,<script>", false},
+		{"https:///no-host", false},
+		{"mailto:", false},
+		{"//vigovia.test", false},
+		{"vigovia.test", false},
+	}
+	for _, tt := range tests {
+		if got := SafeLink(tt.target); got != tt.safe {
+			t.Errorf("SafeLink(%q) = %v, want %v", tt.target, got, tt.safe)
+		}
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	in := "# Day plan\n" +
+		"Arrive and **rest**.\n" +
+		"- Fort\n" +
+		"  with a guide\n" +
+		"* Lake\n" +
+		"\n" +
+		"1. Breakfast\n" +
+		"12) Lunch\n" +
+		"1.5 hours by car\n" +
+		"-not a list\r\n"
+	want := []struct{ marker, text string }{
+		{"", "# Day plan"},
+		{"", "Arrive and rest."},
+		{Bullet, "Fort with a guide"},
+		{Bullet, "Lake"},
+		{"", ""},
+		{"1.", "Breakfast"},
+		{"12.", "Lunch"},
+		{"", "1.5 hours by car"},
+		{"", "-not a list"},
+	}
+	blocks := Parse(in)
+	if len(blocks) != len(want) {
+		t.Fatalf("got %d blocks, want %d: %+v", len(blocks), len(want), blocks)
+	}
+	for i, w := range want {
+		if blocks[i].Marker != w.marker || blocks[i].Text() != w.text {
+			t.Errorf("block %d: got %q %q, want %q %q", i+1, blocks[i].Marker, blocks[i].Text(), w.marker, w.text)
+		}
+	}
+
+	if got, want := Lines(in), "# Day plan\nArrive and rest.\n• Fort with a guide\n• Lake\n\n1. Breakfast\n12. Lunch\n1.5 hours by car\n-not a list"; got != want {
+		t.Errorf("Lines = %q, want %q", got, want)
+	}
+	if got, want := PlainText(in), "# Day plan Arrive and rest. Fort with a guide Lake Breakfast Lunch 1.5 hours by car -not a list"; got != want {
+		t.Errorf("PlainText = %q, want %q", got, want)
+	}
+}
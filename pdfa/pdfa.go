@@ -0,0 +1,202 @@
+// Package pdfa turns the PDFs gofpdf writes into archival files that follow
+// PDF/A-2b: XMP metadata linked from the catalog, an sRGB output intent,
+// printable annotations and a file identifier. Fonts are already embedded by
+// the renderer, which only uses its own TrueType faces.
+package pdfa
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Metadata describes the document. The renderer writes it to the PDF info
+// dictionary and, for archival files, to the XMP packet, which must agree.
+type Metadata struct {
+	Title    string
+	Author   string
+	Subject  string
+	Keywords []string
+	Creator  string
+	Producer string
+	Created  time.Time
+}
+
+// XMP returns the metadata packet declaring PDF/A-2b conformance.
+func (m Metadata) XMP() []byte {
+	esc := func(s string) string {
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(s))
+		return b.String()
+	}
+	date := m.Created.UTC().Format(time.RFC3339)
+
+	var b strings.Builder
+	b.WriteString("<?xpacket begin=\"\ufeff\" id=\"W5M0MpCehiHzreSzNTczkc9d\"?>\n")
+	b.WriteString("<x:xmpmeta xmlns:x=\"adobe:ns:meta/\">\n")
+	b.WriteString("<rdf:RDF xmlns:rdf=\"http://www.w3.org/1999/02/22-rdf-syntax-ns#\">\n")
+	b.WriteString("<rdf:Description rdf:about=\"\"" +
+		" xmlns:pdfaid=\"http://www.aiim.org/pdfa/ns/id/\"" +
+		" xmlns:dc=\"http://purl.org/dc/elements/1.1/\"" +
+		" xmlns:xmp=\"http://ns.adobe.com/xap/1.0/\"" +
+		" xmlns:pdf=\"http://ns.adobe.com/pdf/1.3/\">\n")
+	b.WriteString("<pdfaid:part>2</pdfaid:part>\n<pdfaid:conformance>B</pdfaid:conformance>\n")
+	b.WriteString("<dc:format>application/pdf</dc:format>\n")
+	fmt.Fprintf(&b, "<dc:title><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:title>\n", esc(m.Title))
+	fmt.Fprintf(&b, "<dc:creator><rdf:Seq><rdf:li>%s</rdf:li></rdf:Seq></dc:creator>\n", esc(m.Author))
+	fmt.Fprintf(&b, "<dc:description><rdf:Alt><rdf:li xml:lang=\"x-default\">%s</rdf:li></rdf:Alt></dc:description>\n", esc(m.Subject))
+	fmt.Fprintf(&b, "<pdf:Keywords>%s</pdf:Keywords>\n", esc(strings.Join(m.Keywords, ", ")))
+	fmt.Fprintf(&b, "<pdf:Producer>%s</pdf:Producer>\n", esc(m.Producer))
+	fmt.Fprintf(&b, "<xmp:CreatorTool>%s</xmp:CreatorTool>\n", esc(m.Creator))
+	fmt.Fprintf(&b, "<xmp:CreateDate>%s</xmp:CreateDate>\n<xmp:ModifyDate>%s</xmp:ModifyDate>\n", date, date)
+	b.WriteString("</rdf:Description>\n</rdf:RDF>\n</x:xmpmeta>\n")
+	b.WriteString("<?xpacket end=\"w\"?>")
+	return []byte(b.String())
+}
+
+var (
+	startxrefRe = regexp.MustCompile(`startxref\s+(\d+)\s+%%EOF\s*$`)
+	trailerRe   = regexp.MustCompile(`/(Root|Info) (\d+) 0 R`)
+	xrefRe      = regexp.MustCompile(`^xref\s+0 (\d+)\s+`)
+	infoDateRe  = regexp.MustCompile(`/(CreationDate|ModDate) \(D:(\d{14})\)`)
+)
+
+// Convert rewrites a gofpdf document as PDF/A-2b. The document must carry
+// the XMP packet from Metadata.XMP, with info dates in UTC, and must not be
+// encrypted. Objects are renumbered only by appending, so the result is as
+// deterministic as the input.
+func Convert(doc []byte) ([]byte, error) {
+	if bytes.Contains(doc, []byte("/Encrypt ")) {
+		return nil, errors.New("pdfa: encrypted documents cannot be archival")
+	}
+	m := startxrefRe.FindSubmatch(doc)
+	if m == nil {
+		return nil, errors.New("pdfa: no cross-reference table")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if xref <= 0 || xref >= len(doc) {
+		return nil, errors.New("pdfa: bad cross-reference offset")
+	}
+	refs := map[string]int{}
+	for _, r := range trailerRe.FindAllSubmatch(doc[xref:], -1) {
+		refs[string(r[1])], _ = strconv.Atoi(string(r[2]))
+	}
+	if refs["Root"] == 0 || refs["Info"] == 0 {
+		return nil, errors.New("pdfa: trailer has no catalog or info dictionary")
+	}
+
+	objects, err := splitObjects(doc, xref)
+	if err != nil {
+		return nil, err
+	}
+	metadata := 0
+	for n, obj := range objects {
+		if bytes.Contains(dictionary(obj), []byte("/Type /Metadata /Subtype /XML")) {
+			metadata = n
+		}
+	}
+	if metadata == 0 {
+		return nil, errors.New("pdfa: document has no XMP metadata")
+	}
+	profile := len(objects)
+
+	for n, obj := range objects {
+		dict := dictionary(obj)
+		rest := obj[len(dict):]
+		switch n {
+		case refs["Root"]:
+			if !bytes.Contains(dict, []byte("/Type /Catalog\n")) {
+				return nil, errors.New("pdfa: unexpected catalog")
+			}
+			dict = bytes.Replace(dict, []byte("/Type /Catalog\n"), fmt.Appendf(nil,
+				"/Type /Catalog\n/Metadata %d 0 R\n/OutputIntents [<< /Type /OutputIntent /S /GTS_PDFA1 "+
+					"/OutputConditionIdentifier (sRGB IEC61966-2.1) /Info (sRGB IEC61966-2.1) "+
+					"/DestOutputProfile %d 0 R >>]\n", metadata, profile), 1)
+		case refs["Info"]:
+			dict = infoDateRe.ReplaceAll(dict, []byte("/$1 (D:${2}Z)"))
+		default:
+			// PDF/A wants every annotation to print.
+			dict = bytes.ReplaceAll(dict, []byte("/Subtype /Link "), []byte("/Subtype /Link /F 4 "))
+		}
+		objects[n] = slices.Concat(dict, rest)
+	}
+
+	icc := sRGB()
+	objects = append(objects, fmt.Appendf(nil,
+		"%d 0 obj\n<< /N 3 /Length %d >>\nstream\n%s\nendstream\nendobj\n", profile, len(icc), icc))
+
+	header := doc[:bytes.IndexByte(doc, '\n')+1]
+	var out bytes.Buffer
+	out.Write(header)
+	out.WriteString("%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for n := 1; n < len(objects); n++ {
+		offsets[n] = out.Len()
+		out.Write(objects[n])
+	}
+
+	// The identifier is a digest of the content, so identical documents get
+	// identical identifiers.
+	id := md5.Sum(out.Bytes())
+	start := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects))
+	for n := 1; n < len(objects); n++ {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offsets[n])
+	}
+	fmt.Fprintf(&out, "trailer\n<<\n/Size %d\n/Root %d 0 R\n/Info %d 0 R\n/ID [<%x> <%x>]\n>>\nstartxref\n%d\n%%%%EOF\n",
+		len(objects), refs["Root"], refs["Info"], id, id, start)
+	return out.Bytes(), nil
+}
+
+// splitObjects returns the indirect objects indexed by number, with index 0
+// unused, cutting the body at the offsets the cross-reference table gives.
+func splitObjects(doc []byte, xref int) ([][]byte, error) {
+	m := xrefRe.FindSubmatch(doc[xref:])
+	if m == nil {
+		return nil, errors.New("pdfa: unsupported cross-reference table")
+	}
+	count, _ := strconv.Atoi(string(m[1]))
+	entries := doc[xref+len(m[0]):]
+	if count < 2 || len(entries) < 20*count {
+		return nil, errors.New("pdfa: short cross-reference table")
+	}
+
+	offsets := make([]int, count)
+	for n := 1; n < count; n++ {
+		entry := entries[20*n : 20*n+20]
+		offset, err := strconv.Atoi(string(entry[:10]))
+		if err != nil || entry[17] != 'n' || offset <= 0 || offset >= xref {
+			return nil, fmt.Errorf("pdfa: bad cross-reference entry for object %d", n)
+		}
+		offsets[n] = offset
+	}
+	ends := slices.Sorted(slices.Values(offsets[1:]))
+	ends = append(ends, xref)
+
+	objects := make([][]byte, count)
+	for n := 1; n < count; n++ {
+		i, _ := slices.BinarySearch(ends, offsets[n])
+		obj := doc[offsets[n]:ends[i+1]]
+		if !bytes.HasPrefix(obj, fmt.Appendf(nil, "%d 0 obj", n)) {
+			return nil, fmt.Errorf("pdfa: object %d is not where the table says", n)
+		}
+		objects[n] = bytes.Clone(obj)
+	}
+	return objects, nil
+}
+
+// dictionary returns the part of an object before its stream data, which is
+// the only part Convert edits.
+func dictionary(obj []byte) []byte {
+	if i := bytes.Index(obj, []byte("\nstream\n")); i >= 0 {
+		return obj[:i]
+	}
+	return obj
+}
@@ -0,0 +1,170 @@
+package pdfa
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+var created = time.Date(2025, time.June, 15, 9, 30, 0, 0, time.UTC)
+
+// document is a small gofpdf file of the kind the renderer writes: two pages
+// with a link, and the XMP packet when archival.
+func document(t *testing.T, archival, encrypted bool) []byte {
+	t.Helper()
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetCatalogSort(true)
+	pdf.SetCreationDate(created)
+	pdf.SetModificationDate(created)
+	pdf.SetTitle("Singapore Itinerary - Rahul Sharma", true)
+	if archival {
+		pdf.SetXmpMetadata(Metadata{Title: "Singapore Itinerary - Rahul Sharma", Author: "Vigovia", Created: created}.XMP())
+	}
+	if encrypted {
+		pdf.SetProtection(gofpdf.CnProtectPrint, "user", "owner")
+	}
+	pdf.SetFont("Helvetica", "", 12)
+	for _, line := range []string{"Day 1: Arrival", "Day 2: Sentosa Island"} {
+		pdf.AddPage()
+		pdf.CellFormat(0, 10, line, "", 1, "", false, 0, "https://vigovia.test/day")
+	}
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+var (
+	headerRe  = regexp.MustCompile(`^%PDF-1\.\d\n`)
+	idRe      = regexp.MustCompile(`/ID \[<([0-9a-f]{32})> <([0-9a-f]{32})>\]`)
+	refRe     = regexp.MustCompile(`/(Metadata|DestOutputProfile) (\d+) 0 R`)
+	lengthRe  = regexp.MustCompile(`/Length (\d+)`)
+	linkRe    = regexp.MustCompile(`/Subtype /Link [^>]*`)
+	infoRe    = regexp.MustCompile(`/(CreationDate|ModDate) \(D:(\d{14}Z?)\)`)
+	oneStream = regexp.MustCompile(`(?s)\nstream\n(.*)\nendstream\n`)
+)
+
+// parse reads doc the way a PDF reader finds its objects: from startxref to
+// the cross-reference table, through it to each object, and from the
+// trailer to the catalog and info dictionary.
+func parse(t *testing.T, doc []byte) (objects [][]byte, trailer []byte) {
+	t.Helper()
+	if !headerRe.Match(doc) {
+		t.Fatalf("no PDF header: %q", doc[:min(len(doc), 16)])
+	}
+	m := startxrefRe.FindSubmatch(doc)
+	if m == nil {
+		t.Fatal("no startxref at the end")
+	}
+	xref, _ := strconv.Atoi(string(m[1]))
+	if !bytes.HasPrefix(doc[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the cross-reference table", xref)
+	}
+	objects, err := splitObjects(doc, xref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n, obj := range objects[1:] {
+		if !bytes.HasSuffix(obj, []byte("endobj\n")) {
+			t.Errorf("object %d runs into the next: %q", n+1, obj[max(0, len(obj)-32):])
+		}
+	}
+	trailer = doc[xref:]
+	if !bytes.Contains(trailer, []byte("trailer\n")) {
+		t.Fatal("no trailer")
+	}
+	if size := regexp.MustCompile(`/Size (\d+)`).FindSubmatch(trailer); size == nil || string(size[1]) != strconv.Itoa(len(objects)) {
+		t.Errorf("trailer size %q, want %d", size, len(objects))
+	}
+	return objects, trailer
+}
+
+func TestConvert(t *testing.T) {
+	in := document(t, true, false)
+	out, err := Convert(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects, trailer := parse(t, out)
+	if inObjects, _ := parse(t, in); len(objects) != len(inObjects)+1 {
+		t.Errorf("got %d objects from %d, want only the ICC profile added", len(objects)-1, len(inObjects)-1)
+	}
+
+	refs := map[string]int{}
+	for _, r := range trailerRe.FindAllSubmatch(trailer, -1) {
+		refs[string(r[1])], _ = strconv.Atoi(string(r[2]))
+	}
+	catalog, info := objects[refs["Root"]], objects[refs["Info"]]
+	if !bytes.Contains(catalog, []byte("/Type /Catalog")) || !bytes.Contains(catalog, []byte("/OutputIntents [<< /Type /OutputIntent /S /GTS_PDFA1 ")) {
+		t.Fatalf("catalog without an output intent:\n%s", catalog)
+	}
+	for _, r := range refRe.FindAllSubmatch(catalog, -1) {
+		n, _ := strconv.Atoi(string(r[2]))
+		if n <= 0 || n >= len(objects) {
+			t.Fatalf("catalog /%s refers to missing object %d", r[1], n)
+		}
+		obj := objects[n]
+		length, stream := lengthRe.FindSubmatch(obj), oneStream.FindSubmatch(obj)
+		if length == nil || stream == nil || string(length[1]) != strconv.Itoa(len(stream[1])) {
+			t.Errorf("/%s stream length %q does not match its data", r[1], length)
+		}
+		if string(r[1]) == "Metadata" && !bytes.Contains(obj, []byte("<pdfaid:part>2</pdfaid:part>")) {
+			t.Errorf("/Metadata is not the XMP packet:\n%s", obj)
+		}
+	}
+	if len(refRe.FindAll(catalog, -1)) != 2 {
+		t.Errorf("catalog needs /Metadata and /DestOutputProfile:\n%s", catalog)
+	}
+
+	dates := infoRe.FindAllSubmatch(info, -1)
+	if len(dates) != 2 {
+		t.Fatalf("info dictionary dates: %q", dates)
+	}
+	for _, d := range dates {
+		if string(d[2]) != "20250615093000Z" {
+			t.Errorf("/%s is %s, want it in UTC", d[1], d[2])
+		}
+	}
+	links := linkRe.FindAll(out, -1)
+	if len(links) != 2 {
+		t.Fatalf("got %d links, want 2", len(links))
+	}
+	for _, link := range links {
+		if !bytes.Contains(link, []byte(" /F 4 ")) {
+			t.Errorf("link does not print: %s", link)
+		}
+	}
+	if id := idRe.FindSubmatch(trailer); id == nil || !bytes.Equal(id[1], id[2]) {
+		t.Errorf("trailer identifier %q", id)
+	}
+
+	again, err := Convert(in)
+	if err != nil || !bytes.Equal(again, out) {
+		t.Error("converting the same document twice gave different files")
+	}
+}
+
+func TestConvertRefuses(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  []byte
+		err  string
+	}{
+		{"no metadata", document(t, false, false), "pdfa: document has no XMP metadata"},
+		{"encrypted", document(t, true, true), "pdfa: encrypted documents cannot be archival"},
+		{"truncated", document(t, true, false)[:2000], "pdfa: no cross-reference table"},
+		{"not a PDF", []byte("hello"), "pdfa: no cross-reference table"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Convert(tt.doc); err == nil || err.Error() != tt.err {
+				t.Errorf("got %v, want %q", err, tt.err)
+			}
+		})
+	}
+}
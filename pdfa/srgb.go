@@ -0,0 +1,106 @@
+package pdfa
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+// sRGB returns an ICC v2 display profile for sRGB IEC61966-2.1, built from
+// the published primaries and transfer curve rather than shipped as a file.
+var sRGB = sync.OnceValue(func() []byte {
+	type tag struct {
+		sig  string
+		data []byte
+	}
+	curve := curveTag()
+	tags := []tag{
+		{"desc", descTag("sRGB IEC61966-2.1")},
+		{"cprt", textTag("No copyright, use freely")},
+		{"wtpt", xyzTag(0.9642, 1.0, 0.8249)},
+		// Primaries adapted to the D50 connection space.
+		{"rXYZ", xyzTag(0.4361, 0.2225, 0.0139)},
+		{"gXYZ", xyzTag(0.3851, 0.7169, 0.0971)},
+		{"bXYZ", xyzTag(0.1431, 0.0606, 0.7141)},
+		{"rTRC", curve},
+		{"gTRC", curve},
+		{"bTRC", curve},
+	}
+
+	const headerSize = 128
+	offset := headerSize + 4 + 12*len(tags)
+	var table, data bytes.Buffer
+	binary.Write(&table, binary.BigEndian, uint32(len(tags)))
+	for _, t := range tags {
+		table.WriteString(t.sig)
+		binary.Write(&table, binary.BigEndian, uint32(offset+data.Len()))
+		binary.Write(&table, binary.BigEndian, uint32(len(t.data)))
+		data.Write(t.data)
+		for data.Len()%4 != 0 {
+			data.WriteByte(0)
+		}
+	}
+
+	var header bytes.Buffer
+	binary.Write(&header, binary.BigEndian, uint32(offset+data.Len()))
+	header.Write(make([]byte, 4))                                  // preferred CMM
+	binary.Write(&header, binary.BigEndian, uint32(0x02100000))    // version 2.1
+	header.WriteString("mntrRGB XYZ ")                             // class, colour space, PCS
+	binary.Write(&header, binary.BigEndian, [6]uint16{2025, 1, 1}) // creation date
+	header.WriteString("acsp")
+	header.Write(make([]byte, 24))                     // platform, flags, device, attributes
+	binary.Write(&header, binary.BigEndian, uint32(0)) // perceptual intent
+	header.Write(xyzTag(0.9642, 1.0, 0.8249)[8:])      // D50 illuminant
+	header.Write(make([]byte, headerSize-header.Len()))
+
+	return bytes.Join([][]byte{header.Bytes(), table.Bytes(), data.Bytes()}, nil)
+})
+
+func s15Fixed16(v float64) int32 {
+	return int32(math.Round(v * 65536))
+}
+
+func xyzTag(x, y, z float64) []byte {
+	var b bytes.Buffer
+	b.WriteString("XYZ ")
+	b.Write(make([]byte, 4))
+	binary.Write(&b, binary.BigEndian, [3]int32{s15Fixed16(x), s15Fixed16(y), s15Fixed16(z)})
+	return b.Bytes()
+}
+
+func textTag(s string) []byte {
+	return append(append([]byte("text\x00\x00\x00\x00"), s...), 0)
+}
+
+// descTag is the v2 textDescriptionType: an ASCII description followed by
+// empty Unicode and ScriptCode variants.
+func descTag(s string) []byte {
+	var b bytes.Buffer
+	b.WriteString("desc")
+	b.Write(make([]byte, 4))
+	binary.Write(&b, binary.BigEndian, uint32(len(s)+1))
+	b.WriteString(s)
+	b.WriteByte(0)
+	b.Write(make([]byte, 4+4+2+1+67))
+	return b.Bytes()
+}
+
+// curveTag samples the sRGB transfer function.
+func curveTag() []byte {
+	const n = 1024
+	var b bytes.Buffer
+	b.WriteString("curv")
+	b.Write(make([]byte, 4))
+	binary.Write(&b, binary.BigEndian, uint32(n))
+	for i := range n {
+		v := float64(i) / (n - 1)
+		if v <= 0.04045 {
+			v /= 12.92
+		} else {
+			v = math.Pow((v+0.055)/1.055, 2.4)
+		}
+		binary.Write(&b, binary.BigEndian, uint16(math.Round(v*65535)))
+	}
+	return b.Bytes()
+}
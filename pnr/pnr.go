@@ -0,0 +1,360 @@
+// Package pnr reads flights from the itinerary text agents paste: GDS
+// segment displays like
+//
+//	1  AI 380 Y 15JUN 7 DELSIN HK2  0130  0940  O*E
+//
+// and the flight lines of e-tickets, like
+//
+//	AI 380  15 Jun 2025  New Delhi (DEL) 01:30  Singapore (SIN) 09:40
+//
+// A line is read as a flight when it has a flight number, or a date and a
+// pair of airports. Lines that look like flights but could be read more than one
+// way, or miss something a flight needs, are reported instead of guessed.
+package pnr
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/monoMonu/travel-itinerary-pdf/geo"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+)
+
+var (
+	// flightNumber is a designator written as one token, "AI380", or
+	// "AI380Y" with the booking class. Written as two, "AI 380", it is an
+	// airlineCode followed by a number. The class is not kept, since a
+	// flight has nowhere to put it.
+	flightNumber = regexp.MustCompile(`^([A-Z]{2}|[A-Z][0-9]|[0-9][A-Z])([0-9]{1,4})[A-Z]?$`)
+	airlineCode  = regexp.MustCompile(`^([A-Z]{2}|[A-Z][0-9]|[0-9][A-Z])$`)
+	number       = regexp.MustCompile(`^([0-9]{1,4})[A-Z]?$`)
+	// gdsDate is "15JUN", "15JUN25" or "15JUN2025".
+	gdsDate = regexp.MustCompile(`^([0-9]{1,2})([A-Za-z]{3})([0-9]{2}|[0-9]{4})?$`)
+	// dashedDate is "15-Jun-2025" or "15-Jun".
+	dashedDate = regexp.MustCompile(`^([0-9]{1,2})-([A-Za-z]{3,9})(?:-([0-9]{2}|[0-9]{4}))?$`)
+	// slashedDate is "15/06/2025", which is a different day in different
+	// countries.
+	slashedDate = regexp.MustCompile(`^[0-9]{1,2}[/.][0-9]{1,2}[/.][0-9]{2,4}$`)
+	// cityPair is "DELSIN", after an optional day of the week and marker,
+	// and before the status Sabre joins to it, "DELSIN*HK2".
+	cityPair = regexp.MustCompile(`^[0-9]?\*?([A-Z]{3})([A-Z]{3})(?:\*[A-Z]{2}[0-9]{0,2})?$`)
+	code     = regexp.MustCompile(`^[A-Z]{3}$`)
+	// codeInName is the code of "New Delhi (DEL)".
+	codeInName = regexp.MustCompile(`\(([A-Z]{3})\)`)
+	// gdsTime is "0130", "130A" or "1030P", with "#" before or "+1" after
+	// for an arrival on a later day.
+	gdsTime = regexp.MustCompile(`^(#)?([0-9]{3,4})([AP])?(?:([+-][0-9]))?$`)
+	// clockTime is "01:30" or "1:30PM".
+	clockTime = regexp.MustCompile(`^(#)?([0-9]{1,2}:[0-9]{2})((?i:[AP]M))?(?:([+-][0-9]))?$`)
+	// status is a segment status with its seat count, "HK2".
+	status = regexp.MustCompile(`^[A-Z]{2}[0-9]{1,2}$`)
+
+	segmentNumber = regexp.MustCompile(`^[0-9]{1,2}\.?$`)
+	dayOfMonth    = regexp.MustCompile(`^[0-9]{1,2}$`)
+	fullYear      = regexp.MustCompile(`^[0-9]{4}$`)
+	isoDate       = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+	meridiem      = regexp.MustCompile(`^(?i:[AP]M)$`)
+)
+
+var months = map[string]time.Month{
+	"JAN": time.January, "FEB": time.February, "MAR": time.March, "APR": time.April,
+	"MAY": time.May, "JUN": time.June, "JUL": time.July, "AUG": time.August,
+	"SEP": time.September, "OCT": time.October, "NOV": time.November, "DEC": time.December,
+}
+
+// notAirports are three-letter words in GDS lines that are not airport
+// codes. Lines naming their airports, like "NEW DELHI (DEL)", take only the
+// codes in brackets, so words like NEW are never mistaken for one.
+var notAirports = map[string]bool{
+	"MON": true, "TUE": true, "WED": true, "THU": true, "FRI": true, "SAT": true, "SUN": true,
+	"DEP": true, "ARR": true, "PNR": true, "THE": true, "AND": true, "FOR": true, "VIA": true,
+}
+
+func month(s string) (time.Month, bool) {
+	if len(s) < 3 {
+		return 0, false
+	}
+	m, ok := months[strings.ToUpper(s[:3])]
+	if !ok {
+		return 0, false
+	}
+	// Full names must be spelt out right: "June", not "Junk".
+	if len(s) > 3 && !strings.HasPrefix(strings.ToUpper(m.String()), strings.ToUpper(s)) {
+		return 0, false
+	}
+	return m, true
+}
+
+// date is a date as written, with the year left 0 when the text has none.
+type date struct {
+	year  int
+	month time.Month
+	day   int
+}
+
+// clock is a time of day as written, with the days it is after the
+// flight's date.
+type clock struct {
+	at   time.Duration
+	days int
+}
+
+// segment is what one line says.
+type segment struct {
+	flights  []string
+	dates    []date
+	arrival  []date
+	airports []string
+	times    []clock
+	problems []string
+}
+
+// Parse reads the flights in text. Dates without a year, as GDS displays
+// write them, are taken to be the first such date on or after reference.
+func Parse(text string, reference time.Time) types.FlightTextResponse {
+	resp := types.FlightTextResponse{Flights: []types.Flight{}, Problems: []types.FlightTextProblem{}}
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		// Lines without a flight number are only taken for flights when
+		// they have a date and airports as well, so that names like
+		// SHARMA, which look like city pairs, are passed over.
+		s := scan(line)
+		if len(s.flights) == 0 && (len(s.airports) < 2 || len(s.dates) == 0) {
+			continue
+		}
+		f, err := s.flight(reference)
+		if err != "" {
+			resp.Problems = append(resp.Problems, types.FlightTextProblem{Line: i + 1, Text: line, Error: err})
+			continue
+		}
+		resp.Flights = append(resp.Flights, f)
+	}
+	return resp
+}
+
+// scan sorts the words of a line into what they could be.
+func scan(line string) segment {
+	var s segment
+	words := strings.Fields(strings.NewReplacer("→", " ", "–", " ", ",", " ").Replace(line))
+	// A GDS line starts with its segment number.
+	if len(words) > 0 && segmentNumber.MatchString(words[0]) {
+		words = words[1:]
+	}
+
+	// Airports written like "NEW DELHI (DEL)" are found by their brackets
+	// alone; the other words of their names may look like codes.
+	named := codeInName.MatchString(line)
+
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		next := func(n int) string {
+			if i+n < len(words) {
+				return words[i+n]
+			}
+			return ""
+		}
+		switch {
+		case status.MatchString(w) && len(s.airports) > 0:
+			// The segment's status after the city pair, like HK2, says
+			// nothing about the flight.
+		case flightNumber.MatchString(w) && !cityPair.MatchString(w):
+			m := flightNumber.FindStringSubmatch(w)
+			s.flights = append(s.flights, m[1]+" "+m[2])
+		case airlineCode.MatchString(w) && number.MatchString(next(1)):
+			m := number.FindStringSubmatch(next(1))
+			s.flights = append(s.flights, w+" "+m[1])
+			i++
+		case gdsDate.MatchString(w):
+			m := gdsDate.FindStringSubmatch(w)
+			s.addDate(m[1], m[2], m[3])
+		case dashedDate.MatchString(w):
+			m := dashedDate.FindStringSubmatch(w)
+			s.addDate(m[1], m[2], m[3])
+		case dayOfMonth.MatchString(w) && isMonth(next(1)):
+			// "15 Jun 2025", the year optional.
+			day, name, year := w, next(1), ""
+			if fullYear.MatchString(next(2)) {
+				year = next(2)
+				i++
+			}
+			s.addDate(day, name, year)
+			i++
+		case isMonth(w) && dayOfMonth.MatchString(next(1)):
+			// "Jun 15 2025".
+			day, name, year := next(1), w, ""
+			if fullYear.MatchString(next(2)) {
+				year = next(2)
+				i++
+			}
+			s.addDate(day, name, year)
+			i++
+		case isoDate.MatchString(w):
+			if t, err := time.Parse(time.DateOnly, w); err == nil {
+				s.dates = append(s.dates, date{t.Year(), t.Month(), t.Day()})
+			} else {
+				s.problems = append(s.problems, fmt.Sprintf("%q is not a date", w))
+			}
+		case slashedDate.MatchString(w):
+			s.problems = append(s.problems, fmt.Sprintf("%q could be day/month or month/day; write it like 15JUN or 2025-06-15", w))
+		case cityPair.MatchString(w) && !named:
+			m := cityPair.FindStringSubmatch(w)
+			s.airports = append(s.airports, m[1], m[2])
+		case codeInName.MatchString(w):
+			s.airports = append(s.airports, codeInName.FindStringSubmatch(w)[1])
+		case code.MatchString(w) && !named && !notAirports[w] && !isMonth(w):
+			s.airports = append(s.airports, w)
+		case gdsTime.MatchString(w) && len(s.flights) > 0:
+			m := gdsTime.FindStringSubmatch(w)
+			digits := m[2]
+			if len(digits) == 3 {
+				digits = "0" + digits
+			}
+			s.addTime(digits[:2]+":"+digits[2:], m[3], m[1], m[4])
+		case clockTime.MatchString(w):
+			m := clockTime.FindStringSubmatch(w)
+			half := m[3]
+			if half == "" && meridiem.MatchString(next(1)) {
+				half = next(1)
+				i++
+			}
+			s.addTime(m[2], half, m[1], m[4])
+		}
+	}
+	return s
+}
+
+func isMonth(s string) bool {
+	_, ok := month(s)
+	return ok
+}
+
+// addDate records a date, as the flight's or, once the times have been
+// read, as the arrival's.
+func (s *segment) addDate(day, monthName, year string) {
+	m, ok := month(monthName)
+	d, _ := strconv.Atoi(day)
+	if !ok || d < 1 || d > 31 {
+		return
+	}
+	y := 0
+	if year != "" {
+		y, _ = strconv.Atoi(year)
+		if y < 100 {
+			y += 2000
+		}
+	}
+	if len(s.times) > 0 {
+		s.arrival = append(s.arrival, date{y, m, d})
+		return
+	}
+	s.dates = append(s.dates, date{y, m, d})
+}
+
+func (s *segment) addTime(hhmm, half, hash, offset string) {
+	text := hhmm
+	if half != "" {
+		text += " " + strings.TrimSuffix(strings.ToUpper(half), "M") + "M"
+	}
+	at, ok := utils.ParseClock(text)
+	if !ok {
+		s.problems = append(s.problems, fmt.Sprintf("%q is not a time", text))
+		return
+	}
+	c := clock{at: at}
+	if hash != "" {
+		c.days = 1
+	}
+	if offset != "" {
+		c.days, _ = strconv.Atoi(offset)
+	}
+	s.times = append(s.times, c)
+}
+
+// flight checks that the line says exactly one thing of each kind and
+// returns the flight, or what is wrong.
+func (s segment) flight(reference time.Time) (types.Flight, string) {
+	switch {
+	case len(s.problems) > 0:
+		return types.Flight{}, strings.Join(s.problems, "; ")
+	case len(s.flights) == 0:
+		return types.Flight{}, "no flight number"
+	case len(s.flights) > 1:
+		return types.Flight{}, "more than one flight number: " + strings.Join(s.flights, ", ")
+	case len(s.dates) == 0:
+		return types.Flight{}, "no date"
+	case len(s.dates) > 1:
+		return types.Flight{}, "more than one date before the times"
+	case len(s.airports) < 2:
+		return types.Flight{}, "no pair of airports, like DELSIN or DEL SIN"
+	case len(s.airports) > 2:
+		return types.Flight{}, "more than two airports: " + strings.Join(s.airports, ", ")
+	case len(s.times) < 2:
+		return types.Flight{}, "no departure and arrival times"
+	case len(s.times) > 2:
+		return types.Flight{}, "more than two times"
+	case len(s.arrival) > 1:
+		return types.Flight{}, "more than one arrival date"
+	}
+
+	d := s.dates[0]
+	year := d.year
+	if year == 0 {
+		year = reference.Year()
+		if time.Date(year, d.month, d.day, 0, 0, 0, 0, time.UTC).Before(reference.Truncate(24 * time.Hour)) {
+			year++
+		}
+	}
+	departed := time.Date(year, d.month, d.day, 0, 0, 0, 0, time.UTC)
+	if departed.Day() != d.day {
+		return types.Flight{}, fmt.Sprintf("%d %s is not a date", d.day, d.month)
+	}
+
+	dep, arr := s.times[0], s.times[1]
+	days := arr.days
+	if len(s.arrival) == 1 {
+		a := s.arrival[0]
+		arrived := time.Date(year, a.month, a.day, 0, 0, 0, 0, time.UTC)
+		if arrived.Before(departed) {
+			arrived = arrived.AddDate(1, 0, 0)
+		}
+		days = int(arrived.Sub(departed).Hours() / 24)
+	}
+	// A flight records only clock times, and an arrival earlier on the
+	// clock than the departure is taken to be on the next day or the one
+	// after; other day changes cannot be recorded.
+	switch {
+	case days == 0 && arr.at < dep.at:
+		return types.Flight{}, "arrives before it departs; mark a next-day arrival with +1"
+	case days < 0 || days > 2 || (days > 0 && arr.at >= dep.at):
+		return types.Flight{}, fmt.Sprintf("arrives %d days after it departs at those times, which a flight cannot record", days)
+	}
+
+	return types.Flight{
+		Date:      departed.Format(time.DateOnly),
+		Airline:   s.flights[0],
+		From:      place(s.airports[0]),
+		To:        place(s.airports[1]),
+		Departure: hhmm(dep.at),
+		Arrival:   hhmm(arr.at),
+	}, ""
+}
+
+// place names an airport like "Singapore (SIN)", which geo.FindAirport
+// reads back, or by its code alone when it is not a known one.
+func place(iata string) string {
+	if a, ok := geo.FindAirport(iata); ok {
+		return fmt.Sprintf("%s (%s)", a.City, a.Code)
+	}
+	return iata
+}
+
+func hhmm(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+}
@@ -0,0 +1,137 @@
+package pnr
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+var reference = time.Date(2025, time.June, 10, 0, 0, 0, 0, time.UTC)
+
+func TestParseFlights(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want types.Flight
+	}{
+		{
+			name: "amadeus",
+			line: "  1  AI 380 Y 15JUN 7 DELSIN HK2  0130  0940  O*E",
+			want: types.Flight{Date: "2025-06-15", Airline: "AI 380", From: "Delhi (DEL)", To: "Singapore (SIN)", Departure: "01:30", Arrival: "09:40"},
+		},
+		{
+			name: "amadeus with arrival date",
+			line: "  2  QF   1 Y 05JAN 1 SYDLHR HK1  1600 0525  06JAN  E  QF/ABC123",
+			want: types.Flight{Date: "2026-01-05", Airline: "QF 1", From: "Sydney (SYD)", To: "London (LHR)", Departure: "16:00", Arrival: "05:25"},
+		},
+		{
+			name: "sabre",
+			line: " 2 SQ 422Y 20JUN 5 SINBOM*HK2   810P 1105P /DCSQ*ABC123 /E",
+			want: types.Flight{Date: "2025-06-20", Airline: "SQ 422", From: "Singapore (SIN)", To: "Mumbai (BOM)", Departure: "20:10", Arrival: "23:05"},
+		},
+		{
+			name: "galileo",
+			line: " 3. 6E 2345 Q  22JUN DELGOI HK1  2355  #0215  O        SU",
+			want: types.Flight{Date: "2025-06-22", Airline: "6E 2345", From: "Delhi (DEL)", To: "Goa (GOI)", Departure: "23:55", Arrival: "02:15"},
+		},
+		{
+			name: "worldspan",
+			line: "4 EK 501Y 23JUN BOMDXB HK2 400A 550A",
+			want: types.Flight{Date: "2025-06-23", Airline: "EK 501", From: "Mumbai (BOM)", To: "Dubai (DXB)", Departure: "04:00", Arrival: "05:50"},
+		},
+		{
+			name: "e-ticket",
+			line: "AI 380  15 Jun 2025  New Delhi (DEL) 01:30  Singapore (SIN) 09:40",
+			want: types.Flight{Date: "2025-06-15", Airline: "AI 380", From: "Delhi (DEL)", To: "Singapore (SIN)", Departure: "01:30", Arrival: "09:40"},
+		},
+		{
+			name: "all-caps e-ticket",
+			line: "AI 380 15 JUN 2025 NEW DELHI (DEL) 01:30 SINGAPORE (SIN) 09:40",
+			want: types.Flight{Date: "2025-06-15", Airline: "AI 380", From: "Delhi (DEL)", To: "Singapore (SIN)", Departure: "01:30", Arrival: "09:40"},
+		},
+		{
+			name: "all-caps e-ticket with three-letter words",
+			line: "UA 863 20JUN25 SAN FRANCISCO (SFO) 11:25 SYDNEY (SYD) 08:40+2",
+			want: types.Flight{Date: "2025-06-20", Airline: "UA 863", From: "San Francisco (SFO)", To: "Sydney (SYD)", Departure: "11:25", Arrival: "08:40"},
+		},
+		{
+			name: "all-caps e-ticket with six-letter city",
+			line: "BA 283 10-JUL-2025 LONDON HEATHROW (LHR) 3:35 PM LOS ANGELES (LAX) 6:55 PM",
+			want: types.Flight{Date: "2025-07-10", Airline: "BA 283", From: "London (LHR)", To: "Los Angeles (LAX)", Departure: "15:35", Arrival: "18:55"},
+		},
+		{
+			name: "flight label and ISO date",
+			line: "Flight: LH 761  Date: 2025-07-01  Frankfurt (FRA) 13:20  New Delhi (DEL) 00:55+1",
+			want: types.Flight{Date: "2025-07-01", Airline: "LH 761", From: "Frankfurt (FRA)", To: "Delhi (DEL)", Departure: "13:20", Arrival: "00:55"},
+		},
+		{
+			name: "unknown airports keep their codes",
+			line: "1 XY 12 Y 01JUL ZZAQQB HK1 0800 0900",
+			want: types.Flight{Date: "2025-07-01", Airline: "XY 12", From: "ZZA", To: "QQB", Departure: "08:00", Arrival: "09:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := Parse(tt.line, reference)
+			if len(resp.Problems) > 0 {
+				t.Fatalf("problems: %+v", resp.Problems)
+			}
+			if len(resp.Flights) != 1 {
+				t.Fatalf("got %d flights, want 1", len(resp.Flights))
+			}
+			if got := resp.Flights[0]; got != tt.want {
+				t.Fatalf("got  %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		err  string
+	}{
+		{"slashed date", "UK 955 05/06/2025 DEL BOM 0700 0915", "could be day/month or month/day"},
+		{"no arrival time", "AI 101 16JUN JFKDEL HK1 1100", "no departure and arrival times"},
+		{"no next-day mark", "BA 142 17JUN DELLHR 1300 1200", "arrives before it departs"},
+		{"three airports", "QR 570 18JUN DEL DOH ZRH 0400 0600", "more than two airports"},
+		{"one named airport", "AI 380 15 JUN 2025 DEL 01:30 SINGAPORE (SIN) 09:40", "no pair of airports"},
+		{"two flight numbers", "AI 380 / SQ 422 15JUN DELSIN 0130 0940", "more than one flight number"},
+		{"no date", "AI 380 DELSIN 0130 0940", "no date"},
+		{"no flight number", "15JUN DEL SIN 0130 0940", "no flight number"},
+		{"not a date", "9W 12 31FEB DELBOM 0700 0900", "31 February is not a date"},
+		{"too many days", "UK 1 01JUN DELBOM 0700 0900 05JUN", "arrives 4 days after"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := Parse("header\n"+tt.line, reference)
+			if len(resp.Flights) != 0 {
+				t.Fatalf("got flights %+v, want a problem", resp.Flights)
+			}
+			if len(resp.Problems) != 1 {
+				t.Fatalf("got %d problems, want 1", len(resp.Problems))
+			}
+			p := resp.Problems[0]
+			if p.Line != 2 || p.Text != tt.line || !strings.Contains(p.Error, tt.err) {
+				t.Fatalf("got %+v, want line 2 with %q", p, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseIgnoresOtherLines(t *testing.T) {
+	text := strings.Join([]string{
+		"RP/DELXX2100/DELXX2100            AA/SU  10JUN25/0912Z   ABC123",
+		"  1.SHARMA/RAHUL MR   2.SHARMA/PRIYA MRS",
+		"PASSENGER: MR RAHUL SHARMA   TICKET 0987654321",
+		"  3  AI 380 Y 15JUN 7 DELSIN HK2  0130  0940",
+		"BAGGAGE 2PC   SEAT 23A",
+	}, "\n")
+	resp := Parse(text, reference)
+	if len(resp.Problems) != 0 || len(resp.Flights) != 1 {
+		t.Fatalf("got %+v", resp)
+	}
+}
@@ -0,0 +1,156 @@
+package render
+
+// activityTable lists every activity of the trip. The activity may use
+// Markdown; the other columns are plain.
+func activityTable(ctx *Context) *TableContent {
+	data := ctx.Data
+	t := &TableContent{
+		Headers: []string{ctx.T("City"), ctx.T("Activity"), ctx.T("Type"), ctx.T("Time Required")},
+		Weights: []float64{35, 80, 35, 30},
+	}
+	for _, day := range data.Days {
+		for _, activity := range day.Activities {
+			city := activity.City
+			if city == "" {
+				city = data.Destination
+			}
+			t.Rows = append(t.Rows, []Cell{
+				{Text: city},
+				{Text: activity.Title, Markdown: true},
+				{Text: activity.Type},
+				{Text: ctx.Locale.FormatDuration(activity.Duration, activity.Time)},
+			})
+		}
+	}
+	return t
+}
+
+func addActivityTable(ctx *Context) error {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	t := activityTable(ctx)
+
+	ctx.NewPage()
+	ctx.Heading("Activity Table", 15)
+
+	headers := t.Headers
+	widths := ctx.Columns(t.Weights...)
+
+	ctx.Fill(p.Accent)
+	ctx.TextColor(p.OnPrimary)
+	ctx.Font("B", 10)
+
+	x := ctx.Left()
+	for i, header := range headers {
+		pdf.SetXY(x, pdf.GetY())
+		ctx.CellFormat(widths[i], 8, header, "1", 0, "C", true, 0, "")
+		x += widths[i]
+	}
+	pdf.Ln(8)
+
+	ctx.TextColor(p.Text)
+	ctx.Font("", 9)
+
+	currentY := pdf.GetY()
+
+	const lineHeight = 5.0
+	const paddingTop = 3.0
+	const paddingBottom = 3.0
+	const horizontalPadding = 4.0
+	const minRowHeight = 12.0
+
+	maxFloat := func(a, b float64) float64 {
+		if a > b {
+			return a
+		}
+		return b
+	}
+
+	for i, activity := range t.Rows {
+
+		titleLines := ctx.RichLines(activity[1].Text, widths[1]-horizontalPadding)
+		heights := []float64{}
+		for j, cell := range activity {
+			wrappedWidth := widths[j] - horizontalPadding
+			lineCount := len(ctx.SplitLines(cell.Text, wrappedWidth))
+			if cell.Markdown {
+				lineCount = len(titleLines)
+			}
+			height := float64(lineCount)*lineHeight + paddingTop + paddingBottom
+			heights = append(heights, height)
+		}
+
+		rowHeight := minRowHeight
+		for _, h := range heights {
+			rowHeight = maxFloat(rowHeight, h)
+		}
+		if currentY+rowHeight > ctx.ContentBottom() {
+			ctx.NewPage()
+
+			ctx.Fill(p.Accent)
+			ctx.TextColor(p.OnPrimary)
+			ctx.Font("B", 10)
+			x = ctx.Left()
+			for j, header := range headers {
+				pdf.SetXY(x, pdf.GetY())
+				ctx.CellFormat(widths[j], 8, header, "1", 0, "C", true, 0, "")
+				x += widths[j]
+			}
+			pdf.Ln(8)
+
+			ctx.TextColor(p.Text)
+			ctx.Font("", 9)
+			currentY = pdf.GetY()
+		}
+
+		if i%2 == 0 {
+			ctx.Fill(p.Surface)
+		} else {
+			ctx.Fill(p.Background)
+		}
+
+		pdf.SetXY(ctx.Left(), currentY)
+		pdf.Rect(ctx.Left(), currentY, ctx.ContentWidth(), rowHeight, "F")
+
+		x = ctx.Left()
+		for j, cell := range activity {
+			pdf.SetXY(x, currentY)
+			pdf.Rect(x, currentY, widths[j], rowHeight, "D")
+
+			if cell.Markdown {
+				for k, line := range titleLines {
+					ctx.DrawRichLine(line, x+horizontalPadding/2, currentY+paddingTop+float64(k)*lineHeight,
+						widths[j]-horizontalPadding, lineHeight, "C")
+				}
+			} else {
+				textY := currentY + (rowHeight-lineHeight)/2
+				pdf.SetXY(x, textY)
+				ctx.CellFormat(widths[j], lineHeight, cell.Text, "", 0, "C", false, 0, "")
+			}
+			x += widths[j]
+		}
+
+		currentY += rowHeight
+		pdf.SetY(currentY)
+	}
+
+	return nil
+}
+
+func terms(ctx *Context) *Link {
+	return &Link{Text: ctx.T("View all terms and conditions"), URL: ctx.Links.TermsURL}
+}
+
+func addTerms(ctx *Context) error {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	link := terms(ctx)
+
+	ctx.Continue(15, 20)
+	ctx.Heading("Terms and Conditions", 10)
+
+	ctx.TextColor(p.Primary)
+	ctx.Font("U", 10)
+	ctx.TextLink(8, link.Text, link.URL)
+	pdf.Ln(8)
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package render
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/monoMonu/travel-itinerary-pdf/geo"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// FlightSummary lists the flights, each as its date and a sentence, over
+// a note on what the fares include.
+type FlightSummary struct {
+	Rows []Fact
+	Note string
+}
+
+func (*FlightSummary) content() {}
+
+func flightSummary(ctx *Context) *FlightSummary {
+	summary := &FlightSummary{
+		Note: ctx.T("Note: All Flights Include Meals, Seat Choice (Excluding XL), And 20kg/25Kg Checked Baggage."),
+	}
+	for _, flight := range ctx.Data.Flights {
+		summary.Rows = append(summary.Rows, Fact{
+			Label: ctx.Date(flight.Date),
+			Value: ctx.T("Fly %s From %s To %s.", flight.Airline, withAirportCode(flight.From), withAirportCode(flight.To)),
+		})
+	}
+	return summary
+}
+
+// withAirportCode adds the IATA code in brackets to a place geo knows, like
+// "Delhi (DEL)", unless the place already shows it, as "DEL" and
+// "Singapore (SIN)" do.
+func withAirportCode(place string) string {
+	a, ok := geo.FindAirport(place)
+	if !ok || strings.Contains(place, a.Code) {
+		return place
+	}
+	return place + " (" + a.Code + ")"
+}
+
+func addFlightSummary(ctx *Context) error {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	summary := flightSummary(ctx)
+
+	ctx.NewPage()
+	ctx.Heading("Flight Summary", 15)
+
+	dateW := 40.0
+	textX := ctx.Left() + 10 + dateW
+	textW := ctx.Right() - 5 - textX
+
+	for _, row := range summary.Rows {
+		text := row.Value
+		ctx.Font("B", 10)
+		lines := ctx.SplitLines(text, textW)
+		rowHeight := max(15, float64(len(lines))*5+7)
+		if pdf.GetY()+rowHeight > ctx.ContentBottom() {
+			ctx.NewPage()
+		}
+
+		y := pdf.GetY()
+		ctx.Fill(p.Surface)
+		pdf.RoundedRect(ctx.Left(), y, ctx.ContentWidth(), rowHeight, ctx.Radius(0.6), "1234", "F")
+
+		pdf.SetXY(ctx.Left()+10, y+3)
+		ctx.Font("", 10)
+		ctx.TextColor(p.Muted)
+		ctx.Cell(dateW, 8, row.Label)
+
+		ctx.TextColor(p.Text)
+		ctx.Font("B", 10)
+		if len(lines) == 1 {
+			ctx.Cell(textW, 8, text)
+		} else {
+			pdf.SetXY(textX, y+3.5)
+			ctx.MultiCell(textW, 5, text, "L", false)
+		}
+		pdf.SetY(y + rowHeight + 6)
+	}
+
+	pdf.Ln(5)
+	ctx.Font("", 8)
+	ctx.TextColor(p.Muted)
+	ctx.MultiCell(0, 5, summary.Note, "L", false)
+
+	return nil
+}
+
+const hotelPhotoHeight = 20.0
+
+// hotelBookings is the hotels table. Photos, when any hotel has one, get a
+// first column of their own.
+func hotelBookings(ctx *Context) *TableContent {
+	data := ctx.Data
+	t := &TableContent{
+		Headers: []string{ctx.T("City"), ctx.T("Check In"), ctx.T("Check Out"), ctx.T("Nights"), ctx.T("Hotel Name")},
+		Weights: []float64{25, 25, 25, 15, 90},
+	}
+	withPhotos := slices.ContainsFunc(data.Hotels, func(h types.Hotel) bool { return len(h.Image) > 0 })
+	if withPhotos {
+		t.Headers = append([]string{""}, t.Headers...)
+		t.Weights = []float64{32, 22, 24, 24, 14, 64}
+	}
+	for _, hotel := range data.Hotels {
+		row := []Cell{
+			{Text: hotel.City},
+			{Text: ctx.Date(hotel.CheckIn)},
+			{Text: ctx.Date(hotel.CheckOut)},
+			{Text: ctx.Locale.FormatNumber(float64(hotel.Nights), 0)},
+			{Text: hotel.Name},
+		}
+		if withPhotos {
+			row = append([]Cell{{Image: hotel.Image}}, row...)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+func addHotelBookings(ctx *Context) error {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	t := hotelBookings(ctx)
+
+	ctx.Continue(10, 40)
+	ctx.Heading("Hotel Bookings", 15)
+
+	headers, widths := t.Headers, ctx.Columns(t.Weights...)
+	// Rows grow to show the photos.
+	photos := t.imageColumn()
+	rowHeight := 6.0
+	if photos >= 0 {
+		rowHeight = hotelPhotoHeight + 2
+	}
+
+	drawHeader := func() {
+		ctx.Fill(p.Accent)
+		ctx.TextColor(p.OnPrimary)
+		ctx.Font("B", 9)
+		x := ctx.Left()
+		for i, header := range headers {
+			pdf.SetXY(x, pdf.GetY())
+			ctx.CellFormat(widths[i], 8, header, "1", 0, "C", true, 0, "")
+			x += widths[i]
+		}
+		pdf.Ln(8)
+	}
+	drawHeader()
+
+	for i, row := range t.Rows {
+		if pdf.GetY()+rowHeight > ctx.ContentBottom() {
+			ctx.NewPage()
+			drawHeader()
+		}
+		if i%2 == 0 {
+			ctx.Fill(p.Surface)
+		} else {
+			ctx.Fill(p.Background)
+		}
+
+		y := pdf.GetY()
+		x := ctx.Left()
+		ctx.TextColor(p.Text)
+		ctx.Font("", 8)
+		for j, cell := range row {
+			pdf.SetXY(x, y)
+			ctx.CellFormat(widths[j], rowHeight, cell.Text, "1", 0, "C", true, 0, "")
+			x += widths[j]
+		}
+		if photos >= 0 {
+			x := ctx.Left()
+			for _, w := range widths[:photos] {
+				x += w
+			}
+			ctx.drawImageCover(row[photos].Image, x+1, y+1, widths[photos]-2, hotelPhotoHeight, ctx.Radius(0.3))
+		}
+		pdf.SetXY(ctx.Left(), y+rowHeight)
+	}
+
+	return nil
+}
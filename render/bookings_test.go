@@ -0,0 +1,30 @@
+package render
+
+import (
+	"testing"
+
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+func TestFlightSummary(t *testing.T) {
+	tests := []struct {
+		from, to, locale, want string
+	}{
+		{"Delhi", "Singapore", "", "Fly SQ 403 From Delhi (DEL) To Singapore (SIN)."},
+		// Flights read by /parse-flights name their airports like this.
+		{"Singapore (SIN)", "Delhi (DEL)", "", "Fly SQ 403 From Singapore (SIN) To Delhi (DEL)."},
+		{"SIN", "BOM", "", "Fly SQ 403 From SIN To BOM."},
+		{"Mahe", "Goa", "", "Fly SQ 403 From Mahe (SEZ) To Goa (GOI)."},
+		{"Ooty", "Timbuktu", "", "Fly SQ 403 From Ooty To Timbuktu."},
+		{"Delhi", "Singapore (SIN)", "fr", "Vol SQ 403 de Delhi (DEL) à Singapore (SIN)."},
+	}
+	for _, tt := range tests {
+		ctx := &Context{Locale: i18n.Get(tt.locale), Data: types.BookingData{Flights: []types.Flight{
+			{Date: "2025-06-15", Airline: "SQ 403", From: tt.from, To: tt.to},
+		}}}
+		if got := flightSummary(ctx).Rows[0].Value; got != tt.want {
+			t.Errorf("%s to %s: got %q, want %q", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/monoMonu/travel-itinerary-pdf/markdown"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+const (
+	calendarHeaderHeight = 6.0
+	calendarLineHeight   = 3.2
+	calendarFontSize     = 6.5
+	// calendarTop is the room above the first line of a cell, for the date.
+	calendarTop = 5.0
+)
+
+// Calendar is the trip on a calendar grid, Monday first.
+type Calendar struct {
+	Weekdays []string
+	// Months has a grid per month for Options.Calendar "month", and
+	// otherwise a single untitled grid of the weeks the trip covers.
+	Months []CalendarMonth
+	Legend []LegendItem
+}
+
+func (*Calendar) content() {}
+
+type CalendarMonth struct {
+	Title string
+	Weeks [][]CalendarDay
+}
+
+// CalendarDay is one date of a grid: its flights and activities and, at the
+// foot, the hotel for the night.
+type CalendarDay struct {
+	Date time.Time
+	// Label is the day of the month, with the month's name where a week
+	// grid starts or the month changes.
+	Label string
+	// Blank is set for dates outside the month of a month grid, which are
+	// left empty.
+	Blank  bool
+	InTrip bool
+	// Day is the day of the trip, like "Day 3".
+	Day   string
+	Lines []CalendarLine
+	Foot  CalendarLine
+	// Problem marks a night away without a hotel, or with two.
+	Problem bool
+}
+
+type CalendarLine struct {
+	Text string
+	Kind CalendarLineKind
+}
+
+type CalendarLineKind int
+
+const (
+	CalendarActivity CalendarLineKind = iota
+	CalendarFlight
+	CalendarHotel
+	CalendarProblem
+)
+
+func tripCalendar(ctx *Context) *Calendar {
+	s := planSchedule(ctx.Data)
+	if s.empty() {
+		return nil
+	}
+
+	c := &Calendar{Legend: []LegendItem{
+		{Kind: LegendFill, Color: ctx.Theme.Palette.Surface, Label: ctx.T("Trip day")},
+		{Kind: LegendOutline, Color: cancelledColor, Label: ctx.T("No hotel")},
+		{Kind: LegendOutline, Color: cancelledColor, Label: ctx.T("Overlap")},
+	}}
+	for _, name := range weekdayNames {
+		c.Weekdays = append(c.Weekdays, ctx.T(name))
+	}
+
+	if ctx.Data.Options.Calendar == types.CalendarMonth {
+		for month := time.Date(s.first.Year(), s.first.Month(), 1, 0, 0, 0, 0, time.UTC); !month.After(s.last); month = month.AddDate(0, 1, 0) {
+			start := weekStart(month)
+			weeks := int(month.AddDate(0, 1, -1).Sub(start).Hours()/24)/7 + 1
+			title := fmt.Sprintf("%s %d", ctx.Locale.Months[month.Month()-1], month.Year())
+			c.Months = append(c.Months, calendarMonth(ctx, s, title, start, weeks, month.Month()))
+		}
+	} else {
+		start := weekStart(s.first)
+		weeks := int(s.last.Sub(start).Hours()/24)/7 + 1
+		c.Months = append(c.Months, calendarMonth(ctx, s, "", start, weeks, 0))
+	}
+	return c
+}
+
+// calendarMonth fills weeks rows of seven dates from start. With a month,
+// dates outside it are blank.
+func calendarMonth(ctx *Context, s tripSchedule, title string, start time.Time, weeks int, month time.Month) CalendarMonth {
+	m := CalendarMonth{Title: title, Weeks: make([][]CalendarDay, weeks)}
+	for w := range weeks {
+		m.Weeks[w] = make([]CalendarDay, 7)
+		for i := range 7 {
+			date := start.AddDate(0, 0, 7*w+i)
+			if month != 0 && date.Month() != month {
+				m.Weeks[w][i] = CalendarDay{Date: date, Blank: true}
+				continue
+			}
+			// The week grid names the month where it starts and changes.
+			showMonth := month == 0 && (date.Day() == 1 || (w == 0 && i == 0))
+			m.Weeks[w][i] = calendarDay(ctx, s.days[date], date, !date.Before(s.first) && !date.After(s.last), showMonth)
+		}
+	}
+	return m
+}
+
+func calendarDay(ctx *Context, d *scheduleDay, date time.Time, inTrip, showMonth bool) CalendarDay {
+	day := CalendarDay{Date: date, Label: fmt.Sprint(date.Day()), InTrip: inTrip}
+	if showMonth {
+		day.Label += " " + ctx.Locale.Months[date.Month()-1]
+	}
+	if d == nil {
+		return day
+	}
+	if d.number > 0 {
+		day.Day = ctx.T("Day %d", d.number)
+	}
+	for _, f := range d.flights {
+		day.Lines = append(day.Lines, CalendarLine{strings.TrimSpace(f.Departure + " " + f.From + " → " + f.To), CalendarFlight})
+	}
+	for _, a := range d.activities {
+		day.Lines = append(day.Lines, CalendarLine{strings.TrimSpace(a.Time + " " + markdown.PlainText(a.Title)), CalendarActivity})
+	}
+	switch {
+	case d.overlap():
+		day.Foot = CalendarLine{ctx.T("Overlap") + ": " + strings.Join(d.hotels, ", "), CalendarProblem}
+	case d.gap():
+		day.Foot = CalendarLine{ctx.T("No hotel"), CalendarProblem}
+	case len(d.hotels) == 1:
+		day.Foot = CalendarLine{d.hotels[0], CalendarHotel}
+	}
+	day.Problem = d.gap() || d.overlap()
+	return day
+}
+
+// addCalendar draws the trip on a calendar grid, Monday first: by default
+// just the weeks the trip covers, or whole months for Options.Calendar
+// "month". Nights away without a hotel, or with two, are outlined in red.
+func addCalendar(ctx *Context) error {
+	c := tripCalendar(ctx)
+	if c == nil {
+		return nil
+	}
+
+	ctx.NewPage()
+	ctx.Heading("Trip Calendar", 12)
+
+	if ctx.Data.Options.Calendar == types.CalendarMonth {
+		// Every month gets the same cell height, sized so that six weeks
+		// fit on a page.
+		cellH := min(30, max(16, (ctx.ContentBottom()-contentTop-30)/6))
+		gap := 0.0
+		for _, month := range c.Months {
+			ctx.Continue(gap, 8+calendarHeaderHeight+float64(len(month.Weeks))*cellH)
+			gap = 6
+
+			ctx.TextColor(ctx.Theme.Palette.Text)
+			ctx.HeadingFont("B", 12)
+			ctx.Bookmark(month.Title, 1)
+			ctx.Cell(0, 8, month.Title)
+			ctx.PDF.Ln(8)
+
+			drawCalendarGrid(ctx, c.Weekdays, month, cellH)
+		}
+	} else {
+		month := c.Months[0]
+		available := ctx.ContentBottom() - ctx.PDF.GetY() - calendarHeaderHeight - 12
+		cellH := min(48, max(28, available/float64(len(month.Weeks))))
+		drawCalendarGrid(ctx, c.Weekdays, month, cellH)
+	}
+
+	ctx.PDF.Ln(4)
+	drawLegend(ctx, c.Legend)
+	return nil
+}
+
+// drawCalendarGrid draws a month's weeks, repeating the weekday header on
+// every page.
+func drawCalendarGrid(ctx *Context, weekdays []string, month CalendarMonth, cellH float64) {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	cellW := ctx.ContentWidth() / 7
+
+	header := func() {
+		ctx.Fill(p.Accent)
+		ctx.Stroke(p.Border)
+		ctx.TextColor(p.OnPrimary)
+		ctx.Font("B", 8)
+		y := pdf.GetY()
+		for i, name := range weekdays {
+			pdf.SetXY(ctx.Left()+float64(i)*cellW, y)
+			ctx.CellFormat(cellW, calendarHeaderHeight, name, "1", 0, "C", true, 0, "")
+		}
+		pdf.SetY(y + calendarHeaderHeight)
+	}
+	if pdf.GetY()+calendarHeaderHeight+cellH > ctx.ContentBottom() {
+		ctx.NewPage()
+	}
+	header()
+
+	for _, week := range month.Weeks {
+		y := pdf.GetY()
+		if y+cellH > ctx.ContentBottom() {
+			ctx.NewPage()
+			header()
+			y = pdf.GetY()
+		}
+		for i, day := range week {
+			x := ctx.Left() + float64(i)*cellW
+			if day.Blank {
+				ctx.Stroke(p.Border)
+				pdf.Rect(x, y, cellW, cellH, "D")
+				continue
+			}
+			drawCalendarCell(ctx, day, x, y, cellW, cellH)
+		}
+		pdf.SetY(y + cellH)
+	}
+}
+
+// calendarStyle is the font style and colour of a kind of line.
+func (ctx *Context) calendarStyle(kind CalendarLineKind) (string, theme.Color) {
+	p := ctx.Theme.Palette
+	switch kind {
+	case CalendarFlight:
+		return "B", p.Primary
+	case CalendarHotel:
+		return "", p.Muted
+	case CalendarProblem:
+		return "B", cancelledColor
+	}
+	return "", p.Text
+}
+
+// drawCalendarCell draws one date. Lines that do not fit are counted
+// instead.
+func drawCalendarCell(ctx *Context, day CalendarDay, x, y, w, h float64) {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+
+	ctx.Stroke(p.Border)
+	ctx.Fill(p.Background)
+	if day.InTrip {
+		ctx.Fill(p.Surface)
+	}
+	pdf.Rect(x, y, w, h, "FD")
+
+	ctx.TextColor(p.Muted)
+	if day.InTrip {
+		ctx.TextColor(p.Text)
+	}
+	ctx.Font("B", 8)
+	pdf.SetXY(x, y+0.5)
+	ctx.CellFormat(w, 4, day.Label, "", 0, "L", false, 0, "")
+	if day.Day != "" {
+		ctx.TextColor(p.Muted)
+		ctx.Font("", calendarFontSize)
+		pdf.SetXY(x, y+0.5)
+		ctx.CellFormat(w, 4, day.Day, "", 0, "R", false, 0, "")
+	}
+
+	lines := day.Lines
+	room := h - calendarTop - 1
+	if day.Foot.Text != "" {
+		room -= calendarLineHeight
+	}
+	fits := int(math.Floor(room / calendarLineHeight))
+	var more string
+	if len(lines) > fits && fits > 0 {
+		more = ctx.T("+%d more", len(lines)-fits+1)
+		lines = lines[:fits-1]
+	}
+	lines = lines[:min(len(lines), max(fits, 0))]
+
+	for i, line := range lines {
+		style, color := ctx.calendarStyle(line.Kind)
+		ctx.TextColor(color)
+		ctx.Font(style, calendarFontSize)
+		pdf.SetXY(x, y+calendarTop+float64(i)*calendarLineHeight)
+		ctx.CellFormat(w, calendarLineHeight, ctx.truncate(line.Text, w), "", 0, "L", false, 0, "")
+	}
+	if more != "" {
+		ctx.TextColor(p.Muted)
+		ctx.Font("", calendarFontSize)
+		pdf.SetXY(x, y+calendarTop+float64(len(lines))*calendarLineHeight)
+		ctx.CellFormat(w, calendarLineHeight, ctx.truncate(more, w), "", 0, "L", false, 0, "")
+	}
+	if day.Foot.Text != "" {
+		style, color := ctx.calendarStyle(day.Foot.Kind)
+		ctx.TextColor(color)
+		ctx.Font(style, calendarFontSize)
+		pdf.SetXY(x, y+h-calendarLineHeight-0.5)
+		ctx.CellFormat(w, calendarLineHeight, ctx.truncate(day.Foot.Text, w), "", 0, "L", false, 0, "")
+	}
+
+	if day.Problem {
+		ctx.Stroke(cancelledColor)
+		pdf.SetLineWidth(0.5)
+		pdf.Rect(x+0.25, y+0.25, w-0.5, h-0.5, "D")
+		pdf.SetLineWidth(0.2)
+	}
+}
@@ -0,0 +1,186 @@
+package render
+
+import (
+	"testing"
+	"time"
+
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// scheduleTrip is a booking from departure to ret with a day per date and
+// the given hotels, each as {name, check-in, check-out}.
+func scheduleTrip(departure, ret string, hotels ...[3]string) types.BookingData {
+	data := types.BookingData{DepartureDate: departure, ReturnDate: ret}
+	first, _ := parseDay(departure)
+	last, _ := parseDay(ret)
+	for t := first; !t.After(last); t = t.AddDate(0, 0, 1) {
+		data.Days = append(data.Days, types.Day{Date: t.Format(time.DateOnly), Activities: []types.Activity{{Time: "10:00", Title: "Walk"}}})
+	}
+	for _, h := range hotels {
+		data.Hotels = append(data.Hotels, types.Hotel{Name: h[0], CheckIn: h[1], CheckOut: h[2]})
+	}
+	return data
+}
+
+// mustDay parses a YYYY-MM-DD date.
+func mustDay(s string) time.Time {
+	t, ok := parseDay(s)
+	if !ok {
+		panic(s)
+	}
+	return t
+}
+
+// calendarDays indexes the dates of a calendar's grids that are not blank.
+func calendarDays(c *Calendar) map[time.Time]CalendarDay {
+	days := map[time.Time]CalendarDay{}
+	for _, m := range c.Months {
+		for _, week := range m.Weeks {
+			for _, d := range week {
+				if !d.Blank {
+					days[d.Date] = d
+				}
+			}
+		}
+	}
+	return days
+}
+
+func TestCalendarGrids(t *testing.T) {
+	// Thursday 30 January to Sunday 2 February 2025.
+	data := scheduleTrip("2025-01-30", "2025-02-02", [3]string{"Raffles", "2025-01-30", "2025-02-02"})
+	tests := []struct {
+		name     string
+		calendar string
+		titles   []string
+		weeks    []int
+		starts   []string
+		blank    []int
+	}{
+		{name: "week", calendar: "", titles: []string{""}, weeks: []int{1}, starts: []string{"2025-01-27"}, blank: []int{0}},
+		// January runs Wednesday to Friday and February Saturday to
+		// Friday, so the grids have blank dates either side.
+		{name: "month", calendar: types.CalendarMonth, titles: []string{"Jan 2025", "Feb 2025"}, weeks: []int{5, 5}, starts: []string{"2024-12-30", "2025-01-27"}, blank: []int{4, 7}},
+	}
+	for _, tt := range tests {
+		data.Options.Calendar = tt.calendar
+		ctx := &Context{Data: data, Locale: i18n.Get("")}
+		c := tripCalendar(ctx)
+		if len(c.Months) != len(tt.titles) {
+			t.Fatalf("%s: got %d grids, want %d", tt.name, len(c.Months), len(tt.titles))
+		}
+		for i, m := range c.Months {
+			blank := 0
+			for _, week := range m.Weeks {
+				if len(week) != 7 {
+					t.Fatalf("%s: week of %d days", tt.name, len(week))
+				}
+				for _, d := range week {
+					if d.Blank {
+						blank++
+					}
+				}
+			}
+			if m.Title != tt.titles[i] || len(m.Weeks) != tt.weeks[i] || !m.Weeks[0][0].Date.Equal(mustDay(tt.starts[i])) || blank != tt.blank[i] {
+				t.Errorf("%s: grid %d is %q, %d weeks from %s with %d blank, want %q, %d weeks from %s with %d blank",
+					tt.name, i, m.Title, len(m.Weeks), m.Weeks[0][0].Date.Format(time.DateOnly), blank, tt.titles[i], tt.weeks[i], tt.starts[i], tt.blank[i])
+			}
+		}
+
+		days := calendarDays(c)
+		for d := mustDay("2025-01-27"); d.Before(mustDay("2025-02-03")); d = d.AddDate(0, 0, 1) {
+			inTrip := !d.Before(mustDay("2025-01-30"))
+			if days[d].InTrip != inTrip {
+				t.Errorf("%s: %s in trip is %v", tt.name, d.Format(time.DateOnly), days[d].InTrip)
+			}
+		}
+		if got := days[mustDay("2025-02-01")]; got.Day != "Day 3" || got.Foot.Text != "Raffles" || got.Problem {
+			t.Errorf("%s: 1 February is %q with foot %q, problem %v", tt.name, got.Day, got.Foot.Text, got.Problem)
+		}
+		// The last day goes home, so it has no night to book.
+		if got := days[mustDay("2025-02-02")]; got.Foot != (CalendarLine{}) || got.Problem {
+			t.Errorf("%s: the return day has foot %+v, problem %v", tt.name, got.Foot, got.Problem)
+		}
+	}
+}
+
+// The week grid names the month on its first date and where it changes.
+func TestCalendarWeekLabels(t *testing.T) {
+	ctx := &Context{Data: scheduleTrip("2025-01-30", "2025-02-02"), Locale: i18n.Get("")}
+	days := calendarDays(tripCalendar(ctx))
+	want := map[string]string{
+		"2025-01-27": "27 Jan",
+		"2025-01-28": "28",
+		"2025-01-31": "31",
+		"2025-02-01": "1 Feb",
+		"2025-02-02": "2",
+	}
+	for d, label := range want {
+		if got := days[mustDay(d)].Label; got != label {
+			t.Errorf("%s is labelled %q, want %q", d, got, label)
+		}
+	}
+}
+
+func TestCalendarNights(t *testing.T) {
+	data := scheduleTrip("2025-06-15", "2025-06-19",
+		[3]string{"Raffles", "2025-06-15", "2025-06-17"},
+		[3]string{"Fullerton", "2025-06-16", "2025-06-18"},
+	)
+	ctx := &Context{Data: data, Locale: i18n.Get("")}
+	days := calendarDays(tripCalendar(ctx))
+	tests := []struct {
+		date    string
+		foot    CalendarLine
+		problem bool
+	}{
+		{"2025-06-15", CalendarLine{"Raffles", CalendarHotel}, false},
+		{"2025-06-16", CalendarLine{"Overlap: Raffles, Fullerton", CalendarProblem}, true},
+		{"2025-06-17", CalendarLine{"Fullerton", CalendarHotel}, false},
+		{"2025-06-18", CalendarLine{"No hotel", CalendarProblem}, true},
+		{"2025-06-19", CalendarLine{}, false},
+	}
+	for _, tt := range tests {
+		if got := days[mustDay(tt.date)]; got.Foot != tt.foot || got.Problem != tt.problem {
+			t.Errorf("%s has foot %+v, problem %v, want %+v, %v", tt.date, got.Foot, got.Problem, tt.foot, tt.problem)
+		}
+	}
+}
+
+func TestCalendarSingleDay(t *testing.T) {
+	data := scheduleTrip("2025-03-05", "2025-03-05")
+	data.Flights = []types.Flight{{Date: "2025-03-05", From: "DEL", To: "BOM", Departure: "08:00", Arrival: "10:10"}}
+	for _, calendar := range []string{types.CalendarWeek, types.CalendarMonth} {
+		data.Options.Calendar = calendar
+		ctx := &Context{Data: data, Locale: i18n.Get("")}
+		c := tripCalendar(ctx)
+		var inTrip []CalendarDay
+		for _, d := range calendarDays(c) {
+			if d.InTrip {
+				inTrip = append(inTrip, d)
+			}
+		}
+		if len(c.Months) != 1 || len(inTrip) != 1 {
+			t.Fatalf("%q: got %d grids and %d trip days", calendar, len(c.Months), len(inTrip))
+		}
+		d := inTrip[0]
+		want := []CalendarLine{{"08:00 DEL → BOM", CalendarFlight}, {"10:00 Walk", CalendarActivity}}
+		if !d.Date.Equal(mustDay("2025-03-05")) || d.Day != "Day 1" || d.Problem || len(d.Lines) != len(want) {
+			t.Fatalf("%q: trip day is %+v", calendar, d)
+		}
+		for i, line := range d.Lines {
+			if line != want[i] {
+				t.Errorf("%q: line %d is %+v, want %+v", calendar, i, line, want[i])
+			}
+		}
+	}
+}
+
+// A trip without any dates has no calendar.
+func TestCalendarEmpty(t *testing.T) {
+	ctx := &Context{Data: types.BookingData{Destination: "Singapore"}, Locale: i18n.Get("")}
+	if c := tripCalendar(ctx); c != nil {
+		t.Errorf("got %d grids for an undated trip", len(c.Months))
+	}
+}
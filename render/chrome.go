@@ -0,0 +1,127 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const logoImage = "theme-logo"
+
+func registerLogo(ctx *Context) {
+	if ctx.Theme.LogoBytes == nil {
+		return
+	}
+	opts := gofpdf.ImageOptions{ImageType: ctx.Theme.LogoType}
+	ctx.PDF.RegisterImageOptionsReader(logoImage, opts, bytes.NewReader(ctx.Theme.LogoBytes))
+}
+
+// drawBrand draws the theme logo, or its wordmark in the primary colour,
+// inside a cell of height h at the cursor. align is "L", "C" or "R" like
+// CellFormat, and the cursor moves as CellFormat with ln would move it.
+func drawBrand(ctx *Context, fontSize, h float64, align string, ln int) {
+	pdf := ctx.PDF
+
+	if ctx.Theme.LogoBytes == nil {
+		ctx.TextColor(ctx.Theme.Palette.Primary)
+		ctx.HeadingFont("B", fontSize)
+		ctx.CellFormat(0, h, ctx.Theme.Wordmark, "", ln, align, false, 0, "")
+		return
+	}
+
+	info := pdf.GetImageInfo(logoImage)
+	w := h * info.Width() / info.Height()
+	x, y := pdf.GetXY()
+	left, _, right, _ := pdf.GetMargins()
+	pageW, _ := pdf.GetPageSize()
+	switch align {
+	case "C":
+		x = (pageW - w) / 2
+	case "R":
+		x = pageW - right - w
+	}
+	pdf.ImageOptions(logoImage, x, y, 0, h, false, gofpdf.ImageOptions{}, 0, "")
+
+	if ln == 1 {
+		pdf.SetXY(left, y+h)
+	} else {
+		pdf.SetXY(x+w, y)
+	}
+}
+
+func addPageHeader(ctx *Context) {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+
+	pdf.SetY(15)
+	drawBrand(ctx, 14, 8, "L", 0)
+
+	pdf.SetX(ctx.Left())
+	ctx.TextColor(p.Muted)
+	ctx.Font("", 8)
+	ctx.CellFormat(0, 8, ctx.Theme.Tagline, "", 1, "R", false, 0, "")
+
+	ctx.Stroke(p.Border)
+	pdf.Line(ctx.Left(), 25, ctx.Right(), 25)
+}
+
+// pageCountAlias is replaced with the total page count when the document is
+// written.
+const pageCountAlias = "{nb}"
+
+func addFooterToAllPages(ctx *Context) {
+	pdf, p, company := ctx.PDF, ctx.Theme.Palette, ctx.Theme.Company
+
+	pdf.AliasNbPages(pageCountAlias)
+
+	pdf.SetFooterFunc(func() {
+		// The footer runs in the middle of AddPage; leave the section's font
+		// as it was.
+		saved := ctx.font
+		defer func() { ctx.font = saved }()
+
+		drawWatermark(ctx)
+
+		// Three columns: company, contact details and brand. They keep their
+		// A4 proportions and the text shrinks with narrower pages.
+		left, scale := ctx.Left(), ctx.Scale()
+		contactX := left + ctx.ContentWidth()*105/180
+		brandX := left + ctx.ContentWidth()*155/180
+
+		pdf.SetY(-20)
+		ctx.Font("", 8*scale)
+		ctx.TextColor(p.Muted)
+
+		pdf.SetX(left)
+		ctx.Cell(contactX-left, 5, company.Name)
+		for _, line := range company.Address {
+			pdf.Ln(4)
+			pdf.SetX(left)
+			ctx.Cell(contactX-left, 5, line)
+		}
+
+		if company.Phone != "" {
+			pdf.SetXY(contactX, -20)
+			label := ctx.T("Phone: ")
+			ctx.Cell(0, 5, label)
+			pdf.SetX(contactX + ctx.StringWidth(label))
+			ctx.TextLink(5, company.Phone, "tel:"+strings.Join(strings.Fields(company.Phone), ""))
+		}
+		if company.Email != "" {
+			pdf.SetXY(contactX, -16)
+			label := ctx.T("Email ID: ")
+			ctx.Cell(0, 5, label)
+			pdf.SetX(contactX + ctx.StringWidth(label))
+			ctx.TextLink(5, company.Email, "mailto:"+company.Email)
+		}
+		pdf.SetXY(contactX, -12)
+		ctx.Cell(0, 5, ctx.T("Page %d of %s", pdf.PageNo(), pageCountAlias))
+
+		pdf.SetXY(brandX, -18)
+		drawBrand(ctx, 10*scale, 5*scale, "", 0)
+		pdf.SetXY(brandX, -14)
+		ctx.TextColor(p.Muted)
+		ctx.Font("", 6*scale)
+		ctx.Cell(0, 5, ctx.Theme.Tagline)
+	})
+}
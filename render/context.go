@@ -0,0 +1,193 @@
+package render
+
+import (
+	"slices"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/monoMonu/travel-itinerary-pdf/config"
+	"github.com/monoMonu/travel-itinerary-pdf/fonts"
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+const (
+	// contentTop is below the page header, which has the same height on
+	// every page size.
+	contentTop = 40.0
+	// footerHeight is reserved at the bottom of every page.
+	footerHeight = 35.0
+)
+
+// Context is handed to every section while the PDF is being built.
+type Context struct {
+	PDF     *gofpdf.Fpdf
+	Data    types.BookingData
+	Theme   theme.Theme
+	Links   config.Links
+	Payment config.Payment
+	// QuoteValidityDays is how long a quote stays valid after it is issued.
+	QuoteValidityDays int
+	// Locale translates labels and formats dates and numbers.
+	Locale *i18n.Locale
+
+	// created is the document date, pinned in deterministic mode.
+	created time.Time
+
+	font   fontState
+	faces  map[*fonts.Face]bool
+	images map[string]float64
+
+	// sections names what is being rendered; contents has one entry per
+	// section and entry is the one being drawn. contentsPage is the page
+	// the table of contents is on, if there is one.
+	sections     []string
+	contents     []*tocEntry
+	entry        *tocEntry
+	contentsPage int
+
+	// pageOpen is false before the first content page and after a section
+	// (like the cover) that must not be drawn over.
+	pageOpen bool
+}
+
+func (ctx *Context) hasSection(name string) bool {
+	return slices.Contains(ctx.sections, name)
+}
+
+// NewPage starts a content page with the standard header and moves the
+// cursor to the top of the content area.
+func (ctx *Context) NewPage() {
+	ctx.PDF.AddPage()
+	addPageHeader(ctx)
+	ctx.PDF.SetY(contentTop)
+	ctx.pageOpen = true
+}
+
+// The layout is computed from the page box, so sections work on any page
+// size and orientation.
+
+func (ctx *Context) PageWidth() float64 {
+	w, _ := ctx.PDF.GetPageSize()
+	return w
+}
+
+func (ctx *Context) PageHeight() float64 {
+	_, h := ctx.PDF.GetPageSize()
+	return h
+}
+
+// Left is the x of the left margin.
+func (ctx *Context) Left() float64 {
+	left, _, _, _ := ctx.PDF.GetMargins()
+	return left
+}
+
+// Right is the x of the right margin.
+func (ctx *Context) Right() float64 {
+	_, _, right, _ := ctx.PDF.GetMargins()
+	return ctx.PageWidth() - right
+}
+
+func (ctx *Context) ContentWidth() float64 {
+	return ctx.Right() - ctx.Left()
+}
+
+// ContentBottom is the lowest y sections may draw at.
+func (ctx *Context) ContentBottom() float64 {
+	return ctx.PageHeight() - footerHeight
+}
+
+// Columns scales relative column widths to fill the content width.
+func (ctx *Context) Columns(weights ...float64) []float64 {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	widths := make([]float64, len(weights))
+	for i, w := range weights {
+		widths[i] = w / total * ctx.ContentWidth()
+	}
+	return widths
+}
+
+// Scale is the content width relative to portrait A4, for the few things
+// like the footer that shrink with the page instead of wrapping.
+func (ctx *Context) Scale() float64 {
+	return min(1, ctx.ContentWidth()/180)
+}
+
+// Continue leaves gap below the previous section when at least minHeight
+// more fits on the current page, and starts a new page otherwise.
+func (ctx *Context) Continue(gap, minHeight float64) {
+	if ctx.pageOpen && ctx.PDF.GetY()+gap+minHeight <= ctx.ContentBottom() {
+		ctx.PDF.SetY(ctx.PDF.GetY() + gap)
+		return
+	}
+	ctx.NewPage()
+}
+
+// ClosePage makes the next Continue start a fresh page.
+func (ctx *Context) ClosePage() {
+	ctx.pageOpen = false
+}
+
+// Heading draws a section title, translated, at the cursor and advances by
+// advance. It also adds the title to the outline and, for the first heading
+// of a section, fixes where its contents entry points.
+func (ctx *Context) Heading(title string, advance float64) {
+	title = ctx.T(title)
+	if e := ctx.entry; e != nil && e.page == 0 {
+		e.resolve(ctx, ctx.PDF.PageNo(), ctx.PDF.GetY())
+	}
+
+	ctx.TextColor(ctx.Theme.Palette.Text)
+	ctx.HeadingFont("B", 18)
+	ctx.Bookmark(title, 0)
+	ctx.Cell(0, 10, title)
+	ctx.PDF.Ln(advance)
+}
+
+// T translates msg into the booking's language; see i18n.Locale.T.
+func (ctx *Context) T(msg string, args ...any) string {
+	return ctx.Locale.T(msg, args...)
+}
+
+// Date formats a YYYY-MM-DD date for the booking's locale.
+func (ctx *Context) Date(date string) string {
+	return ctx.Locale.FormatDate(date)
+}
+
+// Amount formats a rupee amount without decimals.
+func (ctx *Context) Amount(v float64) string {
+	return ctx.T("Rs. %s", ctx.Locale.FormatNumber(v, 0))
+}
+
+func (ctx *Context) Fill(c theme.Color) {
+	ctx.PDF.SetFillColor(c.R, c.G, c.B)
+}
+
+func (ctx *Context) TextColor(c theme.Color) {
+	ctx.PDF.SetTextColor(c.R, c.G, c.B)
+}
+
+func (ctx *Context) Stroke(c theme.Color) {
+	ctx.PDF.SetDrawColor(c.R, c.G, c.B)
+}
+
+// Font sets the theme's body font.
+func (ctx *Context) Font(style string, size float64) {
+	ctx.setFont(ctx.Theme.Fonts.Body, style, size)
+}
+
+// HeadingFont sets the theme's heading font.
+func (ctx *Context) HeadingFont(style string, size float64) {
+	ctx.setFont(ctx.Theme.Fonts.Heading, style, size)
+}
+
+// Radius returns the theme corner radius scaled by factor: 1 for cards, 0.6
+// for rows and 1.6 for buttons.
+func (ctx *Context) Radius(factor float64) float64 {
+	return ctx.Theme.Radius * factor
+}
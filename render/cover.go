@@ -0,0 +1,169 @@
+package render
+
+import (
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+)
+
+// Cover is the opening page: a greeting over the destination photo, the
+// trip's details and a code for the online itinerary.
+type Cover struct {
+	Greeting, Title, Duration string
+	Image                     types.Image
+	Details                   []Fact
+	// Map takes the place of Details when the route section, which lists
+	// them instead, is part of the document.
+	Map *RouteMap
+	// QR is what the code encodes, with Caption under it. Link is the
+	// online itinerary, empty when the code is just the booking reference.
+	QR, Caption, Link string
+}
+
+func (*Cover) content() {}
+
+func cover(ctx *Context) *Cover {
+	data := ctx.Data
+	nights := utils.CalculateNights(data.DepartureDate, data.ReturnDate)
+	c := &Cover{
+		Greeting: ctx.T("Hi, %s!", data.CustomerName),
+		Title:    ctx.T("%s Itinerary", data.Destination),
+		Duration: ctx.T("%d Days %d Nights", nights+1, nights),
+		Image:    data.CoverImage,
+		Link:     itineraryURL(ctx.Links.ItineraryURL, data.BookingReference),
+	}
+	if route := buildRoute(data); ctx.hasSection("route") && route.drawable() {
+		c.Map = route
+	} else {
+		c.Details = tripDetails(ctx)
+	}
+	c.QR, c.Caption = c.Link, ctx.T("Scan for your online itinerary")
+	if c.Link == "" {
+		c.QR, c.Caption = data.BookingReference, ctx.T("Booking reference")
+	}
+	return c
+}
+
+// coverLayout places the banner and the trip card. Pages too short for the
+// A4 layout pull everything up and give the card what is left above the
+// footer. The QR code sits at the right of the card.
+type coverLayout struct {
+	brandY           float64
+	bannerY, bannerH float64
+	cardY, cardH     float64
+	qrX, qrY, qrSize float64
+}
+
+func coverLayoutFor(ctx *Context) coverLayout {
+	l := coverLayout{brandY: 25, bannerY: 55, bannerH: 50, cardY: 115, cardH: 60}
+	if ctx.ContentBottom() < l.cardY+l.cardH {
+		l = coverLayout{brandY: 10, bannerY: 35, bannerH: 36, cardY: 75}
+		l.cardH = ctx.ContentBottom() - 2 - l.cardY
+	}
+	l.qrSize = min(40, ctx.ContentWidth()/4, l.cardH-12)
+	l.qrX = ctx.Right() - 8 - l.qrSize
+	l.qrY = l.cardY + 4
+	return l
+}
+
+func addCoverPage(ctx *Context) error {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	c := cover(ctx)
+
+	pdf.AddPage()
+	ctx.Bookmark(ctx.T("Trip Overview"), 0)
+
+	ctx.Fill(p.Background)
+	pdf.Rect(0, 0, ctx.PageWidth(), ctx.PageHeight(), "F")
+	drawStatusBadge(ctx, 10)
+
+	layout := coverLayoutFor(ctx)
+	pdf.SetY(layout.brandY)
+	drawBrand(ctx, 28, 15, "C", 1)
+
+	ctx.Font("", 10)
+	ctx.TextColor(p.Muted)
+	ctx.CellFormat(0, 8, ctx.Theme.Tagline, "", 1, "C", false, 0, "")
+
+	// With a destination photo the banner becomes a tinted image so the
+	// greeting stays readable.
+	ctx.Fill(p.Primary)
+	left, width := ctx.Left(), ctx.ContentWidth()
+	bannerY, bannerH := layout.bannerY, layout.bannerH
+	if ctx.drawImageCover(c.Image, left, bannerY, width, bannerH, ctx.Radius(1)) {
+		pdf.SetAlpha(0.55, "Normal")
+		pdf.RoundedRect(left, bannerY, width, bannerH, ctx.Radius(1), "1234", "F")
+		pdf.SetAlpha(1, "Normal")
+	} else {
+		pdf.RoundedRect(left, bannerY, width, bannerH, ctx.Radius(1), "1234", "F")
+	}
+
+	ctx.TextColor(p.OnPrimary)
+	ctx.HeadingFont("B", 18)
+	pdf.SetY(bannerY + (bannerH-30)/2)
+	ctx.CellFormat(0, 10, c.Greeting, "", 1, "C", false, 0, "")
+
+	ctx.HeadingFont("B", 22)
+	ctx.CellFormat(0, 12, c.Title, "", 1, "C", false, 0, "")
+
+	ctx.Font("", 14)
+	ctx.CellFormat(0, 10, c.Duration, "", 1, "C", false, 0, "")
+
+	ctx.Fill(p.Surface)
+	ctx.Stroke(p.Border)
+	pdf.RoundedRect(left, layout.cardY, width, layout.cardH, ctx.Radius(1), "1234", "FD")
+
+	if c.Map != nil {
+		drawRouteMap(ctx, c.Map, left+4, layout.cardY+4, layout.qrX-8-(left+4), layout.cardH-8, true)
+	} else {
+		drawCoverDetails(ctx, layout, c.Details)
+	}
+
+	if err := drawCoverQR(ctx, c, layout); err != nil {
+		return err
+	}
+
+	ctx.ClosePage()
+	return nil
+}
+
+func drawCoverDetails(ctx *Context, layout coverLayout, details []Fact) {
+	pdf := ctx.PDF
+
+	// Keep the rows centred in the card, closing them up when it is short.
+	step := min(8, (layout.cardH-4)/float64(len(details)))
+	y := layout.cardY + (layout.cardH-step*float64(len(details)))/2
+	x := ctx.Left() + 10
+	valueW := min(75, layout.qrX-x-42)
+	ctx.TextColor(ctx.Theme.Palette.Text)
+	for _, detail := range details {
+		pdf.SetXY(x, y)
+		ctx.Font("B", 9)
+		ctx.Cell(40, min(6, step), detail.Label+":")
+		ctx.Font("", 9)
+		ctx.Cell(valueW, min(6, step), detail.Value)
+		y += step
+	}
+}
+
+// drawCoverQR encodes the online itinerary link, which carries the booking
+// reference, or the bare reference when there is no link.
+func drawCoverQR(ctx *Context, c *Cover, layout coverLayout) error {
+	pdf := ctx.PDF
+	if c.QR == "" {
+		return nil
+	}
+
+	x, y, size := layout.qrX, layout.qrY, layout.qrSize
+	if err := drawQR(ctx, c.QR, x, y, size); err != nil {
+		return err
+	}
+	if c.Link != "" {
+		pdf.LinkString(x, y, size, size, c.Link)
+	}
+
+	pdf.SetXY(x-5, y+size)
+	ctx.TextColor(ctx.Theme.Palette.Muted)
+	ctx.Font("", 7)
+	ctx.CellFormat(size+10, 4, c.Caption, "", 0, "C", false, 0, "")
+	return nil
+}
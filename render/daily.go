@@ -0,0 +1,252 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/monoMonu/travel-itinerary-pdf/markdown"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// Daily itinerary geometry. Each day has a header on the left (badge, date,
+// subtitle) and a timeline of activities on the right, starting level with
+// the date. Heights are fixed; x positions come from dailyColumns.
+const (
+	dayBadgeRadius  = 12.0
+	dayHeaderHeight = 30.0
+	dayGap          = 10.0
+
+	activityOffsetY = 10.0
+	activityTimeH   = 5.0
+	activityLineH   = 4.0
+	activityGap     = 8.0
+	timelineDotR    = 3.0
+	activityImageW  = 48.0
+	activityImageH  = 27.0
+)
+
+// dailyColumns are the x positions of the day header and the timeline. The
+// timeline keeps its place at two thirds of the width on any page size.
+type dailyColumns struct {
+	badgeX, textX, timelineX float64
+	activityX, activityW     float64
+	imageW                   float64
+}
+
+func dailyColumnsFor(ctx *Context) dailyColumns {
+	c := dailyColumns{
+		badgeX:    ctx.Left() + 10,
+		textX:     ctx.Left() + 30,
+		timelineX: ctx.Left() + ctx.ContentWidth()*105/180,
+	}
+	c.activityX = c.timelineX + 8
+	c.activityW = ctx.Right() - 2 - c.activityX
+	c.imageW = min(activityImageW, c.activityW-2)
+	return c
+}
+
+// DailyPlan is the day-by-day plan.
+type DailyPlan struct {
+	Days []DayPlan
+}
+
+func (*DailyPlan) content() {}
+
+// DayPlan is one day: its number, date and subtitle and the activities in
+// order.
+type DayPlan struct {
+	Number         int
+	Date, Subtitle string
+	Activities     []PlannedActivity
+}
+
+// PlannedActivity is an activity as the timeline shows it. Text uses the
+// Markdown subset.
+type PlannedActivity struct {
+	Time, Text string
+	Image      types.Image
+}
+
+func dailyPlan(ctx *Context) *DailyPlan {
+	data := ctx.Data
+	plan := &DailyPlan{Days: make([]DayPlan, len(data.Days))}
+	for i, day := range data.Days {
+		d := DayPlan{
+			Number:   i + 1,
+			Date:     ctx.Date(day.Date),
+			Subtitle: ctx.T("Arrival in %s & City Exploration", data.Destination),
+		}
+		for _, activity := range day.Activities {
+			d.Activities = append(d.Activities, PlannedActivity{
+				Time:  activity.Time,
+				Text:  activityText(activity.Description),
+				Image: activity.Image,
+			})
+		}
+		plan.Days[i] = d
+	}
+	return plan
+}
+
+// activityLayout is an activity measured before anything is drawn.
+type activityLayout struct {
+	time  string
+	lines []richLine
+	image types.Image
+}
+
+func (a activityLayout) height() float64 {
+	h := activityTimeH + float64(len(a.lines))*activityLineH
+	if len(a.image) > 0 {
+		h += activityImageH + 2
+	}
+	return h
+}
+
+func measureDay(ctx *Context, day DayPlan, cols dailyColumns) []activityLayout {
+	ctx.Font("", 8)
+	layouts := make([]activityLayout, len(day.Activities))
+	for i, activity := range day.Activities {
+		layouts[i] = activityLayout{
+			time:  activity.Time,
+			lines: ctx.RichLines(activity.Text, cols.activityW),
+			image: activity.Image,
+		}
+	}
+	return layouts
+}
+
+// activityText is a description as the timeline shows it: after a dash,
+// unless it opens with a list of its own.
+func activityText(description string) string {
+	first, _, _ := strings.Cut(description, "\n")
+	if blocks := markdown.Parse(first); len(blocks) > 0 && blocks[0].Marker != "" {
+		return description
+	}
+	return `\- ` + description
+}
+
+func addDailyItinerary(ctx *Context) error {
+	pdf := ctx.PDF
+	plan := dailyPlan(ctx)
+
+	ctx.NewPage()
+	ctx.Heading("Daily Itinerary", 20)
+
+	cols := dailyColumnsFor(ctx)
+	bottomY := ctx.ContentBottom()
+	for _, day := range plan.Days {
+		activities := measureDay(ctx, day, cols)
+
+		// Keep the header together with the start of the first activity.
+		need := dayHeaderHeight
+		if len(activities) > 0 {
+			first := activityTimeH + float64(min(len(activities[0].lines), 2))*activityLineH
+			need = max(need, activityOffsetY+first)
+		}
+		if pdf.GetY()+need > bottomY {
+			ctx.NewPage()
+		}
+
+		dayY := pdf.GetY()
+		ctx.Bookmark(ctx.T("Day %d - %s", day.Number, day.Date), 1)
+		headerBottom := drawDayHeader(ctx, day.Number, day.Date, day.Subtitle, dayY)
+		bottom := headerBottom
+
+		y := dayY + activityOffsetY
+		prevDotY := -1.0
+		for _, activity := range activities {
+			// Move the whole activity if it fits on a fresh page; split it
+			// line by line only when it is taller than a page on its own.
+			fitsOnPage := activityOffsetY+activity.height() <= bottomY-contentTop
+			if y+activity.height() > bottomY && (fitsOnPage || y+activityTimeH+activityLineH > bottomY) {
+				headerBottom, y = continueDay(ctx, day.Number, day.Date)
+				prevDotY = -1
+			}
+
+			prevDotY = drawActivityDot(ctx, y, prevDotY)
+			pdf.SetXY(cols.activityX, y-3)
+			ctx.TextColor(ctx.Theme.Palette.Text)
+			ctx.Font("B", 9)
+			ctx.Cell(0, activityTimeH, activity.time)
+			y += activityTimeH
+
+			ctx.Font("", 8)
+			for _, line := range activity.lines {
+				if y-3+activityLineH > bottomY {
+					headerBottom, y = continueDay(ctx, day.Number, day.Date)
+					prevDotY = -1
+					ctx.Font("", 8)
+				}
+				ctx.DrawRichLine(line, cols.activityX, y-3, cols.activityW, activityLineH, "L")
+				y += activityLineH
+			}
+			if len(activity.image) > 0 {
+				if y-1+activityImageH > bottomY {
+					headerBottom, y = continueDay(ctx, day.Number, day.Date)
+					prevDotY = -1
+				}
+				if ctx.drawImageCover(activity.image, cols.activityX+1, y-1, cols.imageW, activityImageH, ctx.Radius(0.4)) {
+					y += activityImageH + 2
+				}
+			}
+			y += activityGap
+			bottom = max(headerBottom, y-activityGap)
+		}
+
+		pdf.SetY(bottom + dayGap)
+	}
+
+	return nil
+}
+
+// continueDay starts a new page with a short header for a day that did not
+// fit, and returns the header bottom and first activity position.
+func continueDay(ctx *Context, number int, date string) (float64, float64) {
+	ctx.NewPage()
+	dayY := ctx.PDF.GetY()
+	return drawDayHeader(ctx, number, date, ctx.T("(continued)"), dayY), dayY + activityOffsetY
+}
+
+// drawDayHeader draws the day badge, formatted date and subtitle and returns
+// the lowest point it drew on.
+func drawDayHeader(ctx *Context, number int, date, subtitle string, dayY float64) float64 {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	cols := dailyColumnsFor(ctx)
+
+	ctx.Fill(p.Accent)
+	pdf.Circle(cols.badgeX, dayY+15, dayBadgeRadius, "F")
+
+	ctx.TextColor(p.OnPrimary)
+	ctx.Font("B", 8)
+	pdf.SetXY(cols.badgeX-dayBadgeRadius, dayY+8)
+	ctx.CellFormat(2*dayBadgeRadius, 5, ctx.T("Day"), "", 2, "C", false, 0, "")
+	ctx.Font("B", 14)
+	ctx.CellFormat(2*dayBadgeRadius, 7, fmt.Sprintf("%d", number), "", 0, "C", false, 0, "")
+
+	pdf.SetXY(cols.textX, dayY+10)
+	ctx.TextColor(p.Text)
+	ctx.Font("B", 12)
+	ctx.Cell(0, 8, date)
+	pdf.Ln(6)
+	pdf.SetX(cols.textX)
+	ctx.Font("", 10)
+	ctx.MultiCell(cols.timelineX-cols.textX-5, 5, subtitle, "L", false)
+
+	return max(dayY+dayHeaderHeight, pdf.GetY())
+}
+
+// drawActivityDot draws the timeline marker at y and, when there is an
+// earlier marker on the same page, the connector up to it.
+func drawActivityDot(ctx *Context, y, prevDotY float64) float64 {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	timelineX := dailyColumnsFor(ctx).timelineX
+
+	if prevDotY >= 0 {
+		ctx.Stroke(p.Rule)
+		pdf.Line(timelineX, prevDotY+timelineDotR, timelineX, y-timelineDotR)
+	}
+	ctx.Fill(p.Primary)
+	pdf.Circle(timelineX, y, timelineDotR, "F")
+	return y
+}
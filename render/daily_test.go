@@ -0,0 +1,137 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+var (
+	pageObjectRe = regexp.MustCompile(`(?s)\n\d+ 0 obj\n<</Type /Page\n.*?/Contents (\d+) 0 R>>`)
+	destRe       = regexp.MustCompile(`/Dest \[(\d+) 0 R `)
+)
+
+// renderedPage is a page of an uncompressed PDF: the destinations of its
+// internal links, by page number, and the text it shows.
+type renderedPage struct {
+	dests []int
+	text  string
+}
+
+// renderPages renders data without compression and splits the output into
+// its pages, the way gofpdf writes them: each page object followed by its
+// content stream. Text is written as UTF-16, so the zero bytes of the
+// Latin text in these tests are dropped.
+func renderPages(t *testing.T, data types.BookingData, cfg Config) []renderedPage {
+	t.Helper()
+	pdf, err := Build(data, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pdf.SetCompression(false)
+	var buf bytes.Buffer
+	if err := Write(&buf, pdf, data.Options); err != nil {
+		t.Fatal(err)
+	}
+	doc := buf.Bytes()
+
+	var pages []renderedPage
+	for _, m := range pageObjectRe.FindAllSubmatch(doc, -1) {
+		var page renderedPage
+		for _, d := range destRe.FindAllSubmatch(m[0], -1) {
+			// Page n is object 1+2n.
+			n, _ := strconv.Atoi(string(d[1]))
+			page.dests = append(page.dests, (n-1)/2)
+		}
+		head := []byte("\n" + string(m[1]) + " 0 obj\n<</Length ")
+		at := bytes.Index(doc, head)
+		if at < 0 {
+			t.Fatalf("no content object %s", m[1])
+		}
+		rest := doc[at+len(head):]
+		end := bytes.IndexByte(rest, '>')
+		length, _ := strconv.Atoi(string(rest[:end]))
+		stream := rest[bytes.Index(rest, []byte("stream\n"))+len("stream\n"):]
+		page.text = string(bytes.ReplaceAll(stream[:length], []byte{0}, nil))
+		pages = append(pages, page)
+	}
+	if len(pages) != pdf.PageCount() {
+		t.Fatalf("found %d pages of %d", len(pages), pdf.PageCount())
+	}
+	return pages
+}
+
+// words is n numbered words, each written once in the PDF whatever the
+// line breaks.
+func words(prefix string, n int) string {
+	w := make([]string, n)
+	for i := range w {
+		w[i] = fmt.Sprintf("%s%03d", prefix, i)
+	}
+	return strings.Join(w, " ")
+}
+
+func TestDailySplitsDays(t *testing.T) {
+	many := make([]types.Activity, 24)
+	for i := range many {
+		many[i] = types.Activity{Time: fmt.Sprintf("%02d:00", i), Description: words(fmt.Sprintf("a%02dw", i), 40)}
+	}
+	tests := []struct {
+		name       string
+		activities []types.Activity
+		// pages is the page count, with the second day on the last page.
+		pages int
+	}{
+		{"short day", many[:2], 1},
+		// Four activities fit on a page, and the second day starts a
+		// seventh.
+		{"many activities", many, 7},
+		{"activity longer than a page", []types.Activity{{Time: "09:00", Description: words("long", 900)}}, 4},
+	}
+	for _, tt := range tests {
+		data := sampleBooking()
+		data.Options.Sections = []string{"daily"}
+		data.Days = []types.Day{
+			{Date: "2025-06-15", Activities: tt.activities},
+			{Date: "2025-06-16", Activities: []types.Activity{{Time: "10:00", Description: "Last stop"}}},
+		}
+		pages := renderPages(t, data, Config{})
+		if len(pages) != tt.pages {
+			t.Errorf("%s: got %d pages, want %d", tt.name, len(pages), tt.pages)
+		}
+
+		// Every word of the first day shows once, in order, and each page
+		// after the first that carries on the day does so under a
+		// "(continued)" header.
+		var day []string
+		for _, a := range tt.activities {
+			day = append(day, strings.Fields(a.Description)...)
+		}
+		next := 0
+		for i, page := range pages {
+			found := 0
+			for next < len(day) && strings.Contains(page.text, day[next]) {
+				next++
+				found++
+			}
+			continued := strings.Contains(page.text, `\(continued\)`)
+			if continued != (i > 0 && found > 0) {
+				t.Errorf("%s: page %d has %d words of the day and continued %v", tt.name, i+1, found, continued)
+			}
+			if continued && !strings.Contains(page.text, "15 Jun") {
+				t.Errorf("%s: page %d continues the day without its date", tt.name, i+1)
+			}
+		}
+		if next != len(day) {
+			t.Errorf("%s: %q is missing or out of order", tt.name, day[next])
+		}
+		if !strings.Contains(pages[len(pages)-1].text, "Last stop") {
+			t.Errorf("%s: the second day is not on the last page", tt.name)
+		}
+	}
+}
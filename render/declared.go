@@ -0,0 +1,277 @@
+package render
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+)
+
+// declaredSection draws a section a template declares with blocks.
+type declaredSection struct {
+	spec *TemplateSection
+}
+
+func (s declaredSection) Title() string { return s.spec.Title }
+
+func (s declaredSection) Render(ctx *Context) error {
+	d, err := s.declared(ctx)
+	if err != nil {
+		return err
+	}
+	if s.spec.Page == "continue" {
+		ctx.Continue(10, 40)
+	} else {
+		ctx.NewPage()
+	}
+	if s.spec.Title != "" {
+		ctx.Heading(s.spec.Title, 15)
+	}
+	for _, b := range d.Blocks {
+		drawBlock(ctx, b)
+	}
+	return nil
+}
+
+func (s declaredSection) Describe(ctx *Context) (Content, error) {
+	return s.declared(ctx)
+}
+
+// Declared is a template-declared section with its bindings executed.
+type Declared struct {
+	Blocks []DeclaredBlock
+}
+
+func (*Declared) content() {}
+
+// DeclaredBlock is one block: a page break, space in mm, a table, or
+// Markdown text in the given style, size, L/C/R alignment and colour.
+type DeclaredBlock struct {
+	PageBreak bool
+	Space     float64
+	Table     *TableContent
+
+	Text    string
+	Heading bool
+	Style   string
+	Size    float64
+	Align   string
+	Color   theme.Color
+}
+
+func (s declaredSection) declared(ctx *Context) (*Declared, error) {
+	d := &Declared{}
+	for i := range s.spec.Blocks {
+		b, err := declaredBlock(ctx, &s.spec.Blocks[i])
+		if err != nil {
+			return nil, fmt.Errorf("section %s: %w", s.spec.Name, err)
+		}
+		// Tables without rows are left out.
+		if b.Table != nil && len(b.Table.Rows) == 0 {
+			continue
+		}
+		d.Blocks = append(d.Blocks, b)
+	}
+	return d, nil
+}
+
+// templateFuncs are the functions bindings can call, formatting for l.
+func templateFuncs(l *i18n.Locale) template.FuncMap {
+	return template.FuncMap{
+		"t":        l.T,
+		"date":     l.FormatDate,
+		"duration": l.FormatDuration,
+		"number":   l.FormatNumber,
+		"amount": func(v float64) string {
+			return l.T("Rs. %s", l.FormatNumber(v, 0))
+		},
+		"nights": utils.CalculateNights,
+		"upper":  strings.ToUpper,
+	}
+}
+
+func (ctx *Context) blockColor(name string) theme.Color {
+	p := ctx.Theme.Palette
+	switch name {
+	case "muted":
+		return p.Muted
+	case "primary":
+		return p.Primary
+	case "accent":
+		return p.Accent
+	}
+	return p.Text
+}
+
+// declaredBlock executes a block's bindings and fills in its defaults.
+func declaredBlock(ctx *Context, b *Block) (DeclaredBlock, error) {
+	switch {
+	case b.PageBreak:
+		return DeclaredBlock{PageBreak: true}, nil
+	case b.Space > 0:
+		return DeclaredBlock{Space: b.Space}, nil
+	case b.Table != nil:
+		t, err := declaredTable(ctx, b.Table)
+		return DeclaredBlock{Table: t}, err
+	}
+
+	text := ctx.T(b.Heading)
+	if b.Text != "" {
+		var err error
+		if text, err = execute(ctx, b.text, ctx.Data); err != nil {
+			return DeclaredBlock{}, err
+		}
+	}
+	size, style := b.Size, b.Style
+	if size == 0 {
+		size = 10
+	}
+	if b.Heading != "" && style == "" {
+		style = "B"
+		if b.Size == 0 {
+			size = 13
+		}
+	}
+	align := b.Align
+	if align == "" {
+		align = "L"
+	}
+	return DeclaredBlock{
+		Text:    text,
+		Heading: b.Heading != "",
+		Style:   style,
+		Size:    size,
+		Align:   align,
+		Color:   ctx.blockColor(b.Color),
+	}, nil
+}
+
+func drawBlock(ctx *Context, b DeclaredBlock) {
+	switch {
+	case b.PageBreak:
+		ctx.NewPage()
+	case b.Space > 0:
+		ctx.PDF.Ln(b.Space)
+	case b.Table != nil:
+		drawTable(ctx, b.Table)
+	default:
+		drawText(ctx, b.Text, b.Style, b.Size, b.Align, b.Color)
+	}
+}
+
+// drawText writes wrapped Markdown at the cursor, moving to a new page when
+// a line does not fit.
+func drawText(ctx *Context, text, style string, size float64, align string, color theme.Color) {
+	pdf := ctx.PDF
+	lineHeight := size * 0.5
+	setStyle := func() {
+		ctx.TextColor(color)
+		ctx.Font(style, size)
+	}
+	setStyle()
+	for _, line := range ctx.RichLines(text, ctx.ContentWidth()) {
+		if pdf.GetY()+lineHeight > ctx.ContentBottom() {
+			ctx.NewPage()
+			setStyle()
+		}
+		ctx.DrawRichLine(line, ctx.Left(), pdf.GetY(), ctx.ContentWidth(), lineHeight, align)
+		pdf.SetY(pdf.GetY() + lineHeight)
+	}
+	pdf.Ln(2)
+}
+
+// declaredTable binds a table's rows and executes its cells.
+func declaredTable(ctx *Context, t *Table) (*TableContent, error) {
+	table := &TableContent{}
+	for _, c := range t.Columns {
+		table.Headers = append(table.Headers, ctx.T(c.Header))
+		table.Weights = append(table.Weights, c.Width)
+		align := c.Align
+		if align == "" {
+			align = "C"
+		}
+		table.Align += align
+	}
+	for _, row := range rowsOf(reflect.ValueOf(ctx.Data), t.path) {
+		cells := make([]Cell, len(t.Columns))
+		for i, c := range t.Columns {
+			var err error
+			if cells[i].Text, err = execute(ctx, c.value, row.Interface()); err != nil {
+				return nil, err
+			}
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+	return table, nil
+}
+
+// drawTable draws a bound table in the style of the built-in ones: an
+// accent header, repeated on every page, over striped rows that grow to fit
+// wrapped cells.
+func drawTable(ctx *Context, t *TableContent) {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	widths := ctx.Columns(t.Weights...)
+
+	const headerHeight = 8.0
+	const lineHeight = 5.0
+	const padding = 3.0
+	const minRowHeight = 10.0
+
+	drawHeader := func() {
+		ctx.Fill(p.Accent)
+		ctx.TextColor(p.OnPrimary)
+		ctx.Font("B", 10)
+		x := ctx.Left()
+		for i, header := range t.Headers {
+			pdf.SetXY(x, pdf.GetY())
+			ctx.CellFormat(widths[i], headerHeight, header, "1", 0, "C", true, 0, "")
+			x += widths[i]
+		}
+		pdf.Ln(headerHeight)
+		ctx.TextColor(p.Text)
+		ctx.Font("", 9)
+	}
+	if pdf.GetY()+headerHeight+minRowHeight > ctx.ContentBottom() {
+		ctx.NewPage()
+	}
+	drawHeader()
+
+	for r, cells := range t.Rows {
+		lines := make([][]string, len(cells))
+		rowHeight := minRowHeight
+		for i, cell := range cells {
+			lines[i] = ctx.SplitLines(cell.Text, widths[i]-2*padding)
+			rowHeight = max(rowHeight, float64(len(lines[i]))*lineHeight+2*padding)
+		}
+
+		y := pdf.GetY()
+		if y+rowHeight > ctx.ContentBottom() {
+			ctx.NewPage()
+			drawHeader()
+			y = pdf.GetY()
+		}
+
+		if r%2 == 0 {
+			ctx.Fill(p.Surface)
+		} else {
+			ctx.Fill(p.Background)
+		}
+		ctx.Stroke(p.Border)
+		x := ctx.Left()
+		for i := range cells {
+			pdf.Rect(x, y, widths[i], rowHeight, "FD")
+			textY := y + (rowHeight-float64(len(lines[i]))*lineHeight)/2
+			for j, line := range lines[i] {
+				pdf.SetXY(x+padding, textY+float64(j)*lineHeight)
+				ctx.CellFormat(widths[i]-2*padding, lineHeight, line, "", 0, t.ColumnAlign(i), false, 0, "")
+			}
+			x += widths[i]
+		}
+		pdf.SetXY(ctx.Left(), y+rowHeight)
+	}
+	pdf.Ln(4)
+}
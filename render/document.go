@@ -0,0 +1,205 @@
+package render
+
+import (
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// Document is an itinerary's content without its layout: the text, tables,
+// pictures and links of each section, translated and formatted for the
+// booking's locale. The PDF sections draw from the same values, so a format
+// rendered from a Document never says anything the PDF does not.
+type Document struct {
+	// Lang is the tag of the booking's locale.
+	Lang  string
+	Title string
+	Theme theme.Theme
+	// Status is the badge of quotes, provisional and cancelled itineraries,
+	// shown in StatusColor, and ValidUntil the line a quote has under it.
+	// Both are empty for final itineraries.
+	Status      string
+	StatusColor theme.Color
+	ValidUntil  string
+	// Locale translates the labels a renderer adds of its own.
+	Locale   *i18n.Locale
+	Sections []DocumentSection
+}
+
+// DocumentSection is one section of a Document.
+type DocumentSection struct {
+	// Name is the section's name in templates and options.sections.
+	Name string
+	// Title is the translated heading, empty for sections without one.
+	Title   string
+	Content Content
+}
+
+// Content is what a section shows: a *Cover, *Contents, *TripRoute,
+// *DailyPlan, *FlightSummary, *TableContent, *PaymentPlan, *Facts, *Link,
+// *Closing, *Calendar, *Timeline, *Summary or *Declared.
+type Content interface {
+	content()
+}
+
+// Describer is implemented by sections that can say what they show without
+// drawing it. Sections that do not are left out of a Document.
+type Describer interface {
+	Describe(ctx *Context) (Content, error)
+}
+
+// described adds the content function NewDocument calls to a built-in
+// section, whose Render draws what the same function returns. A nil result
+// leaves the section out, as drawing nothing does in the PDF.
+func described[C interface {
+	Content
+	comparable
+}](section Section, describe func(ctx *Context) C) Section {
+	return describedSection{Section: section, describe: func(ctx *Context) (Content, error) {
+		var none C
+		if c := describe(ctx); c != none {
+			return c, nil
+		}
+		return nil, nil
+	}}
+}
+
+type describedSection struct {
+	Section
+	describe func(ctx *Context) (Content, error)
+}
+
+func (s describedSection) Describe(ctx *Context) (Content, error) { return s.describe(ctx) }
+
+// Title keeps the wrapped section's entry in the table of contents.
+func (s describedSection) Title() string { return sectionTitle(s.Section) }
+
+func sectionTitle(section Section) string {
+	if titled, ok := section.(Titled); ok {
+		return titled.Title()
+	}
+	return ""
+}
+
+// NewDocument collects the content of the sections Build would draw for
+// data.
+func NewDocument(data types.BookingData, cfg Config) (*Document, error) {
+	cfg, names, sections, err := plan(data, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := newContext(data, cfg)
+	ctx.sections = names
+	ctx.created = documentDate(data.Options)
+
+	doc := &Document{
+		Lang:        ctx.Locale.Tag,
+		Title:       ctx.T("%s Itinerary", data.Destination),
+		Theme:       cfg.Theme,
+		Status:      statusLabel(ctx.Locale, data.Status),
+		StatusColor: ctx.statusColor(),
+		Locale:      ctx.Locale,
+	}
+	if until, ok := ctx.quoteValidUntil(); ok {
+		doc.ValidUntil = ctx.T("Valid until %s", until)
+	}
+
+	for i, section := range sections {
+		describer, ok := section.(Describer)
+		if !ok {
+			continue
+		}
+		content, err := describer.Describe(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if content == nil {
+			continue
+		}
+		s := DocumentSection{Name: names[i], Content: content}
+		if title := sectionTitle(section); title != "" {
+			s.Title = ctx.T(title)
+		}
+		doc.Sections = append(doc.Sections, s)
+	}
+	return doc, nil
+}
+
+// Fact is a labelled value, like a row of the trip details.
+type Fact struct {
+	Label, Value string
+}
+
+// TableContent is rows of cells under a header. Weights are the relative
+// widths of the columns and Align has one of L, C or R per column, centring
+// the columns it leaves out.
+type TableContent struct {
+	Headers []string
+	Weights []float64
+	Align   string
+	Rows    [][]Cell
+}
+
+func (*TableContent) content() {}
+
+// ColumnAlign is the alignment of column i.
+func (t *TableContent) ColumnAlign(i int) string {
+	if i < len(t.Align) {
+		return t.Align[i : i+1]
+	}
+	return "C"
+}
+
+// imageColumn is the first column with a picture in any row, or -1.
+func (t *TableContent) imageColumn() int {
+	for _, row := range t.Rows {
+		for i, cell := range row {
+			if len(cell.Image) > 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Cell is the text of a table cell, or a picture when Image is set.
+type Cell struct {
+	Text string
+	// Markdown is set when Text may use the Markdown subset.
+	Markdown bool
+	Image    types.Image
+}
+
+// Facts is a card of labelled values.
+type Facts struct {
+	Rows []Fact
+}
+
+func (*Facts) content() {}
+
+// Link is a single link, Text leading to URL.
+type Link struct {
+	Text, URL string
+}
+
+func (*Link) content() {}
+
+// LegendKind is how a legend entry shows its colour.
+type LegendKind int
+
+const (
+	LegendFill LegendKind = iota
+	LegendOutline
+	LegendDot
+	LegendArrow
+	LegendLine
+	LegendDash
+)
+
+// LegendItem explains one mark of a chart.
+type LegendItem struct {
+	Kind  LegendKind
+	Color theme.Color
+	Label string
+}
@@ -0,0 +1,451 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/monoMonu/travel-itinerary-pdf/markdown"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+)
+
+// The trip timeline is a Gantt chart: one column per date, hotel stays as
+// bars, flights and transfers as markers, and a row of nights that shows at
+// a glance where the travellers have no bed or two.
+
+const (
+	ganttLabelWidth = 38.0
+	ganttAxisHeight = 10.0
+	ganttRowHeight  = 8.0
+	ganttFontSize   = 6.0
+	// ganttLabelSpacing is the least room a date label needs on the axis.
+	ganttLabelSpacing = 6.0
+)
+
+// ganttAxis maps times onto the chart.
+type ganttAxis struct {
+	first    time.Time
+	days     int
+	left     float64
+	dayWidth float64
+}
+
+func (a ganttAxis) at(t time.Time) float64 {
+	return a.left + t.Sub(a.first).Hours()/24*a.dayWidth
+}
+
+func (a ganttAxis) right() float64 {
+	return a.left + float64(a.days)*a.dayWidth
+}
+
+// Timeline is the trip as a Gantt chart: a column per date from First and
+// a row per band of marks.
+type Timeline struct {
+	First  time.Time
+	Days   int
+	Rows   []TimelineRow
+	Legend []LegendItem
+}
+
+func (*Timeline) content() {}
+
+// TimelineRow is one labelled band of the chart.
+type TimelineRow struct {
+	Label, Sublabel string
+	Marks           []TimelineMark
+}
+
+// TimelineMark is something on the chart between From and To. Flights
+// without an arrival time and transfers are points, with a zero To.
+type TimelineMark struct {
+	Kind     MarkKind
+	From, To time.Time
+	Label    string
+}
+
+type MarkKind int
+
+const (
+	MarkFlight MarkKind = iota
+	MarkTransfer
+	// MarkStay is a hotel stay, from check-in to check-out, and
+	// MarkStayOverlap a night of it shared with another stay.
+	MarkStay
+	MarkStayOverlap
+	// The night marks cover a whole date: a night at a hotel, on a flight,
+	// at two hotels or at none.
+	MarkNight
+	MarkAloft
+	MarkOverlap
+	MarkGap
+	MarkActivity
+)
+
+func tripTimeline(ctx *Context) *Timeline {
+	p := ctx.Theme.Palette
+	s := planSchedule(ctx.Data)
+	if s.empty() {
+		return nil
+	}
+
+	t := &Timeline{First: s.first, Days: s.dayCount(), Legend: []LegendItem{
+		{Kind: LegendFill, Color: p.Accent, Label: ctx.T("Hotel")},
+		{Kind: LegendDot, Color: p.Primary, Label: ctx.T("Flight")},
+		{Kind: LegendArrow, Color: p.Text, Label: ctx.T("Transfer")},
+		{Kind: LegendOutline, Color: cancelledColor, Label: ctx.T("No hotel")},
+		{Kind: LegendFill, Color: cancelledColor, Label: ctx.T("Overlap")},
+	}}
+
+	if len(s.flights) > 0 {
+		row := TimelineRow{Label: ctx.T("Flights")}
+		for _, f := range s.flights {
+			row.Marks = append(row.Marks, TimelineMark{Kind: MarkFlight, From: f.departs, To: f.arrives, Label: f.flight.From + " → " + f.flight.To})
+		}
+		t.Rows = append(t.Rows, row)
+	}
+
+	for _, stay := range s.stays {
+		nights := int(stay.out.Sub(stay.in).Hours() / 24)
+		text := ctx.T("%d Nights", nights)
+		if nights == 1 {
+			text = ctx.T("1 Night")
+		}
+		row := TimelineRow{Label: stay.hotel.Name, Sublabel: stay.hotel.City, Marks: []TimelineMark{
+			{Kind: MarkStay, From: atHour(stay.in, checkInHour), To: atHour(stay.out, checkOutHour), Label: text},
+		}}
+		for date := stay.in; date.Before(stay.out); date = date.AddDate(0, 0, 1) {
+			if d := s.days[date]; d != nil && d.overlap() {
+				row.Marks = append(row.Marks, TimelineMark{Kind: MarkStayOverlap, From: atHour(date, checkInHour), To: atHour(date.AddDate(0, 0, 1), checkOutHour)})
+			}
+		}
+		t.Rows = append(t.Rows, row)
+	}
+
+	nights := TimelineRow{Label: ctx.T("Nights")}
+	for i := range t.Days {
+		date := s.first.AddDate(0, 0, i)
+		d := s.days[date]
+		if d == nil || (!d.night && len(d.hotels) == 0) {
+			continue
+		}
+		mark := TimelineMark{Kind: MarkNight, From: date, To: date.AddDate(0, 0, 1)}
+		switch {
+		case d.overlap():
+			mark.Kind, mark.Label = MarkOverlap, ctx.T("Overlap")
+		case d.gap():
+			mark.Kind, mark.Label = MarkGap, ctx.T("No hotel")
+		case len(d.hotels) == 0:
+			mark.Kind = MarkAloft
+		}
+		nights.Marks = append(nights.Marks, mark)
+	}
+	t.Rows = append(t.Rows, nights)
+
+	if len(s.transfers) > 0 {
+		row := TimelineRow{Label: ctx.T("Transfers")}
+		for _, tr := range s.transfers {
+			row.Marks = append(row.Marks, TimelineMark{Kind: MarkTransfer, From: tr.at, Label: tr.from + " → " + tr.to})
+		}
+		t.Rows = append(t.Rows, row)
+	}
+
+	activities := TimelineRow{Label: ctx.T("Activities")}
+	for i := range t.Days {
+		date := s.first.AddDate(0, 0, i)
+		d := s.days[date]
+		if d == nil {
+			continue
+		}
+		for j, a := range d.activities {
+			// Activities without a clock time are spread over the day.
+			hour, ok := clockHours(a.Time)
+			if !ok {
+				hour = 9 + 3*float64(j)
+			}
+			from := atHour(date, hour)
+			activities.Marks = append(activities.Marks, TimelineMark{
+				Kind:  MarkActivity,
+				From:  from,
+				To:    from.Add(time.Duration(a.Duration) * time.Minute),
+				Label: markdown.PlainText(a.Title),
+			})
+		}
+	}
+	t.Rows = append(t.Rows, activities)
+	return t
+}
+
+func addGantt(ctx *Context) error {
+	pdf := ctx.PDF
+	t := tripTimeline(ctx)
+	if t == nil {
+		return nil
+	}
+
+	ctx.NewPage()
+	ctx.Heading("Trip Timeline", 12)
+
+	axis := ganttAxis{first: t.First, days: t.Days, left: ctx.Left() + ganttLabelWidth}
+	axis.dayWidth = (ctx.Right() - axis.left) / float64(axis.days)
+
+	drawGanttAxis(ctx, axis)
+	for i, row := range t.Rows {
+		y := pdf.GetY()
+		if y+ganttRowHeight > ctx.ContentBottom() {
+			ctx.NewPage()
+			drawGanttAxis(ctx, axis)
+			y = pdf.GetY()
+		}
+		drawGanttRow(ctx, axis, row, y, i)
+		pdf.SetY(y + ganttRowHeight)
+	}
+
+	pdf.Ln(6)
+	drawLegend(ctx, t.Legend)
+	return nil
+}
+
+// drawGanttAxis draws the date header: the month where it starts and
+// changes, and the day of the month, thinned out when columns are narrow.
+func drawGanttAxis(ctx *Context, axis ganttAxis) {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	y := pdf.GetY()
+	step := int(math.Ceil(ganttLabelSpacing / axis.dayWidth))
+
+	ctx.Font("", 7)
+	for i := range axis.days {
+		date := axis.first.AddDate(0, 0, i)
+		x := axis.at(date)
+		if i == 0 || date.Day() == 1 {
+			ctx.TextColor(p.Muted)
+			ctx.Font("B", 7)
+			pdf.SetXY(x, y)
+			ctx.CellFormat(4*ganttLabelSpacing, 4, fmt.Sprintf("%s %d", ctx.Locale.Months[date.Month()-1], date.Year()), "", 0, "L", false, 0, "")
+			ctx.Font("", 7)
+		}
+		if i%step == 0 {
+			ctx.TextColor(p.Text)
+			pdf.SetXY(x, y+4)
+			ctx.CellFormat(axis.dayWidth*float64(min(step, axis.days-i)), 5, fmt.Sprint(date.Day()), "", 0, "L", false, 0, "")
+		}
+	}
+	ctx.Stroke(p.Rule)
+	pdf.Line(ctx.Left(), y+ganttAxisHeight-0.5, axis.right(), y+ganttAxisHeight-0.5)
+	pdf.SetY(y + ganttAxisHeight)
+}
+
+// drawGanttRow draws the row label, the day grid with weekends shaded and
+// then the row's own content.
+func drawGanttRow(ctx *Context, axis ganttAxis, row TimelineRow, y float64, index int) {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+
+	if index%2 == 0 {
+		ctx.Fill(p.Surface)
+		pdf.Rect(ctx.Left(), y, axis.right()-ctx.Left(), ganttRowHeight, "F")
+	}
+	ctx.Stroke(p.Border)
+	for i := range axis.days + 1 {
+		x := axis.left + float64(i)*axis.dayWidth
+		if i < axis.days {
+			if wd := axis.first.AddDate(0, 0, i).Weekday(); wd == time.Saturday || wd == time.Sunday {
+				ctx.Fill(p.Border)
+				pdf.Rect(x, y, axis.dayWidth, ganttRowHeight, "F")
+			}
+		}
+		pdf.Line(x, y, x, y+ganttRowHeight)
+	}
+
+	ctx.TextColor(p.Text)
+	ctx.Font("B", 7)
+	labelY, labelH := y, ganttRowHeight
+	if row.Sublabel != "" {
+		labelH = ganttRowHeight / 2
+	}
+	pdf.SetXY(ctx.Left(), labelY)
+	ctx.CellFormat(ganttLabelWidth, labelH, ctx.truncate(row.Label, ganttLabelWidth), "", 0, "L", false, 0, "")
+	if row.Sublabel != "" {
+		ctx.TextColor(p.Muted)
+		ctx.Font("", ganttFontSize)
+		pdf.SetXY(ctx.Left(), y+labelH-0.5)
+		ctx.CellFormat(ganttLabelWidth, labelH, ctx.truncate(row.Sublabel, ganttLabelWidth), "", 0, "L", false, 0, "")
+	}
+
+	drawGanttMarks(ctx, axis, row.Marks, y)
+}
+
+// drawGanttMarks draws the marks of a row, with the labels of flights and
+// transfers above them.
+func drawGanttMarks(ctx *Context, axis ganttAxis, marks []TimelineMark, y float64) {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	var xs []float64
+	var labels []string
+	labelColor := p.Text
+	var x1, x2 float64 // the stay bar, for its overlaps
+	barY, barH := y+1.5, ganttRowHeight-3
+	// Rows hold marks of one kind, or a stay and its overlaps, so the
+	// first mark sets what the rest share.
+	for i, m := range marks {
+		switch m.Kind {
+		case MarkFlight:
+			from, mid := axis.at(m.From), y+ganttRowHeight*0.65
+			ctx.Fill(p.Primary)
+			ctx.Stroke(p.Primary)
+			if !m.To.IsZero() {
+				to := axis.at(m.To)
+				pdf.SetLineWidth(0.6)
+				pdf.Line(from, mid, to, mid)
+				pdf.SetLineWidth(0.2)
+				pdf.Circle(to, mid, 0.8, "F")
+			}
+			pdf.Circle(from, mid, 0.8, "F")
+			xs, labels, labelColor = append(xs, from), append(labels, m.Label), p.Primary
+		case MarkTransfer:
+			x := axis.at(m.From)
+			ganttArrow(ctx, x, y+ganttRowHeight*0.65, p.Text)
+			xs, labels = append(xs, x), append(labels, m.Label)
+		case MarkStay:
+			x1 = axis.at(m.From)
+			x2 = max(x1+1, axis.at(m.To))
+			ctx.Fill(p.Accent)
+			pdf.RoundedRect(x1, barY, x2-x1, barH, min(ctx.Radius(0.6), barH/2), "1234", "F")
+			// Nights shared with another stay are marked on the bar.
+			ctx.Fill(cancelledColor)
+		case MarkStayOverlap:
+			from := max(x1, axis.at(m.From))
+			to := min(x2, axis.at(m.To))
+			pdf.Rect(from, barY, to-from, barH, "F")
+		case MarkNight, MarkAloft, MarkOverlap, MarkGap:
+			if i == 0 {
+				ctx.Font("B", ganttFontSize)
+			}
+			x, w := axis.at(m.From)+0.3, axis.dayWidth-0.6
+			switch m.Kind {
+			case MarkOverlap:
+				ctx.Fill(cancelledColor)
+				pdf.Rect(x, barY, w, barH, "F")
+				ctx.TextColor(p.OnPrimary)
+			case MarkGap:
+				ctx.Stroke(cancelledColor)
+				pdf.SetLineWidth(0.5)
+				pdf.Rect(x, barY, w, barH, "D")
+				pdf.SetLineWidth(0.2)
+				ctx.TextColor(cancelledColor)
+			case MarkAloft:
+				ctx.Fill(p.Primary)
+				pdf.Rect(x, barY, w, barH, "F")
+			default:
+				ctx.Fill(p.Accent)
+				pdf.Rect(x, barY, w, barH, "F")
+			}
+			if m.Label != "" && ctx.StringWidth(m.Label)+1 < w {
+				pdf.SetXY(x, barY)
+				ctx.CellFormat(w, barH, m.Label, "", 0, "C", false, 0, "")
+			}
+		case MarkActivity:
+			if i == 0 {
+				ctx.Fill(p.Muted)
+			}
+			x := axis.at(m.From)
+			w := max(0.6, m.To.Sub(m.From).Hours()/24*axis.dayWidth)
+			pdf.Rect(x, y+2, min(w, axis.right()-x), ganttRowHeight-4, "F")
+		}
+	}
+
+	// The nights of a stay go on its bar when they fit, over any overlaps.
+	for _, m := range marks {
+		if m.Kind != MarkStay {
+			continue
+		}
+		ctx.Font("B", ganttFontSize)
+		if ctx.StringWidth(m.Label)+2 < x2-x1 {
+			ctx.TextColor(p.OnPrimary)
+			pdf.SetXY(x1, barY)
+			ctx.CellFormat(x2-x1, barH, m.Label, "", 0, "C", false, 0, "")
+		}
+	}
+	if len(xs) > 0 {
+		ganttLabels(ctx, xs, labels, y, axis, labelColor)
+	}
+}
+
+// ganttLabels writes the labels of a row of markers above them. A label
+// starts at its marker when there is room before the next one, ends at it
+// when there is more room behind, and is cut short otherwise.
+func ganttLabels(ctx *Context, xs []float64, labels []string, y float64, axis ganttAxis, color theme.Color) {
+	pdf := ctx.PDF
+	ctx.TextColor(color)
+	ctx.Font("", ganttFontSize)
+	margin := pdf.GetCellMargin()
+	used := axis.left
+	for i, x := range xs {
+		ahead := axis.right() - x
+		if i+1 < len(xs) {
+			ahead = xs[i+1] - x
+		}
+		behind := x - used
+		need := ctx.StringWidth(labels[i]) + 2*margin
+
+		from, w := x-margin, ahead
+		if need > ahead && behind > ahead {
+			w = min(need, behind)
+			from = x + margin - w
+		}
+		w = min(w, need)
+		if w < ganttLabelSpacing {
+			continue
+		}
+		pdf.SetXY(from, y)
+		ctx.CellFormat(w, 3.5, ctx.truncate(labels[i], w), "", 0, "L", false, 0, "")
+		used = from + w
+	}
+}
+
+// ganttArrow draws a small right-pointing arrow ending at x, y.
+func ganttArrow(ctx *Context, x, y float64, color theme.Color) {
+	pdf := ctx.PDF
+	ctx.Stroke(color)
+	ctx.Fill(color)
+	pdf.SetLineWidth(0.4)
+	pdf.Line(x-2.5, y, x-0.8, y)
+	pdf.SetLineWidth(0.2)
+	pdf.Polygon([]gofpdf.PointType{{X: x, Y: y}, {X: x - 1.2, Y: y - 0.8}, {X: x - 1.2, Y: y + 0.8}}, "F")
+}
+
+// drawLegend writes swatches and labels at the cursor, wrapping at the
+// right margin.
+func drawLegend(ctx *Context, items []LegendItem) {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	if pdf.GetY()+5 > ctx.ContentBottom() {
+		ctx.NewPage()
+	}
+	x, y := ctx.Left(), pdf.GetY()
+	ctx.Font("", 8)
+	for _, item := range items {
+		w := ctx.StringWidth(item.Label) + 2*pdf.GetCellMargin()
+		if x > ctx.Left() && x+6+w > ctx.Right() {
+			x, y = ctx.Left(), y+6
+		}
+		switch item.Kind {
+		case LegendFill:
+			ctx.Fill(item.Color)
+			ctx.Stroke(p.Border)
+			pdf.Rect(x, y+0.5, 5, 3, "FD")
+		case LegendOutline:
+			ctx.Stroke(item.Color)
+			pdf.SetLineWidth(0.5)
+			pdf.Rect(x, y+0.5, 5, 3, "D")
+			pdf.SetLineWidth(0.2)
+		case LegendDot:
+			ctx.Fill(item.Color)
+			pdf.Circle(x+2.5, y+2, 0.9, "F")
+		case LegendArrow:
+			ganttArrow(ctx, x+4, y+2, item.Color)
+		}
+		ctx.TextColor(p.Muted)
+		pdf.SetXY(x+6, y)
+		ctx.CellFormat(w, 4, item.Label, "", 0, "L", false, 0, "")
+		x += 6 + w + 5
+	}
+	pdf.SetY(y + 6)
+}
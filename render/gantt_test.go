@@ -0,0 +1,111 @@
+package render
+
+import (
+	"testing"
+	"time"
+
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// timelineRow returns the row of a timeline with the label, or fails.
+func timelineRow(t *testing.T, tl *Timeline, label string) TimelineRow {
+	t.Helper()
+	for _, row := range tl.Rows {
+		if row.Label == label {
+			return row
+		}
+	}
+	t.Fatalf("no %q row", label)
+	return TimelineRow{}
+}
+
+func checkMarks(t *testing.T, name string, got, want []TimelineMark) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("%s: got %d marks, want %d: %+v", name, len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Kind != w.Kind || !g.From.Equal(w.From) || !g.To.Equal(w.To) || g.Label != w.Label {
+			t.Errorf("%s: mark %d is %v %s to %s %q, want %v %s to %s %q", name, i,
+				g.Kind, g.From.Format(time.DateTime), g.To.Format(time.DateTime), g.Label,
+				w.Kind, w.From.Format(time.DateTime), w.To.Format(time.DateTime), w.Label)
+		}
+	}
+}
+
+func TestTimelineOverlappingStays(t *testing.T) {
+	data := scheduleTrip("2025-06-15", "2025-06-19",
+		[3]string{"Raffles", "2025-06-15", "2025-06-17"},
+		[3]string{"Fullerton", "2025-06-16", "2025-06-18"},
+	)
+	tl := tripTimeline(&Context{Data: data, Locale: i18n.Get("")})
+	if !tl.First.Equal(mustDay("2025-06-15")) || tl.Days != 5 {
+		t.Fatalf("timeline is %d days from %s", tl.Days, tl.First.Format(time.DateOnly))
+	}
+
+	at := func(date string, hour float64) time.Time { return atHour(mustDay(date), hour) }
+	checkMarks(t, "Raffles", timelineRow(t, tl, "Raffles").Marks, []TimelineMark{
+		{Kind: MarkStay, From: at("2025-06-15", 14), To: at("2025-06-17", 11), Label: "2 Nights"},
+		{Kind: MarkStayOverlap, From: at("2025-06-16", 14), To: at("2025-06-17", 11)},
+	})
+	checkMarks(t, "Fullerton", timelineRow(t, tl, "Fullerton").Marks, []TimelineMark{
+		{Kind: MarkStay, From: at("2025-06-16", 14), To: at("2025-06-18", 11), Label: "2 Nights"},
+		{Kind: MarkStayOverlap, From: at("2025-06-16", 14), To: at("2025-06-17", 11)},
+	})
+	// The return day has no night, so the row stops on the 18th.
+	checkMarks(t, "Nights", timelineRow(t, tl, "Nights").Marks, []TimelineMark{
+		{Kind: MarkNight, From: mustDay("2025-06-15"), To: mustDay("2025-06-16")},
+		{Kind: MarkOverlap, From: mustDay("2025-06-16"), To: mustDay("2025-06-17"), Label: "Overlap"},
+		{Kind: MarkNight, From: mustDay("2025-06-17"), To: mustDay("2025-06-18")},
+		{Kind: MarkGap, From: mustDay("2025-06-18"), To: mustDay("2025-06-19"), Label: "No hotel"},
+	})
+}
+
+// A red-eye arrives after midnight: its night is spent aloft, and the
+// transfer to the hotel follows the arrival.
+func TestTimelineRedEye(t *testing.T) {
+	data := scheduleTrip("2025-06-15", "2025-06-17", [3]string{"Marina Bay Sands", "2025-06-16", "2025-06-17"})
+	data.Flights = []types.Flight{{Date: "2025-06-15", From: "DEL", To: "SIN", Departure: "23:00", Arrival: "07:10"}}
+	tl := tripTimeline(&Context{Data: data, Locale: i18n.Get("")})
+	if tl.Days != 3 {
+		t.Fatalf("timeline is %d days", tl.Days)
+	}
+
+	at := func(date string, hour float64) time.Time { return atHour(mustDay(date), hour) }
+	checkMarks(t, "Flights", timelineRow(t, tl, "Flights").Marks, []TimelineMark{
+		{Kind: MarkFlight, From: at("2025-06-15", 23), To: mustDay("2025-06-16").Add(7*time.Hour + 10*time.Minute), Label: "DEL → SIN"},
+	})
+	checkMarks(t, "Nights", timelineRow(t, tl, "Nights").Marks, []TimelineMark{
+		{Kind: MarkAloft, From: mustDay("2025-06-15"), To: mustDay("2025-06-16")},
+		{Kind: MarkNight, From: mustDay("2025-06-16"), To: mustDay("2025-06-17")},
+	})
+	checkMarks(t, "Transfers", timelineRow(t, tl, "Transfers").Marks, []TimelineMark{
+		{Kind: MarkTransfer, From: mustDay("2025-06-16").Add(8*time.Hour + 10*time.Minute), Label: "SIN → Marina Bay Sands"},
+	})
+}
+
+func TestTimelineSingleDay(t *testing.T) {
+	data := scheduleTrip("2025-03-05", "2025-03-05")
+	data.Days[0].Activities[0].Duration = 90
+	data.Flights = []types.Flight{{Date: "2025-03-05", From: "DEL", To: "BOM", Departure: "08:00", Arrival: "10:10"}}
+	tl := tripTimeline(&Context{Data: data, Locale: i18n.Get("")})
+	if !tl.First.Equal(mustDay("2025-03-05")) || tl.Days != 1 {
+		t.Fatalf("timeline is %d days from %s", tl.Days, tl.First.Format(time.DateOnly))
+	}
+
+	at := func(hour float64) time.Time { return atHour(mustDay("2025-03-05"), hour) }
+	checkMarks(t, "Flights", timelineRow(t, tl, "Flights").Marks, []TimelineMark{
+		{Kind: MarkFlight, From: at(8), To: mustDay("2025-03-05").Add(10*time.Hour + 10*time.Minute), Label: "DEL → BOM"},
+	})
+	checkMarks(t, "Nights", timelineRow(t, tl, "Nights").Marks, nil)
+	checkMarks(t, "Activities", timelineRow(t, tl, "Activities").Marks, []TimelineMark{
+		{Kind: MarkActivity, From: at(10), To: at(11.5), Label: "Walk"},
+	})
+	for _, row := range tl.Rows {
+		if row.Label == "Transfers" {
+			t.Errorf("got transfers %+v without a hotel", row.Marks)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package render
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"image"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/monoMonu/travel-itinerary-pdf/imaging"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// registerImage adds img to the document the first time it is used and
+// returns its name and aspect ratio (width over height). ok is false for data
+// gofpdf cannot embed, which is then left out rather than failing the PDF.
+func (ctx *Context) registerImage(img types.Image) (name string, aspect float64, ok bool) {
+	h := fnv.New64a()
+	h.Write(img)
+	name = fmt.Sprintf("image-%x", h.Sum64())
+
+	if aspect, seen := ctx.images[name]; seen {
+		return name, aspect, aspect > 0
+	}
+	if ctx.images == nil {
+		ctx.images = map[string]float64{}
+	}
+
+	imageType := imaging.Type(img)
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(img))
+	if imageType == "" || err != nil || cfg.Height == 0 {
+		ctx.images[name] = 0
+		return name, 0, false
+	}
+	aspect = float64(cfg.Width) / float64(cfg.Height)
+	ctx.images[name] = aspect
+	ctx.PDF.RegisterImageOptionsReader(name, gofpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(img))
+	return name, aspect, true
+}
+
+// drawImageCover fills the w by h box at x, y with img, cropping whatever
+// overflows, with corners rounded by radius. It reports whether anything was
+// drawn.
+func (ctx *Context) drawImageCover(img types.Image, x, y, w, h, radius float64) bool {
+	if len(img) == 0 {
+		return false
+	}
+	name, aspect, ok := ctx.registerImage(img)
+	if !ok {
+		return false
+	}
+
+	iw, ih := w, w/aspect
+	if ih < h {
+		iw, ih = h*aspect, h
+	}
+
+	pdf := ctx.PDF
+	pdf.ClipRoundedRect(x, y, w, h, radius, false)
+	pdf.ImageOptions(name, x-(iw-w)/2, y-(ih-h)/2, iw, ih, false, gofpdf.ImageOptions{}, 0, "")
+	pdf.ClipEnd()
+	return true
+}
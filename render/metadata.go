@@ -0,0 +1,99 @@
+package render
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/pdfa"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// ReproducibleDate is the creation date of documents rendered with
+// options.deterministic, so that the same booking always gives the same
+// bytes. The server sets it from SOURCE_DATE_EPOCH.
+var ReproducibleDate = time.Unix(0, 0).UTC()
+
+const producer = "gofpdf"
+
+// documentMetadata describes the itinerary for PDF readers and search.
+func documentMetadata(data types.BookingData, th theme.Theme, l *i18n.Locale, created time.Time) pdfa.Metadata {
+	subject := l.T("Travel itinerary for %s, %s to %s", data.CustomerName,
+		l.FormatDate(data.DepartureDate), l.FormatDate(data.ReturnDate))
+	keywords := []string{l.T("itinerary"), data.Destination, data.CustomerName}
+	if data.BookingReference != "" {
+		subject += l.T(", booking %s", data.BookingReference)
+		keywords = append(keywords, data.BookingReference)
+	}
+	title := l.T("%s Itinerary - %s", data.Destination, data.CustomerName)
+	if label := statusLabel(l, data.Status); label != "" {
+		title += " (" + label + ")"
+	}
+	return pdfa.Metadata{
+		Title:    title,
+		Author:   th.Company.Name,
+		Subject:  subject,
+		Keywords: keywords,
+		Creator:  th.Company.Name,
+		Producer: producer,
+		Created:  created,
+	}
+}
+
+// setMetadata fills the info dictionary and, for archival documents, the
+// XMP packet. Deterministic documents get a pinned date and have gofpdf
+// write its resources in a fixed order.
+func setMetadata(ctx *Context) {
+	pdf, opts := ctx.PDF, ctx.Data.Options
+
+	created := documentDate(opts)
+	if opts.Deterministic {
+		pdf.SetCatalogSort(true)
+	}
+	ctx.created = created
+	meta := documentMetadata(ctx.Data, ctx.Theme, ctx.Locale, created)
+
+	pdf.SetTitle(meta.Title, true)
+	pdf.SetAuthor(meta.Author, true)
+	pdf.SetSubject(meta.Subject, true)
+	pdf.SetKeywords(strings.Join(meta.Keywords, ", "), true)
+	pdf.SetCreator(meta.Creator, true)
+	pdf.SetProducer(meta.Producer, true)
+	pdf.SetCreationDate(meta.Created)
+	pdf.SetModificationDate(meta.Created)
+	if opts.Archival {
+		pdf.SetXmpMetadata(meta.XMP())
+	}
+}
+
+// documentDate is when a document is made, or ReproducibleDate for
+// deterministic ones.
+func documentDate(opts types.Options) time.Time {
+	if opts.Deterministic {
+		return ReproducibleDate.UTC()
+	}
+	return time.Now().UTC()
+}
+
+// Write outputs a document made by Build, converted to PDF/A when the
+// booking asked for an archival copy.
+func Write(w io.Writer, pdf *gofpdf.Fpdf, opts types.Options) error {
+	if !opts.Archival {
+		return pdf.Output(w)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return err
+	}
+	doc, err := pdfa.Convert(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(doc)
+	return err
+}
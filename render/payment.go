@@ -0,0 +1,252 @@
+package render
+
+import "fmt"
+
+// PaymentPlan is the price of the trip and how it is paid.
+type PaymentPlan struct {
+	Total, TCS Fact
+	// ValidUntil says how long a quote's prices hold, and is empty for
+	// other statuses.
+	ValidUntil   string
+	Headers      []string
+	Installments []Installment
+}
+
+func (*PaymentPlan) content() {}
+
+type Installment struct {
+	Name   string
+	Amount float64
+	// Label is the amount as shown, and Due when it is due.
+	Label, Due string
+	// UPI asks for the amount in a payment app. It is set when a UPI ID is
+	// configured and there is something to pay.
+	UPI string
+}
+
+func paymentPlan(ctx *Context) *PaymentPlan {
+	data := ctx.Data
+	plan := &PaymentPlan{
+		Total:        Fact{ctx.T("Total Amount"), ctx.T("%s For %d Pax (Inclusive Of GST)", ctx.Amount(data.TotalAmount), data.Travelers)},
+		TCS:          Fact{ctx.T("TCS"), ctx.T("Not Collected")},
+		Headers:      []string{ctx.T("Installment"), ctx.T("Amount"), ctx.T("Due Date")},
+		Installments: planInstallments(ctx),
+	}
+	if until, ok := ctx.quoteValidUntil(); ok {
+		plan.ValidUntil = ctx.T("This quote is valid until %s. Prices may change after that date.", until)
+	}
+
+	if ctx.Payment.UPIID != "" {
+		plan.Headers = append(plan.Headers, ctx.T("Pay via UPI"))
+		payee := ctx.Payment.PayeeName
+		if payee == "" {
+			payee = ctx.Theme.Company.Name
+		}
+		for i, inst := range plan.Installments {
+			if inst.Amount > 0 {
+				note := fmt.Sprintf("%s - %s", inst.Name, data.Destination)
+				plan.Installments[i].UPI = upiURI(ctx.Payment.UPIID, payee, inst.Amount, note, data.BookingReference)
+			}
+		}
+	}
+	return plan
+}
+
+func addPaymentPlan(ctx *Context) error {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	plan := paymentPlan(ctx)
+
+	ctx.NewPage()
+	ctx.Heading("Payment Plan", 20)
+
+	ctx.Fill(p.Surface)
+	pdf.RoundedRect(ctx.Left(), pdf.GetY(), ctx.ContentWidth(), 15, ctx.Radius(0.6), "1234", "F")
+	pdf.SetY(pdf.GetY() + 4)
+	pdf.SetX(ctx.Left() + 10)
+	ctx.Font("B", 12)
+	ctx.Cell(min(60, ctx.ContentWidth()/3), 8, plan.Total.Label)
+	ctx.Font("", 12)
+	ctx.Cell(0, 8, plan.Total.Value)
+	pdf.Ln(20)
+
+	ctx.Fill(p.Surface)
+	pdf.RoundedRect(ctx.Left(), pdf.GetY(), ctx.ContentWidth(), 15, ctx.Radius(0.6), "1234", "F")
+	pdf.SetY(pdf.GetY() + 4)
+	pdf.SetX(ctx.Left() + 10)
+	ctx.Font("B", 12)
+	ctx.Cell(min(60, ctx.ContentWidth()/3), 8, plan.TCS.Label)
+	ctx.Font("", 12)
+	ctx.Cell(0, 8, plan.TCS.Value)
+	if plan.ValidUntil != "" {
+		pdf.Ln(14)
+		pdf.SetX(ctx.Left())
+		ctx.Font("", 9)
+		ctx.TextColor(p.Muted)
+		ctx.Cell(0, 5, plan.ValidUntil)
+		pdf.Ln(11)
+	} else {
+		pdf.Ln(25)
+	}
+
+	return drawInstallments(ctx, plan)
+}
+
+// planInstallments splits the total into the agency's three installments.
+func planInstallments(ctx *Context) []Installment {
+	data := ctx.Data
+	remaining := data.TotalAmount - data.Installment1 - data.Installment2
+	return []Installment{
+		{Name: ctx.T("Installment %d", 1), Amount: data.Installment1, Label: ctx.Amount(data.Installment1), Due: ctx.T("Initial Payment")},
+		{Name: ctx.T("Installment %d", 2), Amount: data.Installment2, Label: ctx.Amount(data.Installment2), Due: ctx.T("Post Visa Approval")},
+		{Name: ctx.T("Installment %d", 3), Amount: remaining, Label: ctx.Amount(remaining), Due: ctx.T("%d Days Before Departure", 20)},
+	}
+}
+
+// drawInstallments draws the installments table. With a UPI ID configured
+// each row gets a QR code asking for its amount, and rows grow to fit it.
+func drawInstallments(ctx *Context, plan *PaymentPlan) error {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	withQR := ctx.Payment.UPIID != ""
+
+	headers := plan.Headers
+	widths := ctx.Columns(60, 60, 60)
+	rowHeight := 10.0
+	if withQR {
+		widths = ctx.Columns(48, 42, 55, 35)
+		rowHeight = 32
+	}
+
+	drawHeader := func() {
+		ctx.Fill(p.Accent)
+		ctx.TextColor(p.OnPrimary)
+		ctx.Font("B", 10)
+		x := ctx.Left()
+		for i, header := range headers {
+			pdf.SetXY(x, pdf.GetY())
+			ctx.CellFormat(widths[i], 10, header, "1", 0, "C", true, 0, "")
+			x += widths[i]
+		}
+		pdf.Ln(10)
+		ctx.TextColor(p.Text)
+		ctx.Font("", 10)
+	}
+	drawHeader()
+
+	for i, inst := range plan.Installments {
+		if pdf.GetY()+rowHeight > ctx.ContentBottom() {
+			ctx.NewPage()
+			drawHeader()
+		}
+		if i%2 == 0 {
+			ctx.Fill(p.Surface)
+		} else {
+			ctx.Fill(p.Background)
+		}
+
+		y := pdf.GetY()
+		x := ctx.Left()
+		for j, value := range []string{inst.Name, inst.Label, inst.Due} {
+			pdf.SetXY(x, y)
+			ctx.CellFormat(widths[j], rowHeight, value, "1", 0, "C", true, 0, "")
+			x += widths[j]
+		}
+
+		if withQR {
+			pdf.SetXY(x, y)
+			ctx.CellFormat(widths[3], rowHeight, "", "1", 0, "C", true, 0, "")
+			if inst.UPI != "" {
+				size := min(rowHeight, widths[3]) - 4
+				if err := drawQR(ctx, inst.UPI, x+(widths[3]-size)/2, y+2, size); err != nil {
+					return err
+				}
+			}
+		}
+		pdf.SetXY(ctx.Left(), y+rowHeight)
+	}
+
+	return nil
+}
+
+func visaDetails(ctx *Context) *Facts {
+	return &Facts{Rows: []Fact{
+		{ctx.T("Visa Type:"), ctx.T("Tourist")},
+		{ctx.T("Validity:"), ctx.T("%d Days", 30)},
+	}}
+}
+
+func addVisaDetails(ctx *Context) error {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	rows := visaDetails(ctx).Rows
+
+	ctx.Continue(15, 60)
+	ctx.Heading("Visa Details", 15)
+
+	ctx.Fill(p.Surface)
+	pdf.RoundedRect(ctx.Left(), pdf.GetY(), ctx.ContentWidth(), float64(8*len(rows)+11), ctx.Radius(1), "1234", "F")
+
+	pdf.SetY(pdf.GetY() + 8)
+
+	// Labels get a column as wide as the longest translation.
+	ctx.Font("B", 11)
+	labelW := 40.0
+	for _, row := range rows {
+		labelW = max(labelW, ctx.StringWidth(row.Label)+3)
+	}
+	for i, row := range rows {
+		if i > 0 {
+			pdf.Ln(8)
+		}
+		pdf.SetX(ctx.Left() + 10)
+		ctx.Font("B", 11)
+		ctx.Cell(labelW, 6, row.Label)
+		ctx.Font("", 11)
+		ctx.Cell(0, 6, row.Value)
+	}
+	pdf.Ln(6)
+
+	return nil
+}
+
+// Closing is the call to book at the end of the document.
+type Closing struct {
+	Tagline, Action string
+	// URL is the checkout page the action leads to, when there is one.
+	URL string
+}
+
+func (*Closing) content() {}
+
+func closing(ctx *Context) *Closing {
+	return &Closing{Tagline: ctx.Theme.Tagline + "!", Action: ctx.T("Book Now"), URL: ctx.Links.CheckoutURL}
+}
+
+func addClosing(ctx *Context) error {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	c := closing(ctx)
+
+	ctx.Continue(14, 40)
+	ctx.Bookmark(c.Action, 0)
+	ctx.TextColor(p.Accent)
+	ctx.HeadingFont("B", 24)
+	ctx.CellFormat(0, 15, c.Tagline, "", 1, "C", false, 0, "")
+
+	rectHeight := 15.0
+	rectY := pdf.GetY() + 5
+
+	ctx.Fill(p.Accent)
+	rectX := (ctx.PageWidth() - 60) / 2
+	pdf.RoundedRect(rectX, rectY, 60, rectHeight, min(ctx.Radius(1.6), rectHeight/2), "1234", "F")
+	if c.URL != "" {
+		pdf.LinkString(rectX, rectY, 60, rectHeight, c.URL)
+	}
+
+	ctx.TextColor(p.OnPrimary)
+	ctx.Font("B", 12)
+
+	textY := rectY + (rectHeight / 2) - 4
+
+	pdf.SetY(textY)
+	ctx.CellFormat(0, 8, c.Action, "", 1, "C", false, 0, "")
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/monoMonu/travel-itinerary-pdf/config"
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+)
+
+// Every installment prints the amount its UPI code asks for.
+func TestPaymentPlanAmounts(t *testing.T) {
+	for _, locale := range i18n.Tags() {
+		data := sampleBooking()
+		data.TotalAmount, data.Installment1, data.Installment2 = 250000, 100000, 60000
+		ctx := &Context{
+			Data:    data,
+			Locale:  i18n.Get(locale),
+			Payment: config.Payment{UPIID: "vigovia@okhdfc", PayeeName: "Vigovia"},
+		}
+		plan := paymentPlan(ctx)
+		want := []float64{100000, 60000, 90000}
+		if len(plan.Installments) != len(want) {
+			t.Fatalf("%s: got %d installments", locale, len(plan.Installments))
+		}
+		for i, inst := range plan.Installments {
+			if inst.Amount != want[i] || inst.Label != ctx.Amount(want[i]) {
+				t.Errorf("%s: installment %d is %v labelled %q, want %v", locale, i+1, inst.Amount, inst.Label, want[i])
+			}
+			if am := fmt.Sprintf("am=%.2f&", want[i]); !strings.Contains(inst.UPI, am) {
+				t.Errorf("%s: installment %d UPI %q does not ask for %s", locale, i+1, inst.UPI, am)
+			}
+		}
+	}
+}
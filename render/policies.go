@@ -0,0 +1,161 @@
+package render
+
+// textTable is a policy table with its headers and text translated.
+func textTable(ctx *Context, headers []string, weights []float64, rows [][]string) *TableContent {
+	t := &TableContent{Weights: weights}
+	for _, header := range headers {
+		t.Headers = append(t.Headers, ctx.T(header))
+	}
+	for _, row := range rows {
+		cells := make([]Cell, len(row))
+		for i, text := range row {
+			cells[i] = Cell{Text: ctx.T(text)}
+		}
+		t.Rows = append(t.Rows, cells)
+	}
+	return t
+}
+
+func importantNotes(ctx *Context) *TableContent {
+	return textTable(ctx, []string{"Point", "Details"}, []float64{50, 130}, [][]string{
+		{"Airlines Standard Policy", "In Case Of Visa Rejection, Visa Fees Or Any Other Non Cancellable Component Cannot Be Reimbursed At Any Cost."},
+		{"Flight/Hotel Cancellation", "In Case Of Visa Rejection, Visa Fees Or Any Other Non Cancellable Component Cannot Be Reimbursed At Any Cost."},
+		{"Trip Insurance", "In Case Of Visa Rejection, Visa Fees Or Any Other Non Cancellable Component Cannot Be Reimbursed At Any Cost."},
+		{"Hotel Check-in & Check Out", "In Case Of Visa Rejection, Visa Fees Or Any Other Non Cancellable Component Cannot Be Reimbursed At Any Cost."},
+		{"Visa Rejection", "In Case Of Visa Rejection, Visa Fees Or Any Other Non Cancellable Component Cannot Be Reimbursed At Any Cost."},
+	})
+}
+
+func addNotesPage(ctx *Context) error {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	notes := importantNotes(ctx)
+
+	ctx.NewPage()
+	ctx.Heading("Important Notes", 15)
+
+	widths := ctx.Columns(notes.Weights...)
+	drawHeader := func() {
+		ctx.Fill(p.Accent)
+		ctx.TextColor(p.OnPrimary)
+		ctx.Font("B", 10)
+		ctx.CellFormat(widths[0], 10, notes.Headers[0], "1", 0, "C", true, 0, "")
+		ctx.CellFormat(widths[1], 10, notes.Headers[1], "1", 1, "C", true, 0, "")
+		ctx.TextColor(p.Text)
+		ctx.Font("", 9)
+	}
+	drawHeader()
+
+	const lineHeight = 6.0
+	const paddingTop = 3.0
+	const paddingBottom = 3.0
+	const horizontalPadding = 4.0
+	const minRowHeight = 12.0
+	x := ctx.Left()
+
+	for i, note := range notes.Rows {
+		y := pdf.GetY()
+		width0, width1 := widths[0], widths[1]
+
+		lines0 := ctx.SplitLines(note[0].Text, width0-horizontalPadding)
+		lines1 := ctx.SplitLines(note[1].Text, width1-horizontalPadding)
+		maxLines := max(len(lines0), len(lines1))
+		cellHeight := float64(maxLines)*lineHeight + paddingTop + paddingBottom
+		if cellHeight < minRowHeight {
+			cellHeight = minRowHeight
+		}
+		if y+cellHeight > ctx.ContentBottom() {
+			ctx.NewPage()
+			drawHeader()
+			y = pdf.GetY()
+		}
+		if i%2 == 0 {
+			ctx.Fill(p.Surface)
+		} else {
+			ctx.Fill(p.Background)
+		}
+
+		pdf.Rect(x, y, width0, cellHeight, "F")
+		pdf.SetXY(x+horizontalPadding/2, y+paddingTop)
+		ctx.MultiCell(width0-horizontalPadding, lineHeight, note[0].Text, "C", false)
+
+		pdf.Rect(x+width0, y, width1, cellHeight, "F")
+		pdf.SetXY(x+width0+horizontalPadding/2, y+paddingTop)
+		ctx.MultiCell(width1-horizontalPadding, lineHeight, note[1].Text, "C", false)
+
+		pdf.SetY(y + cellHeight)
+	}
+
+	return nil
+}
+
+func serviceScope(ctx *Context) *TableContent {
+	return textTable(ctx, []string{"Service", "Details"}, []float64{60, 120}, [][]string{
+		{"Flight Tickets And Hotel Vouchers", "Delivered 3 Days Post Full Payment"},
+		{"Web Check-In", "Boarding Pass Delivery Via Email/WhatsApp"},
+		{"Support", "Chat Support - Response Time: 4 Hours"},
+		{"Cancellation Support", "Provided"},
+		{"Trip Support", "Response Time: 5 Minutes"},
+	})
+}
+
+func addServiceScope(ctx *Context) error {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	services := serviceScope(ctx)
+
+	ctx.Continue(10, 40)
+	ctx.Heading("Scope Of Service", 15)
+
+	widths := ctx.Columns(services.Weights...)
+	drawHeader := func() {
+		ctx.Fill(p.Accent)
+		ctx.TextColor(p.OnPrimary)
+		ctx.Font("B", 10)
+		ctx.CellFormat(widths[0], 10, services.Headers[0], "1", 0, "C", true, 0, "")
+		ctx.CellFormat(widths[1], 10, services.Headers[1], "1", 1, "C", true, 0, "")
+		ctx.TextColor(p.Text)
+		ctx.Font("", 9)
+	}
+	drawHeader()
+
+	const lineHeight = 6.0
+	const paddingTop = 3.0
+	const paddingBottom = 3.0
+	const horizontalPadding = 4.0
+	const minRowHeight = 12.0
+
+	x := ctx.Left()
+	for i, service := range services.Rows {
+		y := pdf.GetY()
+		width0, width1 := widths[0], widths[1]
+
+		lines0 := ctx.SplitLines(service[0].Text, width0-horizontalPadding)
+		lines1 := ctx.SplitLines(service[1].Text, width1-horizontalPadding)
+		maxLines := max(len(lines0), len(lines1))
+		rowHeight := float64(maxLines)*lineHeight + paddingTop + paddingBottom
+		if rowHeight < minRowHeight {
+			rowHeight = minRowHeight
+		}
+		if y+rowHeight > ctx.ContentBottom() {
+			ctx.NewPage()
+			drawHeader()
+			y = pdf.GetY()
+		}
+		if i%2 == 0 {
+			ctx.Fill(p.Surface)
+		} else {
+			ctx.Fill(p.Background)
+		}
+
+		pdf.Rect(x, y, width0, rowHeight, "F")
+		pdf.SetXY(x+horizontalPadding/2, y+paddingTop)
+		ctx.MultiCell(width0-horizontalPadding, lineHeight, service[0].Text, "C", false)
+
+		pdf.Rect(x+width0, y, width1, rowHeight, "F")
+		pdf.SetXY(x+width0+horizontalPadding/2, y+paddingTop)
+		ctx.MultiCell(width1-horizontalPadding, lineHeight, service[1].Text, "C", false)
+
+		pdf.SetXY(x, y+rowHeight)
+	}
+
+	return nil
+}
@@ -0,0 +1,39 @@
+package render
+
+import (
+	"github.com/jung-kurt/gofpdf"
+	"github.com/monoMonu/travel-itinerary-pdf/config"
+)
+
+var permissionFlags = map[string]byte{
+	"print":    gofpdf.CnProtectPrint,
+	"copy":     gofpdf.CnProtectCopy,
+	"modify":   gofpdf.CnProtectModify,
+	"annotate": gofpdf.CnProtectAnnotForms,
+}
+
+// protect encrypts the document when p has a user password. gofpdf only
+// offers 40-bit RC4, which keeps casual readers out rather than determined
+// ones. Without an owner password gofpdf would pick a random one, so one is
+// derived instead; see config.Protection.Owner.
+func protect(pdf *gofpdf.Fpdf, p config.Protection) error {
+	if p.UserPassword == "" {
+		return nil
+	}
+	owner, err := p.Owner(p.UserPassword)
+	if err != nil {
+		return err
+	}
+
+	var flags byte
+	if p.Permissions == nil {
+		for _, flag := range permissionFlags {
+			flags |= flag
+		}
+	}
+	for _, name := range p.Permissions {
+		flags |= permissionFlags[name]
+	}
+	pdf.SetProtection(flags, p.UserPassword, owner)
+	return nil
+}
@@ -0,0 +1,84 @@
+package render
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/monoMonu/travel-itinerary-pdf/config"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+func sampleBooking() types.BookingData {
+	return types.BookingData{
+		BookingReference: "VG1234",
+		CustomerName:     "Rahul Sharma",
+		Destination:      "Singapore",
+		DepartureFrom:    "New Delhi",
+		DepartureDate:    "2025-06-15",
+		ReturnDate:       "2025-06-17",
+		Travelers:        2,
+		Days: []types.Day{
+			{Date: "2025-06-15", Activities: []types.Activity{
+				{Time: "09:00", Title: "Arrival", Description: "Transfer to the hotel.", Duration: 120, Type: "travel"},
+			}},
+			{Date: "2025-06-16", Activities: []types.Activity{
+				{Time: "10:00", Title: "Sentosa Island", Description: "Cable car to Sentosa.", Duration: 480, Type: "adventure"},
+			}},
+		},
+		Flights: []types.Flight{
+			{Date: "2025-06-15", Airline: "SQ 403", From: "Delhi (DEL)", To: "Singapore (SIN)", Departure: "23:00", Arrival: "07:10"},
+		},
+		Hotels: []types.Hotel{
+			{City: "Singapore", Name: "Marina Bay Sands", CheckIn: "2025-06-15", CheckOut: "2025-06-17", Nights: 2},
+		},
+		TotalAmount:  250000,
+		Installment1: 100000,
+		Installment2: 100000,
+		Options:      types.Options{Deterministic: true},
+	}
+}
+
+func renderBytes(t *testing.T, data types.BookingData, cfg Config) []byte {
+	t.Helper()
+	pdf, err := Build(data, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, pdf, data.Options); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProtectedDeterministic(t *testing.T) {
+	secret := config.OwnerPasswordSecret
+	config.OwnerPasswordSecret = "test secret"
+	t.Cleanup(func() { config.OwnerPasswordSecret = secret })
+
+	cfg := Config{Protection: config.Protection{UserPassword: "VG1234", Permissions: []string{"print"}}}
+	first := renderBytes(t, sampleBooking(), cfg)
+	second := renderBytes(t, sampleBooking(), cfg)
+	if !bytes.Contains(first, []byte("/Encrypt")) {
+		t.Fatal("the PDF is not encrypted")
+	}
+	if !bytes.Equal(first, second) {
+		t.Fatal("the same protected booking rendered to different bytes")
+	}
+
+	config.OwnerPasswordSecret = "another secret"
+	if bytes.Equal(first, renderBytes(t, sampleBooking(), cfg)) {
+		t.Fatal("the owner password does not depend on the secret")
+	}
+}
+
+func TestProtectedWithoutOwner(t *testing.T) {
+	secret := config.OwnerPasswordSecret
+	config.OwnerPasswordSecret = ""
+	t.Cleanup(func() { config.OwnerPasswordSecret = secret })
+
+	_, err := Build(sampleBooking(), Config{Protection: config.Protection{UserPassword: "VG1234"}})
+	if err == nil {
+		t.Fatal("a user password without an owner password or secret was accepted")
+	}
+}
@@ -0,0 +1,84 @@
+package render
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// drawQR draws content as a QR code of side size with its top-left corner at
+// x, y. Modules are filled rectangles, so the code stays sharp at any print
+// resolution. The quiet zone is part of size and is painted white.
+func drawQR(ctx *Context, content string, x, y, size float64) error {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return fmt.Errorf("qr code: %w", err)
+	}
+	bitmap := code.Bitmap()
+	module := size / float64(len(bitmap))
+
+	pdf := ctx.PDF
+	pdf.SetFillColor(255, 255, 255)
+	pdf.Rect(x, y, size, size, "F")
+
+	// One rectangle per horizontal run of dark modules keeps the content
+	// stream small.
+	pdf.SetFillColor(0, 0, 0)
+	for row, modules := range bitmap {
+		for col := 0; col < len(modules); {
+			if !modules[col] {
+				col++
+				continue
+			}
+			start := col
+			for col < len(modules) && modules[col] {
+				col++
+			}
+			pdf.Rect(x+float64(start)*module, y+float64(row)*module, float64(col-start)*module, module, "F")
+		}
+	}
+	return nil
+}
+
+// itineraryURL fills the {reference} placeholder of the configured online
+// itinerary link, or adds the reference as a query parameter when the link
+// has no placeholder.
+func itineraryURL(link, reference string) string {
+	if link == "" || reference == "" {
+		return link
+	}
+	if strings.Contains(link, "{reference}") {
+		return strings.ReplaceAll(link, "{reference}", url.PathEscape(reference))
+	}
+	sep := "?"
+	if strings.Contains(link, "?") {
+		sep = "&"
+	}
+	return link + sep + "ref=" + url.QueryEscape(reference)
+}
+
+// upiURI builds a UPI deep link asking for amount rupees, as understood by
+// Indian payment apps.
+func upiURI(payee, name string, amount float64, note, reference string) string {
+	params := []string{
+		"pa=" + upiEscape(payee),
+		"pn=" + upiEscape(name),
+		fmt.Sprintf("am=%.2f", amount),
+		"cu=INR",
+	}
+	if note != "" {
+		params = append(params, "tn="+upiEscape(note))
+	}
+	if reference != "" {
+		params = append(params, "tr="+upiEscape(reference))
+	}
+	return "upi://pay?" + strings.Join(params, "&")
+}
+
+// upiEscape percent-encodes like a query value but with %20 for spaces, which
+// some payment apps require.
+func upiEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,214 @@
+package render
+
+import (
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/monoMonu/travel-itinerary-pdf/config"
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// Section draws one part of the itinerary. Sections that need a fresh page
+// call ctx.NewPage; sections that may share a page with the previous one call
+// ctx.Continue.
+type Section interface {
+	Render(ctx *Context) error
+}
+
+// SectionFunc adapts a plain function to the Section interface.
+type SectionFunc func(ctx *Context) error
+
+func (f SectionFunc) Render(ctx *Context) error {
+	return f(ctx)
+}
+
+// Titled is implemented by sections that have an entry in the table of
+// contents.
+type Titled interface {
+	Title() string
+}
+
+// NewSection returns a section listed in the table of contents under title.
+func NewSection(title string, render func(ctx *Context) error) Section {
+	return titledSection{title: title, render: render}
+}
+
+type titledSection struct {
+	title  string
+	render func(ctx *Context) error
+}
+
+func (s titledSection) Render(ctx *Context) error { return s.render(ctx) }
+func (s titledSection) Title() string             { return s.title }
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Section{}
+)
+
+// Register makes a section available by name. It panics if the name is empty
+// or already taken, so it is meant to be called from init functions.
+func Register(name string, section Section) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" || section == nil {
+		panic("render: Register called with empty name or nil section")
+	}
+	if _, dup := registry[name]; dup {
+		panic("render: Register called twice for section " + name)
+	}
+	registry[name] = section
+}
+
+// Sections returns the names of all registered sections, sorted.
+func Sections() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Config carries the settings that come from the server or the API client
+// rather than from the booking itself.
+type Config struct {
+	// Theme defaults to theme.Default when its Name is empty.
+	Theme theme.Theme
+	// Links are left unlinked when empty.
+	Links config.Links
+	// Payment enables UPI QR codes in the payment plan.
+	Payment config.Payment
+	// Protection encrypts the PDF when its UserPassword, already expanded
+	// for the booking, is set.
+	Protection config.Protection
+	// QuoteValidityDays dates the valid-until line on quotes. Zero leaves it
+	// out.
+	QuoteValidityDays int
+	// Template lays out the document; nil means DefaultTemplate.
+	Template *Template
+}
+
+// Build renders data into a new PDF using the template's sections, or the
+// ones chosen in data.Options.Sections.
+func Build(data types.BookingData, cfg Config) (*gofpdf.Fpdf, error) {
+	cfg, names, sections, err := plan(data, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf, err := newDocument(data.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := newContext(data, cfg)
+	ctx.PDF = pdf
+	ctx.sections = names
+	ctx.contents = newContents(ctx, sections)
+	setMetadata(ctx)
+	if err := protect(pdf, cfg.Protection); err != nil {
+		return nil, err
+	}
+	registerLogo(ctx)
+	addFooterToAllPages(ctx)
+	for i, section := range sections {
+		ctx.entry = ctx.contents[i]
+		firstPage := pdf.PageCount() + 1
+		if err := section.Render(ctx); err != nil {
+			return nil, err
+		}
+		ctx.anchorSection(firstPage)
+	}
+
+	if pdf.PageCount() == 0 {
+		ctx.NewPage()
+	}
+	ctx.finishContents()
+	return pdf, pdf.Error()
+}
+
+// plan fills in the defaults of cfg and picks the sections to render: the
+// summary for the summary variant, else the requested ones or the
+// template's, with the contents page added when asked for.
+func plan(data types.BookingData, cfg Config) (Config, []string, []Section, error) {
+	if cfg.Template == nil {
+		var err error
+		if cfg.Template, err = GetTemplate(DefaultTemplate, 0); err != nil {
+			return cfg, nil, nil, err
+		}
+	}
+	names := data.Options.Sections
+	switch {
+	case data.Options.Variant == types.VariantSummary:
+		names = []string{"summary"}
+	case len(names) == 0:
+		names = cfg.Template.SectionNames()
+	}
+	if data.Options.TableOfContents && data.Options.Variant != types.VariantSummary {
+		names = withContents(names)
+	}
+	sections, err := cfg.Template.Resolve(names)
+	if err != nil {
+		return cfg, nil, nil, err
+	}
+
+	if cfg.Theme.Name == "" {
+		cfg.Theme = theme.Default
+	}
+	return cfg, names, sections, nil
+}
+
+func newContext(data types.BookingData, cfg Config) *Context {
+	return &Context{
+		Data:              data,
+		Theme:             cfg.Theme,
+		Links:             cfg.Links,
+		Payment:           cfg.Payment,
+		QuoteValidityDays: cfg.QuoteValidityDays,
+		Locale:            i18n.Get(data.Options.Locale),
+	}
+}
+
+// newDocument creates an empty PDF with the requested paper size and
+// orientation. Narrow pages get narrower side margins.
+func newDocument(opts types.Options) (*gofpdf.Fpdf, error) {
+	size := opts.PageSize
+	if size == "" {
+		size = "A4"
+	}
+	orientation := "P"
+	if opts.Orientation == "landscape" {
+		orientation = "L"
+	}
+
+	pdf := gofpdf.New(orientation, "mm", size, "")
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+
+	margin := 15.0
+	if w, _ := pdf.GetPageSize(); w < 180 {
+		margin = 10
+	}
+	pdf.SetMargins(margin, 15, margin)
+	return pdf, nil
+}
+
+// withContents puts the "toc" section right after the cover, or first when
+// there is no cover, unless the request already placed it.
+func withContents(names []string) []string {
+	if slices.Contains(names, "toc") {
+		return names
+	}
+	at := slices.Index(names, "cover") + 1
+	return slices.Insert(slices.Clone(names), at, "toc")
+}
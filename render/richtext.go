@@ -0,0 +1,184 @@
+package render
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/monoMonu/travel-itinerary-pdf/fonts"
+	"github.com/monoMonu/travel-itinerary-pdf/markdown"
+)
+
+// Descriptions and notes may use the Markdown subset of package markdown.
+// Each block is wrapped to lines here and drawn with the theme's fonts.
+
+// richLine is one wrapped line, ready to draw.
+type richLine struct {
+	indent float64
+	marker string
+	spans  []markdown.Span
+}
+
+// spanStyle adds the span's emphasis to the current font style.
+func (ctx *Context) spanStyle(span markdown.Span) string {
+	style := ctx.font.style
+	if span.Bold && !strings.Contains(style, "B") {
+		style = "B" + style
+	}
+	if span.Italic && !strings.Contains(style, "I") {
+		style += "I"
+	}
+	return style
+}
+
+func (ctx *Context) spanWidth(span markdown.Span) float64 {
+	return ctx.units(fonts.Width(fonts.Shape(span.Text), ctx.font.family, ctx.spanStyle(span)))
+}
+
+// RichLines wraps Markdown to fit in a cell of width w in the current font,
+// like SplitLines does for plain text. List items get a hanging indent.
+func (ctx *Context) RichLines(s string, w float64) []richLine {
+	maxWidth := w - 2*ctx.PDF.GetCellMargin()
+	var lines []richLine
+	for _, block := range markdown.Parse(s) {
+		indent := 0.0
+		if block.Marker != "" {
+			indent = ctx.StringWidth(block.Marker) + ctx.StringWidth("  ")
+		}
+		line := richLine{indent: indent, marker: block.Marker}
+		width := indent
+		// pending holds spaces that only print if a word follows them on
+		// the same line.
+		var pending []markdown.Span
+		pendingWidth := 0.0
+
+		for _, word := range splitWords(block.Spans) {
+			ww := ctx.spanWidth(word)
+			if strings.TrimSpace(word.Text) == "" {
+				pending = append(pending, word)
+				pendingWidth += ww
+				continue
+			}
+			if width+pendingWidth+ww > maxWidth && len(line.spans) > 0 {
+				lines = append(lines, line)
+				line = richLine{indent: indent}
+				width, pending, pendingWidth = indent, nil, 0
+			}
+			for _, space := range pending {
+				line.add(space)
+			}
+			width += pendingWidth
+			pending, pendingWidth = nil, 0
+
+			// A word wider than a whole line is broken between characters.
+			for width+ww > maxWidth && len(line.spans) == 0 && utf8.RuneCountInString(word.Text) > 1 {
+				head := word
+				head.Text = ""
+				for _, r := range word.Text {
+					next := head
+					next.Text += string(r)
+					if indent+ctx.spanWidth(next) > maxWidth && head.Text != "" {
+						break
+					}
+					head = next
+				}
+				lines = append(lines, richLine{indent: indent, marker: line.marker, spans: []markdown.Span{head}})
+				line = richLine{indent: indent}
+				word.Text = word.Text[len(head.Text):]
+				ww = ctx.spanWidth(word)
+			}
+			line.add(word)
+			width += ww
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// add appends span to the line, joining it to the last span when they look
+// the same so that text is drawn in as few pieces as possible.
+func (l *richLine) add(span markdown.Span) {
+	if n := len(l.spans); n > 0 {
+		last := &l.spans[n-1]
+		if last.Bold == span.Bold && last.Italic == span.Italic && last.Link == span.Link {
+			last.Text += span.Text
+			return
+		}
+	}
+	l.spans = append(l.spans, span)
+}
+
+// splitWords cuts spans at spaces, keeping each run of spaces as its own
+// span so that lines can break there.
+func splitWords(spans []markdown.Span) []markdown.Span {
+	var words []markdown.Span
+	for _, span := range spans {
+		start := 0
+		for i := 1; i <= len(span.Text); i++ {
+			if i == len(span.Text) || (span.Text[i] == ' ') != (span.Text[i-1] == ' ') {
+				word := span
+				word.Text = span.Text[start:i]
+				words = append(words, word)
+				start = i
+			}
+		}
+	}
+	return words
+}
+
+// width returns how wide a line from RichLines draws.
+func (l richLine) width(ctx *Context) float64 {
+	w := l.indent
+	for _, span := range l.spans {
+		w += ctx.spanWidth(span)
+	}
+	return w
+}
+
+// DrawRichLine draws a line from RichLines in a cell at x, y of width w and
+// height h, aligned L, C or R. Links are underlined in the theme's primary
+// color.
+func (ctx *Context) DrawRichLine(line richLine, x, y, w, h float64, align string) {
+	pdf := ctx.PDF
+	margin := pdf.GetCellMargin()
+	textX := x + margin
+	switch {
+	case strings.Contains(align, "C"):
+		textX = x + (w-line.width(ctx))/2
+	case strings.Contains(align, "R"):
+		textX = x + w - margin - line.width(ctx)
+	}
+	baseline := y + h/2 + 0.3*ctx.font.size/pdf.GetConversionRatio()
+
+	r, g, b := pdf.GetTextColor()
+	base := ctx.font
+	if line.marker != "" {
+		ctx.drawRuns(textX, baseline, line.marker)
+	}
+	textX += line.indent
+	for _, span := range line.spans {
+		ctx.font.style = ctx.spanStyle(span)
+		ctx.font.underline = base.underline || span.Link != ""
+		if span.Link != "" {
+			ctx.TextColor(ctx.Theme.Palette.Primary)
+		}
+		spanW := ctx.spanWidth(span)
+		ctx.drawRuns(textX, baseline, fonts.Visual(fonts.Shape(span.Text)))
+		if span.Link != "" {
+			pdf.LinkString(textX, y, spanW, h, span.Link)
+			pdf.SetTextColor(r, g, b)
+		}
+		ctx.font = base
+		textX += spanW
+	}
+	ctx.useFace(ctx.font.family.Face(ctx.font.style))
+}
+
+// drawRuns draws shaped text at a baseline in the current font and its
+// fallbacks.
+func (ctx *Context) drawRuns(x, baseline float64, s string) {
+	for _, run := range fonts.Runs(s, ctx.font.family, ctx.font.style) {
+		ctx.useFace(run.Face)
+		ctx.PDF.Text(x, baseline, run.Text)
+		x += ctx.units(fonts.Width(run.Text, ctx.font.family, ctx.font.style))
+	}
+}
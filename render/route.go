@@ -0,0 +1,546 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/monoMonu/travel-itinerary-pdf/geo"
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// The route map is drawn only from the coordinates in the booking: there is
+// no coastline or tile data, just a graticule, the stops and the legs
+// between them.
+
+const (
+	arcSegments = 48
+	// Stops closer than this on the page share one marker and label.
+	stopMergeDistance = 4.0
+)
+
+// RouteMap is the trip's path: the places it stops at and the legs between
+// them.
+type RouteMap struct {
+	Stops []*RouteStop
+	Legs  []RouteLeg
+}
+
+// RouteStop is a place on the route. Days are the numbers of the days spent
+// there and Home marks where the trip starts.
+type RouteStop struct {
+	Point geo.Point
+	Label string
+	Days  []int
+	Home  bool
+}
+
+// RouteLeg is a flight, or a transfer by road when Flight is false.
+type RouteLeg struct {
+	From, To geo.Point
+	Flight   bool
+}
+
+func location(l *types.Location) *geo.Point {
+	if l == nil {
+		return nil
+	}
+	return &geo.Point{Lat: l.Lat, Lon: l.Lon}
+}
+
+// buildRoute walks flights and hotel stays in date order, starting from the
+// departure location.
+func buildRoute(data types.BookingData) *RouteMap {
+	route := &RouteMap{}
+	var current *geo.Point
+
+	stop := func(p geo.Point, label string) *RouteStop {
+		if len(route.Stops) > 0 {
+			p.Lon = geo.Unwrap(p.Lon, route.Stops[0].Point.Lon)
+		}
+		for _, s := range route.Stops {
+			if s.Point == p {
+				return s
+			}
+		}
+		s := &RouteStop{Point: p, Label: label}
+		route.Stops = append(route.Stops, s)
+		return s
+	}
+	moveTo := func(to *RouteStop, flight bool) {
+		if current != nil && (flight || geo.DistanceKm(*current, to.Point) > 1) {
+			route.Legs = append(route.Legs, RouteLeg{From: *current, To: to.Point, Flight: flight})
+		}
+		p := to.Point
+		current = &p
+	}
+
+	if p := location(data.DepartureLocation); p != nil {
+		stop(*p, data.DepartureFrom).Home = true
+		current = &route.Stops[0].Point
+	}
+
+	type event struct {
+		date   string
+		flight *types.Flight
+		hotel  *types.Hotel
+	}
+	var events []event
+	for i := range data.Flights {
+		events = append(events, event{date: data.Flights[i].Date, flight: &data.Flights[i]})
+	}
+	for i := range data.Hotels {
+		events = append(events, event{date: data.Hotels[i].CheckIn, hotel: &data.Hotels[i]})
+	}
+	// Flights come before check-ins on the same day.
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].date != events[j].date {
+			return events[i].date < events[j].date
+		}
+		return events[i].flight != nil && events[j].flight == nil
+	})
+
+	destination := location(data.DestinationLocation)
+	assigned := map[int]bool{}
+	for _, e := range events {
+		switch {
+		case e.flight != nil:
+			to := location(e.flight.ToLocation)
+			if to == nil {
+				continue
+			}
+			if from := location(e.flight.FromLocation); from != nil {
+				moveTo(stop(*from, e.flight.From), false)
+			}
+			moveTo(stop(*to, e.flight.To), true)
+
+		case e.hotel != nil:
+			p := location(e.hotel.Location)
+			if p == nil {
+				p = destination
+			}
+			if p == nil {
+				continue
+			}
+			label := e.hotel.City
+			if label == "" {
+				label = e.hotel.Name
+			}
+			s := stop(*p, label)
+			for i, day := range data.Days {
+				if day.Date >= e.hotel.CheckIn && day.Date < e.hotel.CheckOut && !assigned[i] {
+					s.Days = append(s.Days, i+1)
+					assigned[i] = true
+				}
+			}
+			moveTo(s, false)
+		}
+	}
+
+	// Days without a located hotel are spent at the destination.
+	if destination != nil {
+		var s *RouteStop
+		for i := range data.Days {
+			if assigned[i] {
+				continue
+			}
+			if s == nil {
+				s = stop(*destination, data.Destination)
+			}
+			s.Days = append(s.Days, i+1)
+		}
+	}
+	return route
+}
+
+// drawable reports whether the route has at least two places to show.
+func (r *RouteMap) drawable() bool {
+	for _, s := range r.Stops[min(1, len(r.Stops)):] {
+		if geo.DistanceKm(s.Point, r.Stops[0].Point) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// arcs returns the path of every leg: a great circle for flights and a
+// straight line for ground transfers.
+func (r *RouteMap) arcs() [][]geo.Point {
+	arcs := make([][]geo.Point, len(r.Legs))
+	for i, leg := range r.Legs {
+		if leg.Flight {
+			arcs[i] = geo.GreatCircle(leg.From, leg.To, arcSegments)
+		} else {
+			arcs[i] = []geo.Point{leg.From, leg.To}
+		}
+	}
+	return arcs
+}
+
+// mapMarker is one or more stops that land on the same spot of the page.
+type mapMarker struct {
+	x, y   float64
+	labels []string
+	days   []int
+	home   bool
+}
+
+func (r *RouteMap) markers(proj geo.Projection) []*mapMarker {
+	var markers []*mapMarker
+	for _, s := range r.Stops {
+		x, y := proj.Project(s.Point)
+		var m *mapMarker
+		for _, other := range markers {
+			if math.Hypot(other.x-x, other.y-y) < stopMergeDistance {
+				m = other
+				break
+			}
+		}
+		if m == nil {
+			m = &mapMarker{x: x, y: y}
+			markers = append(markers, m)
+		}
+		if s.Label != "" && !slices.Contains(m.labels, s.Label) {
+			m.labels = append(m.labels, s.Label)
+		}
+		m.days = append(m.days, s.Days...)
+		m.home = m.home || s.Home
+	}
+	return markers
+}
+
+// RoutePlot is a route map laid out in a box, in the box's coordinates.
+type RoutePlot struct {
+	Paths   []RoutePath
+	Markers []RouteMarker
+	// Parallels are the y and Meridians the x of the graticule lines.
+	Parallels, Meridians []float64
+}
+
+// RoutePath is the line of one leg of the route.
+type RoutePath struct {
+	Points []RoutePoint
+	Flight bool
+}
+
+type RoutePoint struct {
+	X, Y float64
+}
+
+// RouteMarker is one or more stops that land on the same spot of the map,
+// with their names and, on full-size maps, the days spent there.
+type RouteMarker struct {
+	X, Y        float64
+	Label, Days string
+	Home        bool
+}
+
+// Plot lays the route out in the w by h box at x, y. Small maps have
+// narrower margins and leave out the day numbers. Stops are merged when
+// they are closer than a few units, so the box is best given in mm.
+func (r *RouteMap) Plot(l *i18n.Locale, x, y, w, h float64, small bool) RoutePlot {
+	arcs := r.arcs()
+	var extent []geo.Point
+	for _, s := range r.Stops {
+		extent = append(extent, s.Point)
+	}
+	for _, arc := range arcs {
+		extent = append(extent, arc...)
+	}
+	margin := 14.0
+	if small {
+		margin = 7
+	}
+	proj := geo.Fit(extent, x, y, w, h, margin)
+
+	var plot RoutePlot
+	plot.Parallels, plot.Meridians = graticule(proj, x, y, w, h)
+	for i, leg := range r.Legs {
+		path := RoutePath{Points: make([]RoutePoint, len(arcs[i])), Flight: leg.Flight}
+		for j, pt := range arcs[i] {
+			path.Points[j].X, path.Points[j].Y = proj.Project(pt)
+		}
+		plot.Paths = append(plot.Paths, path)
+	}
+	for _, m := range r.markers(proj) {
+		marker := RouteMarker{X: m.x, Y: m.y, Label: strings.Join(m.labels, " / "), Home: m.home}
+		if !small {
+			marker.Days = dayRange(l, m.days)
+		}
+		plot.Markers = append(plot.Markers, marker)
+	}
+	return plot
+}
+
+// dayRange formats day numbers as "Day 2" or "Days 1–3, 5".
+func dayRange(l *i18n.Locale, days []int) string {
+	if len(days) == 0 {
+		return ""
+	}
+	days = slices.Clone(days)
+	slices.Sort(days)
+	days = slices.Compact(days)
+
+	var parts []string
+	for i := 0; i < len(days); {
+		j := i
+		for j+1 < len(days) && days[j+1] == days[j]+1 {
+			j++
+		}
+		if j > i {
+			parts = append(parts, fmt.Sprintf("%d–%d", days[i], days[j]))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d", days[i]))
+		}
+		i = j + 1
+	}
+	if len(days) == 1 {
+		return l.T("Day %s", parts[0])
+	}
+	return l.T("Days %s", strings.Join(parts, ", "))
+}
+
+// drawRouteMap draws the route inside the w by h box at x, y. Small maps
+// leave out the day numbers.
+func drawRouteMap(ctx *Context, route *RouteMap, x, y, w, h float64, small bool) {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+	plot := route.Plot(ctx.Locale, x, y, w, h, small)
+
+	ctx.Fill(p.Surface)
+	ctx.Stroke(p.Border)
+	pdf.RoundedRect(x, y, w, h, ctx.Radius(1), "1234", "FD")
+
+	pdf.ClipRoundedRect(x, y, w, h, ctx.Radius(1), false)
+	ctx.Stroke(p.Rule)
+	pdf.SetLineWidth(0.1)
+	for _, ly := range plot.Parallels {
+		pdf.Line(x, ly, x+w, ly)
+	}
+	for _, lx := range plot.Meridians {
+		pdf.Line(lx, y, lx, y+h)
+	}
+	pdf.SetLineWidth(0.2)
+
+	for _, path := range plot.Paths {
+		points := make([]gofpdf.PointType, len(path.Points))
+		for j, pt := range path.Points {
+			points[j] = gofpdf.PointType{X: pt.X, Y: pt.Y}
+		}
+		if path.Flight {
+			ctx.Stroke(p.Accent)
+			pdf.SetLineWidth(0.6)
+			pdf.MoveTo(points[0].X, points[0].Y)
+			for _, pt := range points[1:] {
+				pdf.LineTo(pt.X, pt.Y)
+			}
+			pdf.DrawPath("D")
+			drawArrow(ctx, points)
+		} else {
+			ctx.Stroke(p.Primary)
+			pdf.SetLineWidth(0.4)
+			pdf.SetDashPattern([]float64{1.2, 1}, 0)
+			pdf.Line(points[0].X, points[0].Y, points[1].X, points[1].Y)
+			pdf.SetDashPattern(nil, 0)
+		}
+	}
+	pdf.SetLineWidth(0.2)
+
+	for _, m := range plot.Markers {
+		radius := 1.8
+		ctx.Fill(p.Primary)
+		if m.Home {
+			radius = 2.2
+			ctx.Fill(p.Accent)
+		}
+		pdf.SetDrawColor(255, 255, 255)
+		pdf.SetLineWidth(0.5)
+		pdf.Circle(m.X, m.Y, radius, "FD")
+		pdf.SetLineWidth(0.2)
+
+		drawMarkerLabel(ctx, m, x+w, small)
+	}
+	pdf.ClipEnd()
+}
+
+func drawMarkerLabel(ctx *Context, m RouteMarker, right float64, small bool) {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+
+	label, days := m.Label, m.Days
+	size := 8.0
+	if small {
+		size = 6.5
+	}
+
+	ctx.Font("B", size)
+	width := ctx.StringWidth(label)
+	ctx.Font("", size-1)
+	width = max(width, ctx.StringWidth(days))
+
+	// Put the label on the left when it would run off the map.
+	lx, align := m.X+3, "L"
+	if lx+width+2 > right {
+		lx, align = m.X-3-width-2, "R"
+	}
+
+	lineH := size * 0.45
+	top := m.Y - lineH/2
+	if days != "" {
+		top = m.Y - lineH
+	}
+
+	ctx.TextColor(p.Text)
+	ctx.Font("B", size)
+	pdf.SetXY(lx, top)
+	ctx.CellFormat(width+2, lineH, label, "", 0, align, false, 0, "")
+	if days != "" {
+		ctx.TextColor(p.Muted)
+		ctx.Font("", size-1)
+		pdf.SetXY(lx, top+lineH)
+		ctx.CellFormat(width+2, lineH, days, "", 0, align, false, 0, "")
+	}
+}
+
+// drawArrow marks the middle of a flight path with a small triangle pointing
+// in the direction of travel.
+func drawArrow(ctx *Context, points []gofpdf.PointType) {
+	mid := len(points) / 2
+	a, b := points[max(mid-1, 0)], points[min(mid+1, len(points)-1)]
+	angle := math.Atan2(b.Y-a.Y, b.X-a.X)
+	c := points[mid]
+
+	const size = 2.0
+	tip := gofpdf.PointType{X: c.X + size*math.Cos(angle), Y: c.Y + size*math.Sin(angle)}
+	left := gofpdf.PointType{X: c.X + size*math.Cos(angle+2.5), Y: c.Y + size*math.Sin(angle+2.5)}
+	right := gofpdf.PointType{X: c.X + size*math.Cos(angle-2.5), Y: c.Y + size*math.Sin(angle-2.5)}
+
+	ctx.Fill(ctx.Theme.Palette.Accent)
+	ctx.PDF.Polygon([]gofpdf.PointType{tip, left, right}, "F")
+}
+
+// graticule places parallels and meridians at a round interval that gives
+// a handful of lines across the box.
+func graticule(proj geo.Projection, x, y, w, h float64) (parallels, meridians []float64) {
+	topLeft := proj.Unproject(x, y)
+	bottomRight := proj.Unproject(x+w, y+h)
+
+	span := math.Max(topLeft.Lat-bottomRight.Lat, bottomRight.Lon-topLeft.Lon)
+	step := 30.0
+	for _, s := range []float64{0.5, 1, 2, 5, 10, 15, 30} {
+		if span/s <= 8 {
+			step = s
+			break
+		}
+	}
+
+	for lat := math.Ceil(bottomRight.Lat/step) * step; lat <= topLeft.Lat; lat += step {
+		_, ly := proj.Project(geo.Point{Lat: lat, Lon: topLeft.Lon})
+		parallels = append(parallels, ly)
+	}
+	for lon := math.Ceil(topLeft.Lon/step) * step; lon <= bottomRight.Lon; lon += step {
+		lx, _ := proj.Project(geo.Point{Lat: topLeft.Lat, Lon: lon})
+		meridians = append(meridians, lx)
+	}
+	return parallels, meridians
+}
+
+// TripRoute is the route page: the map, a legend of its lines and the
+// trip's details.
+type TripRoute struct {
+	Map     *RouteMap
+	Legend  []LegendItem
+	Details []Fact
+}
+
+func (*TripRoute) content() {}
+
+// tripRoute is nil for trips without two places to show.
+func tripRoute(ctx *Context) *TripRoute {
+	route := buildRoute(ctx.Data)
+	if !route.drawable() {
+		return nil
+	}
+	p := ctx.Theme.Palette
+	return &TripRoute{
+		Map: route,
+		Legend: []LegendItem{
+			{Kind: LegendLine, Color: p.Accent, Label: ctx.T("Flight")},
+			{Kind: LegendDash, Color: p.Primary, Label: ctx.T("Transfer")},
+		},
+		Details: tripDetails(ctx),
+	}
+}
+
+func addTripRoute(ctx *Context) error {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+
+	route := tripRoute(ctx)
+	if route == nil {
+		return nil
+	}
+
+	ctx.NewPage()
+	ctx.Heading("Trip Route", 12)
+
+	// Leave room below the map for the legend and the trip details.
+	mapY := pdf.GetY()
+	mapH := min(150, ctx.ContentBottom()-mapY-50)
+	drawRouteMap(ctx, route.Map, ctx.Left(), mapY, ctx.ContentWidth(), mapH, false)
+	pdf.SetY(mapY + mapH + 6)
+
+	// Legend: line samples 40 mm apart, then their labels.
+	legendX, legendY := ctx.Left(), pdf.GetY()
+	for i, item := range route.Legend {
+		x := legendX + 40*float64(i)
+		ctx.Stroke(item.Color)
+		if item.Kind == LegendDash {
+			pdf.SetLineWidth(0.4)
+			pdf.SetDashPattern([]float64{1.2, 1}, 0)
+		} else {
+			pdf.SetLineWidth(0.6)
+		}
+		pdf.Line(x, legendY+2, x+10, legendY+2)
+		if item.Kind == LegendDash {
+			pdf.SetDashPattern(nil, 0)
+		}
+	}
+	pdf.SetLineWidth(0.2)
+
+	ctx.TextColor(p.Muted)
+	ctx.Font("", 8)
+	for i, item := range route.Legend {
+		pdf.SetXY(legendX+40*float64(i)+11, legendY)
+		ctx.Cell(25, 4, item.Label)
+	}
+	pdf.SetY(legendY + 10)
+
+	ctx.TextColor(p.Text)
+	for _, detail := range route.Details {
+		pdf.SetX(ctx.Left())
+		ctx.Font("B", 9)
+		ctx.Cell(40, 6, detail.Label+":")
+		ctx.Font("", 9)
+		ctx.CellFormat(0, 6, detail.Value, "", 1, "L", false, 0, "")
+	}
+	return nil
+}
+
+// tripDetails are the rows shown on the cover card, or on the route page
+// when the map takes the card's place.
+func tripDetails(ctx *Context) []Fact {
+	data := ctx.Data
+	details := []Fact{
+		{ctx.T("Departure From"), data.DepartureFrom},
+		{ctx.T("Departure"), ctx.Date(data.DepartureDate)},
+		{ctx.T("Arrival"), ctx.Date(data.ReturnDate)},
+		{ctx.T("Destination"), data.Destination},
+		{ctx.T("No. Of Travellers"), ctx.Locale.FormatNumber(float64(data.Travelers), 0)},
+	}
+	if data.BookingReference != "" {
+		details = append([]Fact{{ctx.T("Booking Reference"), data.BookingReference}}, details...)
+	}
+	return details
+}
@@ -0,0 +1,228 @@
+package render
+
+import (
+	"sort"
+	"time"
+
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+)
+
+// The calendar and the timeline both lay the booking out on dates. They
+// share a schedule worked out from Days, Hotels and Flights so that they
+// agree on where the gaps and overlaps are.
+
+// tripSchedule runs from the earliest to the latest date anywhere in the
+// booking, so a stay or flight outside the stated trip dates still shows.
+type tripSchedule struct {
+	first, last time.Time
+	days        map[time.Time]*scheduleDay
+	stays       []scheduledStay
+	flights     []scheduledFlight
+	transfers   []scheduledTransfer
+}
+
+type scheduleDay struct {
+	date time.Time
+	// number is the day of the itinerary, from 1, or 0 for a date without
+	// one.
+	number     int
+	activities []types.Activity
+	flights    []types.Flight
+	// hotels are the stays that cover the night starting on this date.
+	hotels []string
+	// night is whether the travellers spend this night away, between the
+	// departure and return dates.
+	night bool
+	// aloft is whether they spend it on a flight instead of in a hotel.
+	aloft bool
+}
+
+// gap reports a night away without a hotel or a flight to sleep on.
+func (d *scheduleDay) gap() bool {
+	return d.night && len(d.hotels) == 0 && !d.aloft
+}
+
+// overlap reports a night booked in more than one hotel.
+func (d *scheduleDay) overlap() bool {
+	return len(d.hotels) > 1
+}
+
+type scheduledStay struct {
+	hotel   types.Hotel
+	in, out time.Time
+}
+
+type scheduledFlight struct {
+	flight types.Flight
+	// departs and arrives are on the trip clock; arrival is left zero when
+	// the booking has no usable time for it.
+	departs, arrives time.Time
+}
+
+type scheduledTransfer struct {
+	at       time.Time
+	from, to string
+}
+
+// Stays are drawn from a typical check-in to check-out time, so that back
+// to back hotels meet around midday rather than touching.
+const (
+	checkInHour  = 14
+	checkOutHour = 11
+)
+
+// parseDay reads a YYYY-MM-DD date.
+func parseDay(s string) (time.Time, bool) {
+	t, err := time.Parse(time.DateOnly, s)
+	return t, err == nil
+}
+
+// atHour returns day plus a number of hours.
+func atHour(day time.Time, hours float64) time.Time {
+	return day.Add(time.Duration(hours * float64(time.Hour)))
+}
+
+// clockHours is utils.ParseClock in hours.
+func clockHours(s string) (float64, bool) {
+	d, ok := utils.ParseClock(s)
+	return d.Hours(), ok
+}
+
+func planSchedule(data types.BookingData) tripSchedule {
+	s := tripSchedule{days: map[time.Time]*scheduleDay{}}
+	extend := func(t time.Time) {
+		if s.first.IsZero() || t.Before(s.first) {
+			s.first = t
+		}
+		if t.After(s.last) {
+			s.last = t
+		}
+	}
+	day := func(t time.Time) *scheduleDay {
+		d := s.days[t]
+		if d == nil {
+			d = &scheduleDay{date: t}
+			s.days[t] = d
+			extend(t)
+		}
+		return d
+	}
+
+	departure, okDeparture := parseDay(data.DepartureDate)
+	ret, okReturn := parseDay(data.ReturnDate)
+	if okDeparture && okReturn {
+		for t := departure; t.Before(ret); t = t.AddDate(0, 0, 1) {
+			day(t).night = true
+		}
+		day(ret)
+	}
+
+	for i, d := range data.Days {
+		if t, ok := parseDay(d.Date); ok {
+			sd := day(t)
+			sd.number = i + 1
+			sd.activities = append(sd.activities, d.Activities...)
+		}
+	}
+
+	for _, hotel := range data.Hotels {
+		in, okIn := parseDay(hotel.CheckIn)
+		out, okOut := parseDay(hotel.CheckOut)
+		if !okIn || !okOut || out.Before(in) {
+			continue
+		}
+		s.stays = append(s.stays, scheduledStay{hotel: hotel, in: in, out: out})
+		for t := in; t.Before(out); t = t.AddDate(0, 0, 1) {
+			day(t).hotels = append(day(t).hotels, hotel.Name)
+		}
+		day(out)
+	}
+	sort.SliceStable(s.stays, func(i, j int) bool { return s.stays[i].in.Before(s.stays[j].in) })
+
+	for _, flight := range data.Flights {
+		t, ok := parseDay(flight.Date)
+		if !ok {
+			continue
+		}
+		day(t).flights = append(day(t).flights, flight)
+		sf := scheduledFlight{flight: flight, departs: t}
+		if h, ok := clockHours(flight.Departure); ok {
+			sf.departs = atHour(t, h)
+		}
+		if h, ok := clockHours(flight.Arrival); ok {
+			sf.arrives = atHour(t, h)
+			// Arrival times are local and may be on the next day.
+			if sf.arrives.Before(sf.departs) {
+				sf.arrives = sf.arrives.AddDate(0, 0, 1)
+			}
+			day(sf.arrives.Truncate(24 * time.Hour))
+		}
+		// A red-eye counts as the night it leaves on.
+		if h := sf.departs.Sub(t).Hours(); h >= 18 {
+			day(t).aloft = true
+		} else if h < 6 && sf.departs != t {
+			if prev, ok := s.days[t.AddDate(0, 0, -1)]; ok {
+				prev.aloft = true
+			}
+		}
+		s.flights = append(s.flights, sf)
+	}
+	sort.SliceStable(s.flights, func(i, j int) bool { return s.flights[i].departs.Before(s.flights[j].departs) })
+
+	s.transfers = planTransfers(s)
+	return s
+}
+
+// planTransfers adds a ground transfer wherever the travellers change
+// place: from the airport to the hotel they check in to on arrival, between
+// hotels on the same day and from the last hotel to the departing flight.
+func planTransfers(s tripSchedule) []scheduledTransfer {
+	var transfers []scheduledTransfer
+	for _, f := range s.flights {
+		arrival := f.arrives
+		if arrival.IsZero() {
+			arrival = f.departs
+		}
+		arrivalDay := arrival.Truncate(24 * time.Hour)
+		for _, stay := range s.stays {
+			if stay.in.Equal(arrivalDay) {
+				transfers = append(transfers, scheduledTransfer{at: arrival.Add(time.Hour), from: f.flight.To, to: stay.hotel.Name})
+				break
+			}
+		}
+		for _, stay := range s.stays {
+			if stay.out.Equal(f.departs.Truncate(24*time.Hour)) && f.departs.After(stay.out) {
+				// Leave for the airport about three hours ahead.
+				transfers = append(transfers, scheduledTransfer{at: f.departs.Add(-3 * time.Hour), from: stay.hotel.Name, to: f.flight.From})
+				break
+			}
+		}
+	}
+	for i, a := range s.stays {
+		for _, b := range s.stays[i+1:] {
+			if a.out.Equal(b.in) && a.hotel.Name != b.hotel.Name {
+				transfers = append(transfers, scheduledTransfer{at: atHour(a.out, 12), from: a.hotel.Name, to: b.hotel.Name})
+			}
+		}
+	}
+	sort.SliceStable(transfers, func(i, j int) bool { return transfers[i].at.Before(transfers[j].at) })
+	return transfers
+}
+
+func (s tripSchedule) empty() bool {
+	return len(s.days) == 0
+}
+
+// dayCount is the number of dates the schedule spans.
+func (s tripSchedule) dayCount() int {
+	return int(s.last.Sub(s.first).Hours()/24) + 1
+}
+
+// weekStart returns the Monday on or before t.
+func weekStart(t time.Time) time.Time {
+	return t.AddDate(0, 0, -(int(t.Weekday())+6)%7)
+}
+
+// weekdayNames are the calendar column headings, Monday first.
+var weekdayNames = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
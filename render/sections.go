@@ -0,0 +1,20 @@
+package render
+
+func init() {
+	Register("cover", described(SectionFunc(addCoverPage), cover))
+	Register("toc", described(SectionFunc(addTableOfContents), contents))
+	Register("route", described(NewSection("Trip Route", addTripRoute), tripRoute))
+	Register("daily", described(NewSection("Daily Itinerary", addDailyItinerary), dailyPlan))
+	Register("flights", described(NewSection("Flight Summary", addFlightSummary), flightSummary))
+	Register("hotels", described(NewSection("Hotel Bookings", addHotelBookings), hotelBookings))
+	Register("notes", described(NewSection("Important Notes", addNotesPage), importantNotes))
+	Register("scope", described(NewSection("Scope Of Service", addServiceScope), serviceScope))
+	Register("calendar", described(NewSection("Trip Calendar", addCalendar), tripCalendar))
+	Register("timeline", described(NewSection("Trip Timeline", addGantt), tripTimeline))
+	Register("activities", described(NewSection("Activity Table", addActivityTable), activityTable))
+	Register("terms", described(NewSection("Terms and Conditions", addTerms), terms))
+	Register("payment", described(NewSection("Payment Plan", addPaymentPlan), paymentPlan))
+	Register("visa", described(NewSection("Visa Details", addVisaDetails), visaDetails))
+	Register("closing", described(SectionFunc(addClosing), closing))
+	Register("summary", described(SectionFunc(addSummary), summary))
+}
@@ -0,0 +1,102 @@
+package render
+
+import (
+	"math"
+	"strings"
+
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+)
+
+// cancelledColor is the same in every theme so a cancelled itinerary never
+// passes for a live one.
+var cancelledColor = theme.RGB(185, 28, 28)
+
+// statusNames are the catalog messages for the statuses.
+var statusNames = map[string]string{
+	types.StatusQuote:       "Quote",
+	types.StatusProvisional: "Provisional",
+	types.StatusCancelled:   "Cancelled",
+}
+
+// statusLabel is the watermark and badge text, empty for final documents.
+func statusLabel(l *i18n.Locale, status string) string {
+	name, ok := statusNames[status]
+	if !ok {
+		return ""
+	}
+	return strings.ToUpper(l.T(name))
+}
+
+func (ctx *Context) statusColor() theme.Color {
+	if ctx.Data.Status == types.StatusCancelled {
+		return cancelledColor
+	}
+	return ctx.Theme.Palette.Primary
+}
+
+// quoteValidUntil is the last day a quote's prices hold.
+func (ctx *Context) quoteValidUntil() (string, bool) {
+	if ctx.Data.Status != types.StatusQuote || ctx.QuoteValidityDays <= 0 {
+		return "", false
+	}
+	issued := ctx.created
+	if date, err := utils.ConvertStringToTime(ctx.Data.IssueDate); err == nil {
+		issued = date
+	}
+	return ctx.Locale.FormatTime(issued.AddDate(0, 0, ctx.QuoteValidityDays)), true
+}
+
+// drawWatermark writes the status across the page along its diagonal, faint
+// enough to read the page through it.
+func drawWatermark(ctx *Context) {
+	label := statusLabel(ctx.Locale, ctx.Data.Status)
+	if label == "" {
+		return
+	}
+	pdf := ctx.PDF
+	w, h := ctx.PageWidth(), ctx.PageHeight()
+
+	// Size the text to two thirds of the diagonal.
+	ctx.Font("B", 100)
+	size := min(120, 100*math.Hypot(w, h)*2/3/ctx.StringWidth(label))
+	ctx.Font("B", size)
+	lineH := size * 0.3528
+
+	pdf.TransformBegin()
+	pdf.TransformRotate(math.Atan2(h, w)*180/math.Pi, w/2, h/2)
+	pdf.SetAlpha(0.1, "Normal")
+	ctx.TextColor(ctx.statusColor())
+	pdf.SetXY(0, (h-lineH)/2)
+	ctx.CellFormat(w, lineH, label, "", 0, "C", false, 0, "")
+	pdf.SetAlpha(1, "Normal")
+	pdf.TransformEnd()
+}
+
+// drawStatusBadge puts the status in a pill at the top right of the cover,
+// with the valid-until date of a quote below it.
+func drawStatusBadge(ctx *Context, y float64) {
+	label := statusLabel(ctx.Locale, ctx.Data.Status)
+	if label == "" {
+		return
+	}
+	pdf := ctx.PDF
+
+	ctx.Font("B", 9)
+	w := ctx.StringWidth(label) + 8
+	x := ctx.Right() - w
+	ctx.Fill(ctx.statusColor())
+	pdf.RoundedRect(x, y, w, 7, 3.5, "1234", "F")
+	pdf.SetXY(x, y)
+	ctx.TextColor(ctx.Theme.Palette.OnPrimary)
+	ctx.CellFormat(w, 7, label, "", 0, "C", false, 0, "")
+
+	if until, ok := ctx.quoteValidUntil(); ok {
+		ctx.Font("", 7)
+		ctx.TextColor(ctx.Theme.Palette.Muted)
+		pdf.SetXY(ctx.Left(), y+8)
+		ctx.CellFormat(ctx.ContentWidth(), 4, ctx.T("Valid until %s", until), "", 0, "R", false, 0, "")
+	}
+}
@@ -0,0 +1,198 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/monoMonu/travel-itinerary-pdf/markdown"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+)
+
+// ErrSummaryTooLong is returned when a trip has too much in it for the
+// one-page summary even at the smallest font size.
+var ErrSummaryTooLong = errors.New("trip does not fit on one page")
+
+// The summary shrinks its body font from summaryMaxSize towards
+// summaryMinSize until everything fits. Every entry is a single line, so
+// the height is proportional to the font size and the size can be worked
+// out instead of searched for.
+const (
+	summaryMaxSize = 10.0
+	summaryMinSize = 6.0
+	// summaryLeading is the line height in mm per point of font size.
+	summaryLeading = 0.5
+)
+
+// Summary is the one-page summary, a line per entry.
+type Summary struct {
+	Lines []SummaryLine
+}
+
+func (*Summary) content() {}
+
+// SummaryLine is one line of the summary. Columns are fractions of the
+// content width; text that does not fit its column is cut short.
+type SummaryLine struct {
+	// Scale is the font size relative to the body size.
+	Scale   float64
+	Style   string
+	Heading bool
+	Color   theme.Color
+	// Gap is space above the line, in lines.
+	Gap     float64
+	Columns []float64
+	Cells   []string
+	Aligns  string
+}
+
+func (l SummaryLine) height(size float64) float64 {
+	return (l.Gap + l.Scale) * size * summaryLeading
+}
+
+func addSummary(ctx *Context) error {
+	lines := summary(ctx).Lines
+
+	ctx.NewPage()
+	available := ctx.ContentBottom() - ctx.PDF.GetY()
+	perPoint := 0.0
+	for _, line := range lines {
+		perPoint += line.height(1)
+	}
+	size := min(summaryMaxSize, math.Floor(available/perPoint*10)/10)
+	if size < summaryMinSize {
+		return fmt.Errorf("%w: it needs %.0f mm at %gpt and the page has %.0f mm",
+			ErrSummaryTooLong, perPoint*summaryMinSize, summaryMinSize, available)
+	}
+
+	for _, line := range lines {
+		drawSummaryLine(ctx, line, size)
+	}
+	return nil
+}
+
+// summary lists the trip header, one line per activity grouped by day, the
+// flights and hotels, and the amount due next.
+func summary(ctx *Context) *Summary {
+	data, p := ctx.Data, ctx.Theme.Palette
+	heading := func(title string) SummaryLine {
+		return SummaryLine{Scale: 1.3, Style: "B", Heading: true, Color: p.Accent, Gap: 0.8, Cells: []string{ctx.T(title)}}
+	}
+	row := func(columns []float64, aligns string, cells ...string) SummaryLine {
+		return SummaryLine{Scale: 1, Color: p.Text, Columns: columns, Cells: cells, Aligns: aligns}
+	}
+
+	nights := utils.CalculateNights(data.DepartureDate, data.ReturnDate)
+	trip := []string{
+		ctx.Date(data.DepartureDate) + " – " + ctx.Date(data.ReturnDate),
+		ctx.T("%d Days %d Nights", nights+1, nights),
+		ctx.T("No. Of Travellers") + ": " + ctx.Locale.FormatNumber(float64(data.Travelers), 0),
+	}
+	who := []string{data.CustomerName}
+	if data.BookingReference != "" {
+		who = append(who, ctx.T("Booking reference")+": "+data.BookingReference)
+	}
+	if until, ok := ctx.quoteValidUntil(); ok {
+		who = append(who, ctx.T("Valid until %s", until))
+	}
+	lines := []SummaryLine{
+		{Scale: 2, Style: "B", Heading: true, Color: p.Primary, Cells: []string{ctx.T("%s Itinerary", data.Destination)}},
+		{Scale: 1.1, Style: "B", Color: p.Text, Gap: 0.2, Cells: []string{strings.Join(who, "  ·  ")}},
+		{Scale: 1, Color: p.Muted, Cells: []string{strings.Join(trip, "  ·  ")}},
+	}
+
+	if len(data.Days) > 0 {
+		lines = append(lines, heading("Daily Itinerary"))
+		columns := []float64{0.12, 0.68, 0.2}
+		for i, day := range data.Days {
+			lines = append(lines, SummaryLine{
+				Scale: 1, Style: "B", Color: p.Text, Gap: 0.3,
+				Cells: []string{ctx.T("Day %d - %s", i+1, ctx.Date(day.Date))},
+			})
+			for _, activity := range day.Activities {
+				lines = append(lines, row(columns, "LLR",
+					activity.Time,
+					markdown.PlainText(activity.Title),
+					ctx.Locale.FormatDuration(activity.Duration, activity.Time)))
+			}
+		}
+	}
+
+	if len(data.Flights) > 0 {
+		lines = append(lines, heading("Flight Summary"))
+		columns := []float64{0.18, 0.3, 0.34, 0.18}
+		for _, flight := range data.Flights {
+			times := strings.Trim(flight.Departure+" – "+flight.Arrival, " –")
+			lines = append(lines, row(columns, "LLLR",
+				ctx.Date(flight.Date), flight.Airline, flight.From+" → "+flight.To, times))
+		}
+	}
+
+	if len(data.Hotels) > 0 {
+		lines = append(lines, heading("Hotel Bookings"))
+		columns := []float64{0.18, 0.42, 0.28, 0.12}
+		for _, hotel := range data.Hotels {
+			lines = append(lines, row(columns, "LLLR",
+				hotel.City, hotel.Name,
+				ctx.Date(hotel.CheckIn)+" – "+ctx.Date(hotel.CheckOut),
+				ctx.Locale.FormatNumber(float64(hotel.Nights), 0)+" "+ctx.T("Nights")))
+		}
+	}
+
+	for _, inst := range planInstallments(ctx) {
+		if inst.Amount > 0 {
+			lines = append(lines,
+				heading("Amount Due Next"),
+				row([]float64{0.5, 0.5}, "LR",
+					ctx.Amount(inst.Amount)+"  ·  "+inst.Due,
+					ctx.T("Total Amount")+": "+ctx.Amount(data.TotalAmount)))
+			break
+		}
+	}
+	return &Summary{Lines: lines}
+}
+
+func drawSummaryLine(ctx *Context, line SummaryLine, size float64) {
+	pdf := ctx.PDF
+	lineH := line.Scale * size * summaryLeading
+	pdf.SetY(pdf.GetY() + line.Gap*size*summaryLeading)
+
+	ctx.TextColor(line.Color)
+	if line.Heading {
+		ctx.HeadingFont(line.Style, line.Scale*size)
+	} else {
+		ctx.Font(line.Style, line.Scale*size)
+	}
+
+	columns := line.Columns
+	if len(columns) == 0 {
+		columns = []float64{1}
+	}
+	x, y := ctx.Left(), pdf.GetY()
+	for i, cell := range line.Cells {
+		w := columns[i] * ctx.ContentWidth()
+		align := "L"
+		if i < len(line.Aligns) {
+			align = line.Aligns[i : i+1]
+		}
+		pdf.SetXY(x, y)
+		ctx.CellFormat(w, lineH, ctx.truncate(cell, w), "", 0, align, false, 0, "")
+		x += w
+	}
+	pdf.SetXY(ctx.Left(), y+lineH)
+}
+
+// truncate shortens s with an ellipsis so that it fits in a cell of width w.
+func (ctx *Context) truncate(s string, w float64) string {
+	maxWidth := w - 2*ctx.PDF.GetCellMargin()
+	if ctx.StringWidth(s) <= maxWidth {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && ctx.StringWidth(string(runes)+"…") > maxWidth {
+		runes = runes[:len(runes)-1]
+	}
+	return strings.TrimRight(string(runes), " ") + "…"
+}
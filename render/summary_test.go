@@ -0,0 +1,62 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// summaryTrip is the sample booking as a summary with n activities a day
+// over days days.
+func summaryTrip(days, n int) types.BookingData {
+	data := sampleBooking()
+	data.Options.Variant = types.VariantSummary
+	data.Days = nil
+	for d := range days {
+		day := types.Day{Date: fmt.Sprintf("2025-07-%02d", d%28+1)}
+		for a := range n {
+			day.Activities = append(day.Activities, types.Activity{
+				Time: fmt.Sprintf("%02d:00", 8+a), Title: fmt.Sprintf("Activity %d of day %d with a title long enough to be cut short in its column", a+1, d+1), Duration: 90,
+			})
+		}
+		data.Days = append(data.Days, day)
+	}
+	return data
+}
+
+func TestSummaryFitsOnePage(t *testing.T) {
+	for _, pageSize := range []string{"A4", "A5"} {
+		fits := 0
+		for days := 1; ; days++ {
+			data := summaryTrip(days, 4)
+			data.Options.PageSize = pageSize
+			pdf, err := Build(data, Config{})
+			if errors.Is(err, ErrSummaryTooLong) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("%s, %d days: %v", pageSize, days, err)
+			}
+			if pdf.PageCount() != 1 {
+				t.Fatalf("%s, %d days: %d pages", pageSize, days, pdf.PageCount())
+			}
+			fits = days
+		}
+		// At 6pt an A4 page has room for about 70 lines.
+		if pageSize == "A4" && (fits < 8 || fits > 30) {
+			t.Errorf("A4 fits %d days of 4 activities", fits)
+		}
+		if pageSize == "A5" && fits < 3 {
+			t.Errorf("A5 fits %d days of 4 activities", fits)
+		}
+	}
+}
+
+func TestSummaryTooLong(t *testing.T) {
+	_, err := Build(summaryTrip(60, 5), Config{})
+	if !errors.Is(err, ErrSummaryTooLong) {
+		t.Fatalf("got %v, want ErrSummaryTooLong", err)
+	}
+}
@@ -0,0 +1,524 @@
+package render
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultTemplate is used when neither the request nor its API client picks
+// a template.
+const DefaultTemplate = "vigovia"
+
+// Template declares a layout: which sections a document has, in what order,
+// and the content of any sections that are not built in. Built-in sections
+// are named with Use; declared sections are made of blocks whose text is a
+// text/template executed on the booking.
+type Template struct {
+	Name        string            `json:"name" yaml:"name"`
+	Version     int               `json:"version" yaml:"version"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Sections    []TemplateSection `json:"sections" yaml:"sections"`
+
+	declared map[string]Section
+}
+
+// TemplateSection is either a built-in section named by Use, or a section
+// declared with a name and blocks.
+type TemplateSection struct {
+	Use string `json:"use,omitempty" yaml:"use,omitempty"`
+
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Title is the heading and the contents entry. It is translated.
+	Title string `json:"title,omitempty" yaml:"title,omitempty"`
+	// Page is "new" (the default) to start on a fresh page, or "continue"
+	// to follow the previous section when there is room.
+	Page   string  `json:"page,omitempty" yaml:"page,omitempty"`
+	Blocks []Block `json:"blocks,omitempty" yaml:"blocks,omitempty"`
+}
+
+// Block is one piece of a declared section. Exactly one of Heading, Text,
+// Table, Space and PageBreak is set.
+type Block struct {
+	// Heading is a translated subheading.
+	Heading string `json:"heading,omitempty" yaml:"heading,omitempty"`
+	// Text is a template executed on the booking, e.g.
+	// "Dear {{.CustomerName}}". Long text wraps.
+	Text      string  `json:"text,omitempty" yaml:"text,omitempty"`
+	Table     *Table  `json:"table,omitempty" yaml:"table,omitempty"`
+	Space     float64 `json:"space,omitempty" yaml:"space,omitempty"`
+	PageBreak bool    `json:"pageBreak,omitempty" yaml:"pageBreak,omitempty"`
+
+	// Style is any of B, I and U. Size is in points and defaults to 10.
+	Style string  `json:"style,omitempty" yaml:"style,omitempty"`
+	Size  float64 `json:"size,omitempty" yaml:"size,omitempty"`
+	// Align is L, C or R.
+	Align string `json:"align,omitempty" yaml:"align,omitempty"`
+	// Color names a theme palette color: text, muted, primary or accent.
+	Color string `json:"color,omitempty" yaml:"color,omitempty"`
+
+	text *template.Template
+}
+
+// Table draws one row per element of a list in the booking.
+type Table struct {
+	// Rows is a path of field names from the booking to a list, like
+	// "Hotels". Lists along the way are flattened, so "Days.Activities"
+	// gives every activity of the trip.
+	Rows    string   `json:"rows" yaml:"rows"`
+	Columns []Column `json:"columns" yaml:"columns"`
+
+	path []string
+}
+
+type Column struct {
+	// Header is translated.
+	Header string `json:"header" yaml:"header"`
+	// Width is relative to the other columns; the table fills the page.
+	Width float64 `json:"width" yaml:"width"`
+	// Value is a template executed on the row, e.g. "{{.Title}}".
+	Value string `json:"value" yaml:"value"`
+	// Align is L, C (the default) or R.
+	Align string `json:"align,omitempty" yaml:"align,omitempty"`
+
+	value *template.Template
+}
+
+var blockColors = []string{"text", "muted", "primary", "accent"}
+
+// SectionNames returns the names of the template's sections, in order.
+func (t *Template) SectionNames() []string {
+	names := make([]string, len(t.Sections))
+	for i, s := range t.Sections {
+		names[i] = s.Use
+		if s.Use == "" {
+			names[i] = s.Name
+		}
+	}
+	return names
+}
+
+// Resolve looks up the requested section names among the template's
+// declared sections and the built-in ones, falling back to the template's
+// own order when none are given.
+func (t *Template) Resolve(names []string) ([]Section, error) {
+	if len(names) == 0 {
+		names = t.SectionNames()
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	var unknown []string
+	sections := make([]Section, 0, len(names))
+	for _, name := range names {
+		section, ok := t.declared[name]
+		if !ok {
+			section, ok = registry[name]
+		}
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		sections = append(sections, section)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("unknown sections: %s", strings.Join(unknown, ", "))
+	}
+	return sections, nil
+}
+
+// compile checks the template and prepares its declared sections. Every
+// binding is tried on an empty booking and on one with an element in every
+// list and every pointer set, so a misspelt field fails here rather than on
+// a customer's request. Only the branches those two take are checked: a
+// field misspelt in, say, the else of an if on a list is still found late.
+func (t *Template) compile() error {
+	if t.Name == "" {
+		return errors.New("template has no name")
+	}
+	if t.Version < 1 {
+		return fmt.Errorf("template %q: version must be 1 or more", t.Name)
+	}
+	if len(t.Sections) == 0 {
+		return fmt.Errorf("template %q has no sections", t.Name)
+	}
+
+	t.declared = map[string]Section{}
+	seen := map[string]bool{}
+	for i := range t.Sections {
+		s := &t.Sections[i]
+		name := s.Use
+		if s.Use == "" {
+			name = s.Name
+		}
+		if err := s.compile(); err != nil {
+			return fmt.Errorf("template %q, section %d: %w", t.Name, i+1, err)
+		}
+		if seen[name] {
+			return fmt.Errorf("template %q: section %q appears twice", t.Name, name)
+		}
+		seen[name] = true
+		if s.Use == "" {
+			t.declared[name] = declaredSection{s}
+		}
+	}
+	return nil
+}
+
+func (s *TemplateSection) compile() error {
+	if s.Use != "" {
+		if s.Name != "" || s.Title != "" || s.Page != "" || len(s.Blocks) > 0 {
+			return fmt.Errorf("built-in section %q cannot declare content", s.Use)
+		}
+		registryMu.RLock()
+		_, ok := registry[s.Use]
+		registryMu.RUnlock()
+		if !ok {
+			return fmt.Errorf("unknown built-in section %q", s.Use)
+		}
+		return nil
+	}
+
+	if s.Name == "" {
+		return errors.New("section needs use or a name")
+	}
+	registryMu.RLock()
+	_, builtin := registry[s.Name]
+	registryMu.RUnlock()
+	if builtin {
+		return fmt.Errorf("%q is a built-in section; pick another name", s.Name)
+	}
+	if s.Page != "" && s.Page != "new" && s.Page != "continue" {
+		return fmt.Errorf("%s: page must be new or continue", s.Name)
+	}
+	if len(s.Blocks) == 0 {
+		return fmt.Errorf("%s: no blocks", s.Name)
+	}
+	for i := range s.Blocks {
+		if err := s.Blocks[i].compile(); err != nil {
+			return fmt.Errorf("%s, block %d: %w", s.Name, i+1, err)
+		}
+	}
+	return nil
+}
+
+func (b *Block) compile() error {
+	kinds := 0
+	for _, set := range []bool{b.Heading != "", b.Text != "", b.Table != nil, b.Space != 0, b.PageBreak} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return errors.New("set exactly one of heading, text, table, space and pageBreak")
+	}
+	if strings.Trim(strings.ToUpper(b.Style), "BIU") != "" {
+		return fmt.Errorf("style %q: use B, I and U", b.Style)
+	}
+	if b.Size < 0 || b.Space < 0 {
+		return errors.New("size and space cannot be negative")
+	}
+	if !validAlign(b.Align) {
+		return fmt.Errorf("align %q: use L, C or R", b.Align)
+	}
+	if b.Color != "" && !slices.Contains(blockColors, b.Color) {
+		return fmt.Errorf("color %q: use one of %s", b.Color, strings.Join(blockColors, ", "))
+	}
+
+	var err error
+	if b.Text != "" {
+		if b.text, err = parseBinding(b.Text, reflect.TypeFor[types.BookingData]()); err != nil {
+			return err
+		}
+	}
+	if b.Table != nil {
+		return b.Table.compile()
+	}
+	return nil
+}
+
+func (t *Table) compile() error {
+	row, err := bindRows(t.Rows)
+	if err != nil {
+		return err
+	}
+	t.path = strings.Split(t.Rows, ".")
+	if len(t.Columns) == 0 {
+		return errors.New("table has no columns")
+	}
+	for i := range t.Columns {
+		c := &t.Columns[i]
+		if c.Width <= 0 {
+			return fmt.Errorf("column %d: width must be positive", i+1)
+		}
+		if !validAlign(c.Align) {
+			return fmt.Errorf("column %d: align %q: use L, C or R", i+1, c.Align)
+		}
+		if c.value, err = parseBinding(c.Value, row); err != nil {
+			return fmt.Errorf("column %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func validAlign(align string) bool {
+	return align == "" || align == "L" || align == "C" || align == "R"
+}
+
+// parseBinding parses a text or cell template and runs it on an empty and
+// on a filled value of the type it will be given.
+func parseBinding(text string, dot reflect.Type) (*template.Template, error) {
+	tmpl, err := template.New("").Option("missingkey=error").Funcs(templateFuncs(i18n.Get(i18n.Default))).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range []reflect.Value{reflect.Zero(dot), filled(dot)} {
+		if err := tmpl.Execute(io.Discard, v.Interface()); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+// filled returns a value of typ with one element in every list and every
+// pointer set, so that bindings inside range and with run when checked.
+func filled(typ reflect.Type) reflect.Value {
+	v := reflect.New(typ).Elem()
+	switch typ.Kind() {
+	case reflect.Pointer:
+		v.Set(reflect.New(typ.Elem()))
+		v.Elem().Set(filled(typ.Elem()))
+	case reflect.Slice:
+		if typ.Elem().Kind() != reflect.Uint8 {
+			v.Set(reflect.Append(v, filled(typ.Elem())))
+		}
+	case reflect.Struct:
+		for i := range typ.NumField() {
+			if typ.Field(i).IsExported() {
+				v.Field(i).Set(filled(typ.Field(i).Type))
+			}
+		}
+	}
+	return v
+}
+
+// bindRows checks that a rows path leads from the booking through at least
+// one list, and returns the type of a row. Rows are structs or plain
+// values; byte strings such as images are not lists of rows.
+func bindRows(rows string) (reflect.Type, error) {
+	if rows == "" {
+		return nil, errors.New("table needs rows")
+	}
+	typ, lists := reflect.TypeFor[types.BookingData](), 0
+	for _, name := range strings.Split(rows, ".") {
+		if typ.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("rows %q: %s has no fields, so no %q", rows, typ, name)
+		}
+		field, ok := typ.FieldByName(name)
+		if !ok || !field.IsExported() {
+			return nil, fmt.Errorf("rows %q: %s has no field %q", rows, typ.Name(), name)
+		}
+		typ = field.Type
+		if typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
+			return nil, fmt.Errorf("rows %q: %s is not a list", rows, name)
+		}
+		if typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+			lists++
+		}
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+	}
+	if lists == 0 {
+		return nil, fmt.Errorf("rows %q is not a list", rows)
+	}
+	return typ, nil
+}
+
+// rowsOf follows a rows path from v, flattening lists and skipping nil
+// pointers.
+func rowsOf(v reflect.Value, path []string) []reflect.Value {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.Slice {
+		var rows []reflect.Value
+		for i := range v.Len() {
+			rows = append(rows, rowsOf(v.Index(i), path)...)
+		}
+		return rows
+	}
+	if len(path) == 0 {
+		return []reflect.Value{v}
+	}
+	return rowsOf(v.FieldByName(path[0]), path[1:])
+}
+
+// execute runs a compiled binding with the document's locale.
+func execute(ctx *Context, tmpl *template.Template, dot any) (string, error) {
+	tmpl, err := tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Funcs(templateFuncs(ctx.Locale)).Execute(&buf, dot); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+var (
+	templatesMu sync.RWMutex
+	templates   = map[string][]*Template{}
+)
+
+//go:embed templates/*.yaml
+var builtinFS embed.FS
+
+// loadBuiltins registers the templates shipped with the server. It runs on
+// first use rather than in init so that the built-in sections it refers to
+// are registered by then.
+var loadBuiltins = sync.OnceValue(func() error {
+	return loadTemplates(builtinFS, "templates")
+})
+
+// RegisterTemplate checks t and adds it, replacing any template with the
+// same name and version.
+func RegisterTemplate(t *Template) error {
+	if err := t.compile(); err != nil {
+		return err
+	}
+
+	templatesMu.Lock()
+	defer templatesMu.Unlock()
+	versions := slices.DeleteFunc(templates[t.Name], func(o *Template) bool { return o.Version == t.Version })
+	versions = append(versions, t)
+	slices.SortFunc(versions, func(a, b *Template) int { return a.Version - b.Version })
+	templates[t.Name] = versions
+	return nil
+}
+
+// GetTemplate returns a version of the named template, or its latest
+// version when version is zero. An empty name returns DefaultTemplate.
+func GetTemplate(name string, version int) (*Template, error) {
+	if err := loadBuiltins(); err != nil {
+		return nil, err
+	}
+	if name == "" {
+		name = DefaultTemplate
+	}
+
+	templatesMu.RLock()
+	defer templatesMu.RUnlock()
+	versions := templates[name]
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("unknown template %q", name)
+	}
+	if version == 0 {
+		return versions[len(versions)-1], nil
+	}
+	for _, t := range versions {
+		if t.Version == version {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("template %q has no version %d", name, version)
+}
+
+// Templates returns every registered template, by name and then version.
+func Templates() []*Template {
+	loadBuiltins()
+
+	templatesMu.RLock()
+	defer templatesMu.RUnlock()
+	var all []*Template
+	for _, versions := range templates {
+		all = append(all, versions...)
+	}
+	slices.SortFunc(all, func(a, b *Template) int {
+		if c := strings.Compare(a.Name, b.Name); c != 0 {
+			return c
+		}
+		return a.Version - b.Version
+	})
+	return all
+}
+
+// LoadTemplates registers the built-in templates and every *.yaml, *.yml and
+// *.json template in dir. A missing directory is not an error, so
+// deployments without their own layouts need no setup.
+func LoadTemplates(dir string) error {
+	if err := loadBuiltins(); err != nil {
+		return err
+	}
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return loadTemplates(os.DirFS(dir), ".")
+}
+
+func loadTemplates(fsys fs.FS, dir string) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		t, err := loadTemplate(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("template %s: %w", entry.Name(), err)
+		}
+		if err := RegisterTemplate(t); err != nil {
+			return fmt.Errorf("template %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+// loadTemplate decodes one file, rejecting fields the format does not have
+// so that typos are reported instead of ignored.
+func loadTemplate(fsys fs.FS, file string) (*Template, error) {
+	raw, err := fs.ReadFile(fsys, file)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Template{}
+	if strings.EqualFold(path.Ext(file), ".json") {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(t)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(raw))
+		dec.KnownFields(true)
+		err = dec.Decode(t)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
@@ -0,0 +1,105 @@
+package render
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+func tableTemplate(rows, value string) *Template {
+	return &Template{Name: "t", Version: 1, Sections: []TemplateSection{
+		{Use: "cover"},
+		{Name: "extra", Blocks: []Block{{Table: &Table{Rows: rows, Columns: []Column{{Header: "H", Width: 1, Value: value}}}}}},
+	}}
+}
+
+func textTemplate(text string) *Template {
+	return &Template{Name: "t", Version: 1, Sections: []TemplateSection{
+		{Name: "extra", Blocks: []Block{{Text: text}}},
+	}}
+}
+
+func TestTemplateCompileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		t    *Template
+		err  string
+	}{
+		{"rows through a string", tableTemplate("CustomerName.Length", "{{.}}"), `rows "CustomerName.Length": string has no fields`},
+		{"rows through a pointer", tableTemplate("Delivery.Missing", "{{.}}"), `rows "Delivery.Missing": Delivery has no field "Missing"`},
+		{"rows of image bytes", tableTemplate("CoverImage", "{{.}}"), `rows "CoverImage": CoverImage is not a list`},
+		{"rows of nested image bytes", tableTemplate("Days.Activities.Image", "{{.}}"), `rows "Days.Activities.Image": Image is not a list`},
+		{"rows not a list", tableTemplate("Delivery", "{{.}}"), `rows "Delivery" is not a list`},
+		{"unknown rows", tableTemplate("Hotel", "{{.Name}}"), `rows "Hotel": BookingData has no field "Hotel"`},
+		{"misspelt column", tableTemplate("Hotels", "{{.Nmae}}"), "can't evaluate field Nmae"},
+		{"misspelt field in range", textTemplate("{{range .Days}}{{.Dat}}{{end}}"), "can't evaluate field Dat"},
+		{"misspelt field in with", textTemplate("{{with .Delivery}}{{.Too}}{{end}}"), "can't evaluate field Too"},
+		{"unknown function", textTemplate("{{shout .CustomerName}}"), `function "shout" not defined`},
+		{"no version", &Template{Name: "t", Sections: []TemplateSection{{Use: "cover"}}}, "version must be 1 or more"},
+		{"unknown built-in", &Template{Name: "t", Version: 1, Sections: []TemplateSection{{Use: "covers"}}}, `unknown built-in section "covers"`},
+		{"section twice", &Template{Name: "t", Version: 1, Sections: []TemplateSection{{Use: "cover"}, {Use: "cover"}}}, `section "cover" appears twice`},
+		{"two kinds in a block", &Template{Name: "t", Version: 1, Sections: []TemplateSection{
+			{Name: "extra", Blocks: []Block{{Heading: "A", Text: "B"}}},
+		}}, "set exactly one of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.t.compile()
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("got %v, want an error with %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestTemplateCompiles(t *testing.T) {
+	for i, tt := range []*Template{
+		tableTemplate("Hotels", "{{.Name}}, {{nights .CheckIn .CheckOut}}"),
+		tableTemplate("Days.Activities", "{{.Title}}"),
+		tableTemplate("Delivery.To", "{{.}}"),
+		tableTemplate("Hotels.Location", "{{.Lat}}"),
+		textTemplate("{{with .Delivery}}{{range .To}}{{.}}{{end}}{{end}}{{if .Days}}{{(index .Days 0).Date}}{{end}}"),
+	} {
+		if err := tt.compile(); err != nil {
+			t.Errorf("template %d: %v", i+1, err)
+		}
+	}
+}
+
+func TestRowsOf(t *testing.T) {
+	data := types.BookingData{Hotels: []types.Hotel{
+		{Name: "A", Location: &types.Location{Lat: 1}},
+		{Name: "B"},
+		{Name: "C", Location: &types.Location{Lat: 3}},
+	}}
+	var lats []float64
+	for _, row := range rowsOf(reflect.ValueOf(data), []string{"Hotels", "Location"}) {
+		lats = append(lats, row.Interface().(types.Location).Lat)
+	}
+	if !reflect.DeepEqual(lats, []float64{1, 3}) {
+		t.Errorf("got %v, want the hotels with a location", lats)
+	}
+	if rows := rowsOf(reflect.ValueOf(data), []string{"Delivery", "To"}); len(rows) != 0 {
+		t.Errorf("got %d rows from a nil delivery", len(rows))
+	}
+}
+
+// A bad template in TEMPLATES_DIR is an error at startup, not a panic.
+func TestLoadTemplatesError(t *testing.T) {
+	dir := t.TempDir()
+	bad := "name: bad\nversion: 1\nsections:\n  - name: extra\n    blocks:\n      - table:\n          rows: CustomerName.Length\n          columns:\n            - {header: H, width: 1, value: \"{{.}}\"}\n"
+	if err := os.WriteFile(filepath.Join(dir, "bad.yaml"), []byte(bad), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := LoadTemplates(dir)
+	if err == nil || !strings.Contains(err.Error(), "template bad.yaml") {
+		t.Fatalf("got %v", err)
+	}
+	if _, err := GetTemplate("bad", 0); err == nil {
+		t.Error("the bad template was registered")
+	}
+}
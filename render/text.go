@@ -0,0 +1,179 @@
+package render
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/monoMonu/travel-itinerary-pdf/fonts"
+)
+
+// All text goes through these methods instead of gofpdf's Cell, MultiCell and
+// SplitLines. They pick a font per character from the theme family and the
+// fonts.Fallbacks chain, and measure with the real glyph advances, so wrapped
+// row heights match what is drawn for any script.
+
+type fontState struct {
+	family    *fonts.Family
+	style     string
+	underline bool
+	size      float64
+}
+
+func (ctx *Context) setFont(familyName, style string, size float64) {
+	family, ok := fonts.Lookup(familyName)
+	if !ok {
+		family, _ = fonts.Lookup(fonts.Sans)
+	}
+	ctx.font = fontState{
+		family:    family,
+		style:     strings.ReplaceAll(strings.ToUpper(style), "U", ""),
+		underline: strings.Contains(strings.ToUpper(style), "U"),
+		size:      size,
+	}
+	// Keep gofpdf's own state in step for anything that still reads it,
+	// like the underline position.
+	ctx.useFace(ctx.font.family.Face(ctx.font.style))
+}
+
+// useFace registers face with the document on first use and selects it.
+func (ctx *Context) useFace(face *fonts.Face) {
+	if ctx.faces == nil {
+		ctx.faces = map[*fonts.Face]bool{}
+	}
+	if !ctx.faces[face] {
+		ctx.PDF.AddUTF8FontFromBytes(face.Family, face.Style, face.Data)
+		ctx.faces[face] = true
+	}
+
+	style := face.Style
+	if ctx.font.underline {
+		style += "U"
+	}
+	ctx.PDF.SetFont(face.Family, style, ctx.font.size)
+}
+
+// StringWidth returns the width of s in the current font, in user units.
+func (ctx *Context) StringWidth(s string) float64 {
+	s = fonts.Shape(s)
+	return ctx.units(fonts.Width(s, ctx.font.family, ctx.font.style))
+}
+
+func (ctx *Context) units(thousandths float64) float64 {
+	return thousandths * ctx.font.size / 1000 / ctx.PDF.GetConversionRatio()
+}
+
+// SplitLines wraps s to fit in a cell of width w, breaking at spaces and at
+// explicit newlines, and breaking words that do not fit on a line of their
+// own.
+func (ctx *Context) SplitLines(s string, w float64) []string {
+	s = fonts.Shape(strings.ReplaceAll(s, "\r", ""))
+	s = strings.TrimRight(s, "\n")
+	maxWidth := w - 2*ctx.PDF.GetCellMargin()
+
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		lines = append(lines, ctx.wrap(paragraph, maxWidth)...)
+	}
+	return lines
+}
+
+func (ctx *Context) wrap(paragraph string, maxWidth float64) []string {
+	var lines []string
+	var line []rune
+	lineWidth := 0.0
+	lastSpace := -1
+
+	for _, r := range paragraph {
+		rw := ctx.units(fonts.FaceFor(r, ctx.font.family, ctx.font.style).Advance(r))
+		if lineWidth+rw > maxWidth && len(line) > 0 && !unicode.IsSpace(r) {
+			if lastSpace >= 0 {
+				lines = append(lines, strings.TrimRight(string(line[:lastSpace]), " "))
+				line = append([]rune{}, line[lastSpace+1:]...)
+			} else {
+				lines = append(lines, string(line))
+				line = line[:0]
+			}
+			lineWidth = ctx.units(fonts.Width(string(line), ctx.font.family, ctx.font.style))
+			lastSpace = -1
+		}
+		if unicode.IsSpace(r) {
+			lastSpace = len(line)
+		}
+		line = append(line, r)
+		lineWidth += rw
+	}
+	return append(lines, strings.TrimRight(string(line), " "))
+}
+
+// Cell draws s at the cursor in a cell of width w and height h.
+func (ctx *Context) Cell(w, h float64, s string) {
+	ctx.CellFormat(w, h, s, "", 0, "L", false, 0, "")
+}
+
+// TextLink draws s at the cursor in a cell as wide as the text, linked to url
+// when it is not empty, and moves the cursor past it.
+func (ctx *Context) TextLink(h float64, s, url string) {
+	w := ctx.StringWidth(s) + 2*ctx.PDF.GetCellMargin()
+	ctx.CellFormat(w, h, s, "", 0, "L", false, 0, url)
+}
+
+// CellFormat behaves like gofpdf's CellFormat.
+func (ctx *Context) CellFormat(w, h float64, s, border string, ln int, align string, fill bool, link int, linkStr string) {
+	pdf := ctx.PDF
+	x, y := pdf.GetXY()
+	if w == 0 {
+		pageW, _ := pdf.GetPageSize()
+		_, _, right, _ := pdf.GetMargins()
+		w = pageW - right - x
+	}
+
+	// Let gofpdf draw the box and fill and move the cursor. It only links
+	// text it draws itself, so the link covers the whole cell here instead.
+	pdf.CellFormat(w, h, "", border, ln, "", fill, 0, "")
+	switch {
+	case link != 0:
+		pdf.Link(x, y, w, h, link)
+	case linkStr != "":
+		pdf.LinkString(x, y, w, h, linkStr)
+	}
+	if s == "" {
+		return
+	}
+
+	s = fonts.Visual(fonts.Shape(s))
+	textW := ctx.units(fonts.Width(s, ctx.font.family, ctx.font.style))
+	margin := pdf.GetCellMargin()
+
+	textX := x + margin
+	switch {
+	case strings.Contains(align, "C"):
+		textX = x + (w-textW)/2
+	case strings.Contains(align, "R"):
+		textX = x + w - margin - textW
+	}
+	baseline := y + h/2 + 0.3*ctx.font.size/pdf.GetConversionRatio()
+
+	afterX, afterY := pdf.GetXY()
+	ctx.drawRuns(textX, baseline, s)
+	ctx.useFace(ctx.font.family.Face(ctx.font.style))
+	pdf.SetXY(afterX, afterY)
+}
+
+// MultiCell wraps s into lines of height h inside width w, like gofpdf's
+// MultiCell with no border.
+func (ctx *Context) MultiCell(w, h float64, s, align string, fill bool) {
+	pdf := ctx.PDF
+	x := pdf.GetX()
+	if w == 0 {
+		pageW, _ := pdf.GetPageSize()
+		_, _, right, _ := pdf.GetMargins()
+		w = pageW - right - x
+	}
+
+	for _, line := range ctx.SplitLines(s, w) {
+		pdf.SetX(x)
+		ctx.CellFormat(w, h, line, "", 2, align, fill, 0, "")
+	}
+	left, _, _, _ := pdf.GetMargins()
+	pdf.SetX(left)
+}
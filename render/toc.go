@@ -0,0 +1,116 @@
+package render
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// The contents page is drawn before the sections it lists, so it cannot know
+// their page numbers yet. Each entry gets an internal link and a page number
+// alias up front; both are filled in when the section draws its heading, and
+// gofpdf substitutes the aliases when the document is written.
+
+const tocRowHeight = 9.0
+
+type tocEntry struct {
+	title string
+	link  int
+	alias string
+	page  int
+}
+
+func (e *tocEntry) resolve(ctx *Context, page int, y float64) {
+	e.page = page
+	ctx.PDF.SetLink(e.link, y, page)
+	ctx.PDF.RegisterAlias(e.alias, strconv.Itoa(page))
+}
+
+// newContents returns one entry per section, nil for sections without a
+// title.
+func newContents(ctx *Context, sections []Section) []*tocEntry {
+	entries := make([]*tocEntry, len(sections))
+	for i, section := range sections {
+		titled, ok := section.(Titled)
+		if !ok || titled.Title() == "" {
+			continue
+		}
+		entries[i] = &tocEntry{
+			title: titled.Title(),
+			link:  ctx.PDF.AddLink(),
+			alias: fmt.Sprintf("{toc:%d}", i),
+		}
+	}
+	return entries
+}
+
+// anchorSection points the current entry at the first page the section added
+// when the section drew no heading of its own.
+func (ctx *Context) anchorSection(firstPage int) {
+	if e := ctx.entry; e != nil && e.page == 0 && ctx.PDF.PageCount() >= firstPage {
+		e.resolve(ctx, firstPage, contentTop)
+	}
+}
+
+// finishContents blanks the page numbers of sections that drew nothing and
+// points their links back at the contents page, since a link left unset
+// leads nowhere.
+func (ctx *Context) finishContents() {
+	for _, e := range ctx.contents {
+		if e != nil && e.page == 0 {
+			ctx.PDF.RegisterAlias(e.alias, "-")
+			if ctx.contentsPage > 0 {
+				ctx.PDF.SetLink(e.link, contentTop, ctx.contentsPage)
+			}
+		}
+	}
+}
+
+// Bookmark adds an outline entry at the cursor. Level 0 is a section, level 1
+// an entry inside it.
+func (ctx *Context) Bookmark(title string, level int) {
+	// gofpdf encodes the title for the current font, so make sure one of
+	// ours is selected.
+	if ctx.font.family == nil {
+		ctx.Font("", 10)
+	}
+	ctx.PDF.Bookmark(title, level, -1)
+}
+
+func addTableOfContents(ctx *Context) error {
+	pdf, p := ctx.PDF, ctx.Theme.Palette
+
+	ctx.NewPage()
+	ctx.contentsPage = pdf.PageNo()
+	ctx.Heading("Contents", 15)
+
+	for _, e := range ctx.contents {
+		if e == nil {
+			continue
+		}
+		ctx.Continue(0, tocRowHeight)
+
+		y := pdf.GetY()
+		ctx.TextColor(p.Text)
+		ctx.Font("", 11)
+		pdf.SetX(ctx.Left())
+		ctx.CellFormat(ctx.ContentWidth()-20, tocRowHeight, ctx.T(e.title), "", 0, "L", false, e.link, "")
+
+		// The alias is wider than the number it stands for, so the column is
+		// left-aligned rather than measured.
+		ctx.TextColor(p.Muted)
+		pdf.SetX(ctx.Right() - 15)
+		ctx.CellFormat(15, tocRowHeight, e.alias, "", 1, "L", false, e.link, "")
+
+		ctx.Stroke(p.Border)
+		pdf.Line(ctx.Left(), y+tocRowHeight, ctx.Right(), y+tocRowHeight)
+	}
+	return nil
+}
+
+// Contents is where the table of contents goes. Other formats list the
+// titled sections of the Document there.
+type Contents struct{}
+
+func (*Contents) content() {}
+
+func contents(*Context) *Contents { return &Contents{} }
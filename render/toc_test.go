@@ -0,0 +1,76 @@
+package render
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// tocRowRe is a contents row: the title, then, after any font changes, the
+// page number its alias was replaced with.
+var tocRowRe = regexp.MustCompile(`Td \(([^)]+)\) Tj ET Q\n(?:BT [^\n]* Tf ET\n)*[^\n]*Td \(([\d-]+|\{toc:\d+\})\) Tj ET Q`)
+
+// The contents page is drawn before the sections, so its page numbers are
+// aliases; they and the links must end up at the pages the sections start
+// on, even after a day runs over several pages.
+func TestContentsPages(t *testing.T) {
+	data := sampleBooking()
+	data.Options.TableOfContents = true
+	for i := range 12 {
+		data.Days[0].Activities = append(data.Days[0].Activities, types.Activity{Time: fmt.Sprintf("%02d:00", i), Description: words("w", 40)})
+	}
+	pages := renderPages(t, data, Config{})
+
+	const contents = 2
+	if !strings.Contains(pages[contents-1].text, "(Contents) Tj") {
+		t.Fatalf("page %d is not the contents page", contents)
+	}
+	rows := tocRowRe.FindAllStringSubmatch(pages[contents-1].text, -1)
+	dests := pages[contents-1].dests
+	if len(rows) < 5 || len(dests) != 2*len(rows) {
+		t.Fatalf("got %d rows with %d links", len(rows), len(dests))
+	}
+
+	daily := 0
+	for i, row := range rows {
+		title, number := row[1], row[2]
+		// A section starts on the first page after the contents with its
+		// heading.
+		start := 0
+		for n := contents + 1; n <= len(pages) && start == 0; n++ {
+			if strings.Contains(pages[n-1].text, "("+title+") Tj") {
+				start = n
+			}
+		}
+		want, link := "-", contents
+		if start > 0 {
+			want, link = strconv.Itoa(start), start
+		}
+		if number != want {
+			t.Errorf("%s is listed on page %s, want %s", title, number, want)
+		}
+		if dests[2*i] != link || dests[2*i+1] != link {
+			t.Errorf("%s links to pages %d and %d, want %d", title, dests[2*i], dests[2*i+1], link)
+		}
+		if title == "Daily Itinerary" {
+			daily = start
+		}
+	}
+
+	// The day takes several pages, and the section after it starts on the
+	// next one.
+	if daily == 0 || rows[2][1] != "Flight Summary" {
+		t.Fatalf("rows %q", rows)
+	}
+	if flights, _ := strconv.Atoi(rows[2][2]); flights < daily+3 {
+		t.Errorf("the daily itinerary starts on page %d and the flights on %d", daily, flights)
+	}
+	// The sample has no coordinates for a route, so that section is empty.
+	if rows[0][1] != "Trip Route" || rows[0][2] != "-" {
+		t.Errorf("the first row is %q", rows[0][1:])
+	}
+}
@@ -0,0 +1,160 @@
+package schema
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Operation describes one HTTP route. Request and Response are zero values of
+// the Go types the handler binds and returns; nil means no JSON body.
+type Operation struct {
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Request     any
+	Response    any
+	// Multipart names the form field carrying Request as JSON when the route
+	// also accepts multipart/form-data with file uploads in other fields.
+	Multipart string
+	// Form is the zero value of a struct describing a multipart/form-data
+	// body, for routes that take only uploads. Fields tagged
+	// `format:"binary"` are files.
+	Form any
+	// ContentType of a successful response when it is not JSON,
+	// e.g. "application/pdf".
+	ContentType string
+	// Errors lists the non-2xx statuses the handler may answer with.
+	Errors []int
+}
+
+var ginParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+
+// OpenAPIPath converts a gin route path ("/pdfs/*filepath") to OpenAPI
+// syntax ("/pdfs/{filepath}").
+func OpenAPIPath(path string) string {
+	return ginParam.ReplaceAllString(path, "{$1}")
+}
+
+// OpenAPI builds an OpenAPI 3.1 document for ops. errorType is the body
+// returned with every status listed in Operation.Errors.
+func OpenAPI(title, version string, ops []Operation, errorType any) Schema {
+	gen := NewGenerator("#/components/schemas/")
+	paths := Schema{}
+
+	for _, op := range ops {
+		path := OpenAPIPath(op.Path)
+		item, _ := paths[path].(Schema)
+		if item == nil {
+			item = Schema{}
+			paths[path] = item
+		}
+
+		operation := Schema{
+			"summary":     op.Summary,
+			"operationId": operationID(op),
+			"responses":   responses(gen, op, errorType),
+		}
+		if op.Description != "" {
+			operation["description"] = op.Description
+		}
+		if params := pathParams(op.Path); len(params) > 0 {
+			operation["parameters"] = params
+		}
+		if op.Request != nil || op.Form != nil {
+			operation["requestBody"] = requestBody(gen, op)
+		}
+		item[strings.ToLower(op.Method)] = operation
+	}
+
+	return Schema{
+		"openapi": "3.1.0",
+		"info":    Schema{"title": title, "version": version},
+		"paths":   paths,
+		"components": Schema{
+			"schemas": gen.Defs,
+		},
+	}
+}
+
+func requestBody(gen *Generator, op Operation) Schema {
+	if op.Form != nil {
+		form := gen.Schema(reflect.TypeOf(op.Form))
+		return Schema{"required": true, "content": Schema{"multipart/form-data": Schema{"schema": form}}}
+	}
+	body := gen.Schema(reflect.TypeOf(op.Request))
+	content := Schema{"application/json": Schema{"schema": body}}
+	if op.Multipart != "" {
+		content["multipart/form-data"] = Schema{"schema": Schema{
+			"type": "object",
+			"properties": Schema{
+				op.Multipart: Schema{
+					"type":             "string",
+					"contentMediaType": "application/json",
+					"contentSchema":    body,
+				},
+			},
+			"required":             []string{op.Multipart},
+			"additionalProperties": Schema{"type": "string", "format": "binary"},
+		}}
+	}
+	return Schema{"required": true, "content": content}
+}
+
+func responses(gen *Generator, op Operation, errorType any) Schema {
+	success := Schema{"description": http.StatusText(http.StatusOK)}
+	switch {
+	case op.ContentType != "":
+		success["content"] = Schema{
+			op.ContentType: Schema{"schema": Schema{"type": "string", "format": "binary"}},
+		}
+	case op.Response != nil:
+		success["content"] = Schema{
+			"application/json": Schema{"schema": gen.Schema(reflect.TypeOf(op.Response))},
+		}
+	}
+
+	out := Schema{"200": success}
+	for _, status := range op.Errors {
+		resp := Schema{"description": http.StatusText(status)}
+		if errorType != nil {
+			resp["content"] = Schema{
+				"application/json": Schema{"schema": gen.Schema(reflect.TypeOf(errorType))},
+			}
+		}
+		out[strconv.Itoa(status)] = resp
+	}
+	return out
+}
+
+func pathParams(path string) []Schema {
+	var params []Schema
+	for _, match := range ginParam.FindAllStringSubmatch(path, -1) {
+		params = append(params, Schema{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   Schema{"type": "string"},
+		})
+	}
+	return params
+}
+
+func operationID(op Operation) string {
+	parts := strings.FieldsFunc(op.Path, func(r rune) bool {
+		return r == '/' || r == '-' || r == '.' || r == ':' || r == '*' || r == '_'
+	})
+	if len(parts) == 0 {
+		parts = []string{"root"}
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.ToLower(op.Method))
+	for _, part := range parts {
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
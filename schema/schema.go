@@ -0,0 +1,208 @@
+package schema
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema (draft 2020-12) object. It is kept as a plain map so
+// it can be embedded as-is into an OpenAPI 3.1 document.
+type Schema map[string]any
+
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Generator turns Go types into JSON Schemas. Named struct types are emitted
+// once into Defs and referenced by "$ref" everywhere they are used.
+type Generator struct {
+	// RefPrefix is prepended to the type name in every "$ref",
+	// e.g. "#/$defs/" or "#/components/schemas/".
+	RefPrefix string
+	Defs      map[string]Schema
+}
+
+func NewGenerator(refPrefix string) *Generator {
+	return &Generator{RefPrefix: refPrefix, Defs: map[string]Schema{}}
+}
+
+// Of builds a self-contained JSON Schema document for the type of v.
+func Of(v any) Schema {
+	gen := NewGenerator("#/$defs/")
+	root := gen.Schema(reflect.TypeOf(v))
+
+	doc := Schema{"$schema": Draft}
+	if ref, ok := root["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, gen.RefPrefix)
+		for k, val := range gen.Defs[name] {
+			doc[k] = val
+		}
+		delete(gen.Defs, name)
+		doc["title"] = name
+	} else {
+		for k, val := range root {
+			doc[k] = val
+		}
+	}
+	if len(gen.Defs) > 0 {
+		doc["$defs"] = gen.Defs
+	}
+	return doc
+}
+
+// Schema returns the schema for t, registering any named structs in g.Defs.
+func (g *Generator) Schema(t reflect.Type) Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return Schema{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string", "contentEncoding": "base64"}
+		}
+		return Schema{"type": "array", "items": g.Schema(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": g.Schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		if _, ok := g.Defs[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate.
+			g.Defs[t.Name()] = Schema{}
+			g.Defs[t.Name()] = g.object(t)
+		}
+		return Schema{"$ref": g.RefPrefix + t.Name()}
+	}
+
+	// interface{} and anything else is left unconstrained.
+	return Schema{}
+}
+
+func (g *Generator) object(t reflect.Type) Schema {
+	properties := Schema{}
+	var required []string
+	g.fields(t, properties, &required)
+
+	obj := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		obj["required"] = required
+	}
+	return obj
+}
+
+func (g *Generator) fields(t reflect.Type, properties Schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.fields(embedded, properties, required)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := g.Schema(field.Type)
+		if format := field.Tag.Get("format"); format != "" {
+			prop = withKeyword(prop, "format", format)
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			prop = withKeyword(prop, "enum", strings.Split(enum, "|"))
+		}
+		if doc := field.Tag.Get("doc"); doc != "" {
+			prop = withKeyword(prop, "description", doc)
+		}
+		properties[name] = withBinding(prop, field.Tag.Get("binding"))
+
+		if strings.Contains(field.Tag.Get("binding"), "required") && !strings.Contains(opts, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// withBinding adds the keywords for the validation rules in a binding tag
+// that the format and enum tags cannot give: bounds and email addresses.
+// Rules after "dive" apply to the items of an array.
+func withBinding(prop Schema, binding string) Schema {
+	rules := strings.Split(binding, ",")
+	if i := slices.Index(rules, "dive"); i >= 0 {
+		if items, ok := prop["items"].(Schema); ok {
+			prop = withKeyword(prop, "items", withBinding(items, strings.Join(rules[i+1:], ",")))
+		}
+		rules = rules[:i]
+	}
+	for _, rule := range rules {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "email":
+			prop = withKeyword(prop, "format", "email")
+		case "min", "max":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			prop = withKeyword(prop, boundKeyword(prop, name), n)
+		}
+	}
+	return prop
+}
+
+// boundKeyword is the keyword for a min or max rule, which bounds the
+// length of strings and arrays and the value of numbers.
+func boundKeyword(prop Schema, rule string) string {
+	suffix := map[any]string{"string": "Length", "array": "Items"}[prop["type"]]
+	if suffix == "" {
+		return map[string]string{"min": "minimum", "max": "maximum"}[rule]
+	}
+	return rule + suffix
+}
+
+// withKeyword adds a keyword to prop. "$ref" siblings are allowed in 2020-12,
+// but array items are where a format or enum belongs for []string fields.
+func withKeyword(prop Schema, key string, value any) Schema {
+	if (key == "format" || key == "enum") && prop["type"] == "array" {
+		if items, ok := prop["items"].(Schema); ok {
+			prop["items"] = withKeyword(items, key, value)
+			return prop
+		}
+	}
+	out := Schema{}
+	for k, v := range prop {
+		out[k] = v
+	}
+	out[key] = value
+	return out
+}
@@ -0,0 +1,128 @@
+package schema
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+// TestBindingTags checks that the schemas published for request bodies
+// say what the server validates: every rule in a binding tag has its
+// keyword, and required fields are listed as required.
+func TestBindingTags(t *testing.T) {
+	for _, v := range []any{types.BookingData{}, types.FlightTextRequest{}} {
+		doc := Of(v)
+		defs, _ := doc["$defs"].(map[string]Schema)
+		seen := map[reflect.Type]bool{}
+		var check func(typ reflect.Type, obj Schema)
+		check = func(typ reflect.Type, obj Schema) {
+			if seen[typ] {
+				return
+			}
+			seen[typ] = true
+			properties, _ := obj["properties"].(Schema)
+			required, _ := obj["required"].([]string)
+			for i := 0; i < typ.NumField(); i++ {
+				field := typ.Field(i)
+				name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+				if name == "" || name == "-" {
+					continue
+				}
+				prop, ok := properties[name].(Schema)
+				if !ok {
+					t.Errorf("%s.%s: no property %q", typ.Name(), field.Name, name)
+					continue
+				}
+				binding := field.Tag.Get("binding")
+				rules := strings.Split(binding, ",")
+				wantRequired := slices.Contains(rules, "required") && !strings.Contains(opts, "omitempty")
+				if slices.Contains(required, name) != wantRequired {
+					t.Errorf("%s.%s: required = %v, want %v", typ.Name(), field.Name, !wantRequired, wantRequired)
+				}
+				checkRules(t, typ.Name()+"."+field.Name, prop, rules)
+
+				elem := field.Type
+				for elem.Kind() == reflect.Pointer || elem.Kind() == reflect.Slice || elem.Kind() == reflect.Map {
+					elem = elem.Elem()
+				}
+				if elem.Kind() == reflect.Struct && elem.Name() != "" && elem != timeType {
+					check(elem, defs[elem.Name()])
+				}
+			}
+		}
+		check(reflect.TypeOf(v), doc)
+	}
+}
+
+// checkRules checks the keywords for each validation rule, and fails on a
+// rule it does not know, so that a new one gets a keyword or is listed here
+// as having none.
+func checkRules(t *testing.T, field string, prop Schema, rules []string) {
+	t.Helper()
+	for i, rule := range rules {
+		name, arg, _ := strings.Cut(rule, "=")
+		want := map[string]any{}
+		switch name {
+		case "", "omitempty", "required":
+		case "timezone":
+			// JSON Schema has no format for IANA zone names.
+		case "dive":
+			items, ok := prop["items"].(Schema)
+			if !ok {
+				t.Errorf("%s: dive on a property without items", field)
+				return
+			}
+			checkRules(t, field+"[]", items, rules[i+1:])
+			return
+		case "oneof":
+			want["enum"] = strings.Fields(arg)
+		case "datetime":
+			if arg != "2006-01-02" {
+				t.Errorf("%s: no format for datetime=%s", field, arg)
+			}
+			want["format"] = "date"
+		case "url":
+			want["format"] = "uri"
+		case "email":
+			want["format"] = "email"
+		case "min", "max":
+			n, _ := strconv.ParseFloat(arg, 64)
+			want[boundKeyword(prop, name)] = n
+		default:
+			t.Errorf("%s: no keyword for binding rule %q", field, rule)
+		}
+		for key, value := range want {
+			if !reflect.DeepEqual(prop[key], value) {
+				t.Errorf("%s: %s is %v, want %v for %s", field, key, prop[key], value, rule)
+			}
+		}
+	}
+}
+
+func TestWithBinding(t *testing.T) {
+	tests := []struct {
+		prop    Schema
+		binding string
+		want    Schema
+	}{
+		{Schema{"type": "number"}, "min=-90,max=90", Schema{"type": "number", "minimum": -90.0, "maximum": 90.0}},
+		{Schema{"type": "integer"}, "omitempty,min=1", Schema{"type": "integer", "minimum": 1.0}},
+		{Schema{"type": "string"}, "required,max=20", Schema{"type": "string", "maxLength": 20.0}},
+		{Schema{"type": "string"}, "omitempty,email", Schema{"type": "string", "format": "email"}},
+		{
+			Schema{"type": "array", "items": Schema{"type": "string"}},
+			"required,min=1,dive,email",
+			Schema{"type": "array", "minItems": 1.0, "items": Schema{"type": "string", "format": "email"}},
+		},
+		{Schema{"$ref": "#/$defs/Flight"}, "dive", Schema{"$ref": "#/$defs/Flight"}},
+	}
+	for _, tt := range tests {
+		if got := withBinding(tt.prop, tt.binding); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("withBinding(%v, %q) = %v, want %v", tt.prop, tt.binding, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteCSV writes s with a header row. Dates are written as YYYY-MM-DD and
+// amounts as plain numbers with two decimals, which spreadsheet programs
+// read back as dates and numbers in any locale.
+func WriteCSV(w io.Writer, s Sheet) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(s.Headers); err != nil {
+		return err
+	}
+	record := make([]string, len(s.Headers))
+	for _, row := range s.Rows {
+		for i, v := range row {
+			record[i] = v.csv()
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func (v Value) csv() string {
+	switch v.Kind {
+	case Text:
+		// Text that a spreadsheet would run as a formula is quoted.
+		if v.Text != "" && strings.ContainsAny(v.Text[:1], "=+-@\t\r") {
+			return "'" + v.Text
+		}
+		return v.Text
+	case Number:
+		return strconv.FormatFloat(v.Number, 'f', -1, 64)
+	case Amount:
+		return strconv.FormatFloat(v.Number, 'f', 2, 64)
+	case Date:
+		return v.Time.Format("2006-01-02")
+	}
+	return ""
+}
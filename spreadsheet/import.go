@@ -0,0 +1,525 @@
+package spreadsheet
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+)
+
+// The kinds of rows Import reads, by the names the API's CSV uploads use.
+const (
+	ImportActivities = "activities"
+	ImportFlights    = "flights"
+	ImportHotels     = "hotels"
+)
+
+// field is a column Import reads, with the headers it is found by when the
+// mapping does not name one.
+type field struct {
+	name     string
+	headers  []string
+	required bool
+}
+
+// rowKind is a kind of row: the sheet names it is looked for under and its
+// fields.
+type rowKind struct {
+	name   string
+	sheets []string
+	fields []field
+}
+
+// The export's sheet titles and headers are among the defaults, so an
+// exported workbook imports as it is.
+var (
+	activityRows = rowKind{ImportActivities, []string{"activities", "activity table", "itinerary", "days"}, []field{
+		{name: "day", headers: []string{"day", "day no", "day number"}},
+		{name: "date", headers: []string{"date"}},
+		{name: "time", headers: []string{"time", "start", "start time"}},
+		{name: "title", headers: []string{"title", "activity", "activity name"}, required: true},
+		{name: "description", headers: []string{"description", "details"}},
+		{name: "duration", headers: []string{"duration", "minutes", "duration minutes"}},
+		{name: "type", headers: []string{"type", "category"}},
+		{name: "city", headers: []string{"city", "location"}},
+	}}
+	flightRows = rowKind{ImportFlights, []string{"flights", "flight summary"}, []field{
+		{name: "date", headers: []string{"date"}, required: true},
+		{name: "airline", headers: []string{"airline", "carrier"}},
+		{name: "from", headers: []string{"from", "origin"}, required: true},
+		{name: "to", headers: []string{"to", "destination"}, required: true},
+		{name: "departure", headers: []string{"departure", "departure time", "departs"}},
+		{name: "arrival", headers: []string{"arrival", "arrival time", "arrives"}},
+	}}
+	hotelRows = rowKind{ImportHotels, []string{"hotels", "hotel bookings"}, []field{
+		{name: "city", headers: []string{"city"}, required: true},
+		{name: "name", headers: []string{"name", "hotel", "hotel name"}, required: true},
+		{name: "checkIn", headers: []string{"check in", "checkin"}, required: true},
+		{name: "checkOut", headers: []string{"check out", "checkout"}, required: true},
+		{name: "nights", headers: []string{"nights"}},
+	}}
+)
+
+// Import reads the activities, flights and hotels in sheets, each from the
+// sheet the mapping names or, failing that, the sheet named after it.
+// Without a sheet named for activities, the one sheet named after none of
+// them holds activities.
+// Activities given only a day number are dated from departureDate, when it
+// is set.
+//
+// Rows that cannot be read are reported in the response's Errors and left
+// out. The error is for a mapping that does not fit the sheets at all.
+func Import(sheets []Sheet, m types.ImportMapping, departureDate string) (types.ImportResponse, error) {
+	resp := types.ImportResponse{Days: []types.Day{}, Flights: []types.Flight{}, Hotels: []types.Hotel{}, Errors: []types.ImportError{}}
+	var departure time.Time
+	if departureDate != "" {
+		var err error
+		if departure, err = utils.ConvertStringToTime(departureDate); err != nil {
+			return resp, fmt.Errorf("departureDate %q is not a YYYY-MM-DD date", departureDate)
+		}
+	}
+
+	found := 0
+	for _, kind := range []struct {
+		rowKind
+		mapping types.SheetMapping
+		read    func(r *reader)
+	}{
+		{activityRows, m.Activities, func(r *reader) { resp.Days = r.days(departure) }},
+		{flightRows, m.Flights, func(r *reader) { resp.Flights = r.flights() }},
+		{hotelRows, m.Hotels, func(r *reader) { resp.Hotels = r.hotels() }},
+	} {
+		if err := kind.check(kind.mapping); err != nil {
+			return resp, err
+		}
+		s, ok := kind.sheet(sheets, kind.mapping)
+		if !ok && kind.mapping.Sheet != "" {
+			return resp, fmt.Errorf("%s: no sheet named %q", kind.name, kind.mapping.Sheet)
+		}
+		if !ok && kind.name == ImportActivities {
+			s, ok = unnamedSheet(sheets)
+		}
+		if !ok {
+			continue
+		}
+		found++
+
+		r := &reader{sheet: s}
+		r.columns(kind.rowKind, kind.mapping)
+		if !r.failed {
+			kind.read(r)
+		}
+		resp.Errors = append(resp.Errors, r.errors...)
+	}
+	if found == 0 {
+		return resp, errors.New("no sheet of activities, flights or hotels")
+	}
+	return resp, nil
+}
+
+// check reports mapped fields the kind does not have.
+func (k rowKind) check(m types.SheetMapping) error {
+	for name := range m.Columns {
+		if !slices.ContainsFunc(k.fields, func(f field) bool { return f.name == name }) {
+			names := make([]string, len(k.fields))
+			for i, f := range k.fields {
+				names[i] = f.name
+			}
+			return fmt.Errorf("%s: unknown field %q, want one of %s", k.name, name, strings.Join(names, ", "))
+		}
+	}
+	return nil
+}
+
+func (k rowKind) sheet(sheets []Sheet, m types.SheetMapping) (Sheet, bool) {
+	for _, s := range sheets {
+		name := normalize(s.Name)
+		if m.Sheet != "" && name == normalize(m.Sheet) || m.Sheet == "" && slices.Contains(k.sheets, name) {
+			return s, true
+		}
+	}
+	return Sheet{}, false
+}
+
+// unnamedSheet is the only sheet not named after a kind of row, if there is
+// exactly one.
+func unnamedSheet(sheets []Sheet) (Sheet, bool) {
+	var unnamed []Sheet
+	for _, s := range sheets {
+		if !slices.ContainsFunc([]rowKind{activityRows, flightRows, hotelRows}, func(k rowKind) bool {
+			return slices.Contains(k.sheets, normalize(s.Name))
+		}) {
+			unnamed = append(unnamed, s)
+		}
+	}
+	if len(unnamed) != 1 {
+		return Sheet{}, false
+	}
+	return unnamed[0], true
+}
+
+// normalize makes headers and sheet names match regardless of case, spacing
+// and punctuation: "Check-In" and "check in" are both "check in".
+func normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// reader reads the rows of one sheet, collecting their errors.
+type reader struct {
+	sheet  Sheet
+	column map[string]int
+	errors []types.ImportError
+	// failed is set when the sheet cannot be read at all.
+	failed bool
+	// rowFailed is set when the current row has an error.
+	rowFailed bool
+	row       int
+}
+
+// columns finds the column of each field.
+func (r *reader) columns(k rowKind, m types.SheetMapping) {
+	headers := make([]string, len(r.sheet.Headers))
+	for i, h := range r.sheet.Headers {
+		headers[i] = normalize(h)
+	}
+	r.column = map[string]int{}
+	for _, f := range k.fields {
+		if header, ok := m.Columns[f.name]; ok {
+			if i := slices.Index(headers, normalize(header)); i >= 0 {
+				r.column[f.name] = i
+			} else {
+				r.sheetError(header, fmt.Sprintf("no column %q for %s", header, f.name))
+			}
+			continue
+		}
+		i := slices.IndexFunc(headers, func(h string) bool { return slices.Contains(f.headers, h) })
+		switch {
+		case i >= 0:
+			r.column[f.name] = i
+		case f.required:
+			r.sheetError("", fmt.Sprintf("no column for %s; name it %q or map it in mapping.%s.columns", f.name, f.headers[0], k.name))
+		}
+	}
+	if k.name == ImportActivities {
+		_, day := r.column["day"]
+		_, date := r.column["date"]
+		if !day && !date {
+			r.sheetError("", fmt.Sprintf("no column for day or date; name one %q or %q, or map it in mapping.%s.columns", "day", "date", k.name))
+		}
+	}
+}
+
+func (r *reader) sheetError(column, msg string) {
+	r.errors = append(r.errors, types.ImportError{Sheet: r.sheet.Name, Column: column, Error: msg})
+	r.failed = true
+}
+
+// rows calls read with each row that is not blank, with r.row set to its
+// number.
+func (r *reader) rows(read func(row []Value)) {
+	for i, row := range r.sheet.Rows {
+		if blank(row) {
+			continue
+		}
+		r.row = r.sheet.RowNumbers[i]
+		r.rowFailed = false
+		read(row)
+	}
+}
+
+// value is the field's cell in row, or an empty value when the sheet has no
+// column for it.
+func (r *reader) value(row []Value, name string) Value {
+	i, ok := r.column[name]
+	if !ok || i >= len(row) {
+		return Value{}
+	}
+	v := row[i]
+	if v.Kind == Text {
+		v.Text = strings.TrimSpace(v.Text)
+		if v.Text == "" {
+			return Value{}
+		}
+	}
+	return v
+}
+
+func (r *reader) fail(name, msg string) {
+	header := name
+	if i, ok := r.column[name]; ok {
+		header = r.sheet.Headers[i]
+	}
+	r.errors = append(r.errors, types.ImportError{Sheet: r.sheet.Name, Row: r.row, Column: header, Error: msg})
+	r.rowFailed = true
+}
+
+// text is the field as text, failing when a required field is empty.
+func (r *reader) text(row []Value, name string, required bool) string {
+	v := r.value(row, name)
+	if v.Kind == Empty && required {
+		r.fail(name, name+" is empty")
+	}
+	return v.String()
+}
+
+// dateLayouts are the date forms accepted in text cells. Forms like
+// 05/06/2025, which mean different days in different countries, are not.
+var dateLayouts = []string{
+	time.DateOnly, "2006/01/02", "2 Jan 2006", "2-Jan-2006", "2 January 2006", "Jan 2, 2006", "January 2, 2006", "Jan 2 2006",
+}
+
+func (r *reader) date(row []Value, name string, required bool) string {
+	v := r.value(row, name)
+	switch v.Kind {
+	case Empty:
+		if required {
+			r.fail(name, name+" is empty")
+		}
+		return ""
+	case Date:
+		return v.Time.Format(time.DateOnly)
+	case Number:
+		// A date typed into a cell formatted as a plain number.
+		if v.Number >= 1 && v.Number < 2958466 && v.Number == math.Trunc(v.Number) {
+			return serialTime(v.Number).Format(time.DateOnly)
+		}
+	case Text:
+		for _, layout := range dateLayouts {
+			if t, err := time.Parse(layout, v.Text); err == nil {
+				return t.Format(time.DateOnly)
+			}
+		}
+	}
+	r.fail(name, fmt.Sprintf("%q is not a date like 2025-06-15", v.String()))
+	return ""
+}
+
+// clock is a time of day as HH:MM. Activities may give a slot like
+// "Morning" or "Full day" instead, which is kept as it is.
+func (r *reader) clock(row []Value, name string, slots bool) string {
+	v := r.value(row, name)
+	switch v.Kind {
+	case Empty:
+		return ""
+	case Date:
+		return v.Time.Format("15:04")
+	case Number:
+		if v.Number >= 0 && v.Number < 1 {
+			return serialTime(v.Number).Format("15:04")
+		}
+	case Text:
+		if slots && !strings.ContainsAny(v.Text, "0123456789") {
+			return v.Text
+		}
+		if d, ok := utils.ParseClock(v.Text); ok && strings.ContainsAny(v.Text, "0123456789") {
+			return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+		}
+	}
+	r.fail(name, fmt.Sprintf("%q is not a time like 09:30", v.String()))
+	return ""
+}
+
+// durationText matches lengths like "90 min", "2 hours" and "1h 30m".
+var durationText = regexp.MustCompile(`^(?:(\d+(?:\.\d+)?)\s*h(?:rs?|ours?)?)?\s*(?:(\d+)\s*m(?:ins?|inutes?)?)?$`)
+
+// minutes is a length in whole minutes: a number of minutes, a time like
+// 1:30 or text like "1h 30m".
+func (r *reader) minutes(row []Value, name string) int {
+	v := r.value(row, name)
+	switch v.Kind {
+	case Empty:
+		return 0
+	case Number:
+		if v.Number >= 0 {
+			return int(math.Round(v.Number))
+		}
+	case Date:
+		if v.Time.Before(excelEpoch.AddDate(0, 0, 1)) {
+			return int(v.Time.Sub(excelEpoch).Minutes())
+		}
+	case Text:
+		s := strings.ToLower(v.Text)
+		if n, err := strconv.Atoi(s); err == nil && n >= 0 {
+			return n
+		}
+		if h, m, ok := strings.Cut(s, ":"); ok {
+			hours, err1 := strconv.Atoi(h)
+			mins, err2 := strconv.Atoi(m)
+			if err1 == nil && err2 == nil && hours >= 0 && mins >= 0 && mins < 60 {
+				return hours*60 + mins
+			}
+		}
+		if match := durationText.FindStringSubmatch(s); match != nil && (match[1] != "" || match[2] != "") {
+			hours, _ := strconv.ParseFloat(match[1], 64)
+			mins, _ := strconv.Atoi(match[2])
+			return int(math.Round(hours*60)) + mins
+		}
+	}
+	r.fail(name, fmt.Sprintf("%q is not a number of minutes", v.String()))
+	return 0
+}
+
+// count is a whole number of at least min, 0 when the cell is empty.
+func (r *reader) count(row []Value, name string, min int) int {
+	v := r.value(row, name)
+	switch v.Kind {
+	case Empty:
+		return 0
+	case Number:
+		if v.Number == math.Trunc(v.Number) && v.Number >= float64(min) {
+			return int(v.Number)
+		}
+	case Text:
+		s := strings.TrimPrefix(strings.ToLower(v.Text), "day")
+		if n, err := strconv.Atoi(strings.TrimSpace(s)); err == nil && n >= min {
+			return n
+		}
+	}
+	r.fail(name, fmt.Sprintf("%q is not a whole number of at least %d", v.String(), min))
+	return 0
+}
+
+// plannedActivity is an activity row with the day it belongs to.
+type plannedActivity struct {
+	row      int
+	number   int
+	date     string
+	activity types.Activity
+}
+
+// days groups the activity rows into days, by date or, for rows with only
+// a day number, by number. Days are in date order when they all have a
+// date, in day order when they all have a number, and otherwise in the
+// order they first appear.
+func (r *reader) days(departure time.Time) []types.Day {
+	var planned []plannedActivity
+	numbered := map[int]string{}
+	dayRow := map[int]int{}
+	r.rows(func(row []Value) {
+		p := plannedActivity{
+			row:    r.row,
+			number: r.count(row, "day", 1),
+			date:   r.date(row, "date", false),
+			activity: types.Activity{
+				Time:        r.clock(row, "time", true),
+				Title:       r.text(row, "title", true),
+				Description: r.text(row, "description", false),
+				Duration:    r.minutes(row, "duration"),
+				Type:        r.text(row, "type", false),
+				City:        r.text(row, "city", false),
+			},
+		}
+		if p.number == 0 && p.date == "" && !r.rowFailed {
+			r.fail("date", "the row has neither a day nor a date")
+		}
+		if p.number > 0 && p.date == "" && !departure.IsZero() {
+			p.date = departure.AddDate(0, 0, p.number-1).Format(time.DateOnly)
+		}
+		if p.number > 0 && p.date != "" && !r.rowFailed {
+			if date, ok := numbered[p.number]; ok && date != p.date {
+				r.fail("date", fmt.Sprintf("day %d is %s in row %d", p.number, date, dayRow[p.number]))
+			} else {
+				numbered[p.number], dayRow[p.number] = p.date, r.row
+			}
+		}
+		if !r.rowFailed {
+			planned = append(planned, p)
+		}
+	})
+
+	var days []types.Day
+	var numbers []int
+	index := map[string]int{}
+	for _, p := range planned {
+		if p.date == "" {
+			p.date = numbered[p.number]
+		}
+		key := p.date
+		if key == "" {
+			key = "#" + strconv.Itoa(p.number)
+		}
+		i, ok := index[key]
+		if !ok {
+			i = len(days)
+			index[key] = i
+			days = append(days, types.Day{Date: p.date})
+			numbers = append(numbers, p.number)
+		}
+		days[i].Activities = append(days[i].Activities, p.activity)
+	}
+
+	dated, allNumbered := true, true
+	for i, d := range days {
+		dated = dated && d.Date != ""
+		allNumbered = allNumbered && numbers[i] > 0
+	}
+	order := make([]int, len(days))
+	for i := range order {
+		order[i] = i
+	}
+	switch {
+	case dated:
+		sort.SliceStable(order, func(a, b int) bool { return days[order[a]].Date < days[order[b]].Date })
+	case allNumbered:
+		sort.SliceStable(order, func(a, b int) bool { return numbers[order[a]] < numbers[order[b]] })
+	}
+	sorted := make([]types.Day, len(days))
+	for i, j := range order {
+		sorted[i] = days[j]
+	}
+	return sorted
+}
+
+func (r *reader) flights() []types.Flight {
+	flights := []types.Flight{}
+	r.rows(func(row []Value) {
+		f := types.Flight{
+			Date:      r.date(row, "date", true),
+			Airline:   r.text(row, "airline", false),
+			From:      r.text(row, "from", true),
+			To:        r.text(row, "to", true),
+			Departure: r.clock(row, "departure", false),
+			Arrival:   r.clock(row, "arrival", false),
+		}
+		if !r.rowFailed {
+			flights = append(flights, f)
+		}
+	})
+	return flights
+}
+
+// hotels reads hotel stays, working out the nights from the dates when the
+// sheet does not give them.
+func (r *reader) hotels() []types.Hotel {
+	hotels := []types.Hotel{}
+	r.rows(func(row []Value) {
+		h := types.Hotel{
+			City:     r.text(row, "city", true),
+			Name:     r.text(row, "name", true),
+			CheckIn:  r.date(row, "checkIn", true),
+			CheckOut: r.date(row, "checkOut", true),
+			Nights:   r.count(row, "nights", 0),
+		}
+		if h.CheckIn != "" && h.CheckOut != "" && h.CheckOut <= h.CheckIn {
+			r.fail("checkOut", "check-out is not after check-in")
+		}
+		if h.Nights == 0 && !r.rowFailed {
+			h.Nights = utils.CalculateNights(h.CheckIn, h.CheckOut)
+		}
+		if !r.rowFailed {
+			hotels = append(hotels, h)
+		}
+	})
+	return hotels
+}
@@ -0,0 +1,375 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPart bounds the unpacked size of each part of an XLSX file, so that a
+// small upload cannot unpack into gigabytes.
+const maxPart = 50 << 20
+
+// maxRows is the most rows a sheet can have, as in Excel.
+const maxRows = 1 << 20
+
+// IsXLSX reports whether data starts like a zip file, as XLSX files do.
+func IsXLSX(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("PK\x03\x04"))
+}
+
+// ReadCSV reads a CSV file whose first non-blank row is the header. Every
+// cell is text, without the quote WriteCSV puts before formula-like text.
+func ReadCSV(r io.Reader, name string) (Sheet, error) {
+	in := bufio.NewReader(r)
+	if bom, err := in.Peek(3); err == nil && bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		in.Discard(3)
+	}
+	records := csv.NewReader(in)
+	records.FieldsPerRecord = -1
+	records.LazyQuotes = true
+
+	s := Sheet{Name: name, Title: name}
+	for {
+		record, err := records.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Sheet{}, fmt.Errorf("%s: %w", name, err)
+		}
+		line, _ := records.FieldPos(0)
+		row := make([]Value, len(record))
+		for i, field := range record {
+			if len(field) > 1 && field[0] == '\'' && strings.ContainsAny(field[1:2], "=+-@\t\r") {
+				field = field[1:]
+			}
+			row[i] = text(field)
+		}
+		if err := s.add(line, row); err != nil {
+			return Sheet{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return s, nil
+}
+
+// add puts row, read from the given row number, after the header. Blank
+// rows before the header are skipped.
+func (s *Sheet) add(number int, row []Value) error {
+	switch {
+	case number > maxRows:
+		return fmt.Errorf("row %d is past the last row a sheet can have, %d", number, maxRows)
+	case number <= s.lastRow:
+		return fmt.Errorf("row %d is out of order", number)
+	}
+	s.lastRow = number
+	if s.Headers == nil {
+		if blank(row) {
+			return nil
+		}
+		s.Headers = make([]string, len(row))
+		for i, v := range row {
+			s.Headers[i] = v.String()
+		}
+		return nil
+	}
+	s.Rows = append(s.Rows, row)
+	s.RowNumbers = append(s.RowNumbers, number)
+	return nil
+}
+
+func blank(row []Value) bool {
+	for _, v := range row {
+		switch v.Kind {
+		case Text:
+			if strings.TrimSpace(v.Text) != "" {
+				return false
+			}
+		case Number, Amount, Date:
+			return false
+		}
+	}
+	return true
+}
+
+// String is the value as text: numbers in the shortest form, dates as
+// YYYY-MM-DD and times of day as HH:MM.
+func (v Value) String() string {
+	switch v.Kind {
+	case Text:
+		return v.Text
+	case Number, Amount:
+		return strconv.FormatFloat(v.Number, 'f', -1, 64)
+	case Date:
+		if v.Time.Before(excelEpoch.AddDate(0, 0, 1)) {
+			return v.Time.Format("15:04")
+		}
+		if v.Time.Hour() != 0 || v.Time.Minute() != 0 {
+			return v.Time.Format("2006-01-02 15:04")
+		}
+		return v.Time.Format("2006-01-02")
+	}
+	return ""
+}
+
+// The parts of an XLSX file that ReadXLSX reads.
+type (
+	xlsxWorkbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	xlsxRelationships struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	xlsxSharedStrings struct {
+		Items []xlsxText `xml:"si"`
+	}
+	xlsxStyles struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	xlsxWorksheet struct {
+		Rows []struct {
+			R     int `xml:"r,attr"`
+			Cells []struct {
+				R      string   `xml:"r,attr"`
+				T      string   `xml:"t,attr"`
+				S      int      `xml:"s,attr"`
+				V      string   `xml:"v"`
+				Inline xlsxText `xml:"is"`
+			} `xml:"c"`
+		} `xml:"sheetData>row"`
+	}
+	// xlsxText is plain text in t, or rich text in runs.
+	xlsxText struct {
+		T    string `xml:"t"`
+		Runs []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	}
+)
+
+func (t xlsxText) String() string {
+	s := t.T
+	for _, r := range t.Runs {
+		s += r.T
+	}
+	return s
+}
+
+// ReadXLSX reads every sheet of an XLSX workbook, in tab order, taking each
+// one's first non-blank row as its header. Numbers formatted as dates or
+// times are read as dates.
+func ReadXLSX(r io.ReaderAt, size int64) ([]Sheet, error) {
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("xlsx: %w", err)
+	}
+	parts := map[string]*zip.File{}
+	for _, f := range z.File {
+		parts[strings.TrimPrefix(f.Name, "/")] = f
+	}
+	read := func(name string, v any, optional bool) error {
+		f, ok := parts[name]
+		if !ok {
+			if optional {
+				return nil
+			}
+			return fmt.Errorf("xlsx: no %s", name)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return fmt.Errorf("xlsx: %s: %w", name, err)
+		}
+		defer rc.Close()
+		raw, err := io.ReadAll(io.LimitReader(rc, maxPart+1))
+		if err != nil {
+			return fmt.Errorf("xlsx: %s: %w", name, err)
+		}
+		if len(raw) > maxPart {
+			return fmt.Errorf("xlsx: %s is too large", name)
+		}
+		if err := xml.Unmarshal(raw, v); err != nil {
+			return fmt.Errorf("xlsx: %s: %w", name, err)
+		}
+		return nil
+	}
+
+	var workbook xlsxWorkbook
+	var rels xlsxRelationships
+	var shared xlsxSharedStrings
+	var styles xlsxStyles
+	if err := read("xl/workbook.xml", &workbook, false); err != nil {
+		return nil, err
+	}
+	if err := read("xl/_rels/workbook.xml.rels", &rels, true); err != nil {
+		return nil, err
+	}
+	if err := read("xl/sharedStrings.xml", &shared, true); err != nil {
+		return nil, err
+	}
+	if err := read("xl/styles.xml", &styles, true); err != nil {
+		return nil, err
+	}
+
+	targets := map[string]string{}
+	for _, rel := range rels.Relationships {
+		if strings.HasPrefix(rel.Target, "/") {
+			targets[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			targets[rel.ID] = path.Join("xl", rel.Target)
+		}
+	}
+	dateStyles := dateStyles(styles)
+
+	var sheets []Sheet
+	for i, ref := range workbook.Sheets {
+		target, ok := targets[ref.ID]
+		if !ok {
+			target = fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1)
+		}
+		var ws xlsxWorksheet
+		if err := read(target, &ws, false); err != nil {
+			return nil, err
+		}
+
+		s := Sheet{Name: ref.Name, Title: ref.Name}
+		for _, wr := range ws.Rows {
+			// A row without a number follows the one before it.
+			number := wr.R
+			if number == 0 {
+				number = s.lastRow + 1
+			}
+			var row []Value
+			for i, c := range wr.Cells {
+				col := i
+				if c.R != "" {
+					if col, err = columnIndex(c.R); err != nil {
+						return nil, fmt.Errorf("xlsx: %s: %w", ref.Name, err)
+					}
+				}
+				for len(row) <= col {
+					row = append(row, Value{})
+				}
+				row[col] = cellValue(c.T, c.V, c.Inline, shared, dateStyles[c.S])
+			}
+			if err := s.add(number, row); err != nil {
+				return nil, fmt.Errorf("xlsx: %s: %w", ref.Name, err)
+			}
+		}
+		sheets = append(sheets, s)
+	}
+	return sheets, nil
+}
+
+func cellValue(kind, v string, inline xlsxText, shared xlsxSharedStrings, isDate bool) Value {
+	switch kind {
+	case "s":
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 0 || i >= len(shared.Items) {
+			return Value{}
+		}
+		return text(shared.Items[i].String())
+	case "inlineStr":
+		return text(inline.String())
+	case "str", "e":
+		return text(v)
+	case "b":
+		if v == "1" {
+			return text("TRUE")
+		}
+		return text("FALSE")
+	case "d":
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", time.DateOnly} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return Value{Kind: Date, Time: t}
+			}
+		}
+		return text(v)
+	}
+	if v == "" {
+		return Value{}
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return text(v)
+	}
+	if isDate {
+		return Value{Kind: Date, Time: serialTime(n)}
+	}
+	return number(n)
+}
+
+// serialTime is the time of an Excel date serial number, to the second.
+func serialTime(serial float64) time.Time {
+	return excelEpoch.Add(time.Duration(math.Round(serial*86400)) * time.Second)
+}
+
+// columnIndex is the zero-based column of an A1 reference like "C12".
+func columnIndex(ref string) (int, error) {
+	col := 0
+	letters := 0
+	for _, r := range strings.ToUpper(ref) {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		letters++
+	}
+	if letters == 0 || letters > 3 {
+		return 0, fmt.Errorf("bad cell reference %q", ref)
+	}
+	return col - 1, nil
+}
+
+// builtinDateFormats are the number formats Excel predefines for dates and
+// times.
+var builtinDateFormats = map[int]bool{
+	14: true, 15: true, 16: true, 17: true, 18: true, 19: true, 20: true, 21: true, 22: true,
+	27: true, 28: true, 29: true, 30: true, 31: true, 32: true, 33: true, 34: true, 35: true, 36: true,
+	45: true, 46: true, 47: true,
+	50: true, 51: true, 52: true, 53: true, 54: true, 55: true, 56: true, 57: true, 58: true,
+}
+
+// formatLiterals are the parts of a number format that are printed as they
+// are: quoted text, escaped characters and bracketed colours and locales.
+var formatLiterals = regexp.MustCompile(`"[^"]*"|\\.|\[[^\]]*\]`)
+
+// dateStyles reports, by cell style index, which styles show numbers as
+// dates or times.
+func dateStyles(styles xlsxStyles) map[int]bool {
+	custom := map[int]bool{}
+	for _, f := range styles.NumFmts {
+		code := strings.ToLower(formatLiterals.ReplaceAllString(f.Code, ""))
+		custom[f.ID] = strings.ContainsAny(code, "dmyhs")
+	}
+	dates := map[int]bool{}
+	for i, xf := range styles.CellXfs {
+		if isDate, ok := custom[xf.NumFmtID]; ok {
+			dates[i] = isDate
+		} else {
+			dates[i] = builtinDateFormats[xf.NumFmtID]
+		}
+	}
+	return dates
+}
@@ -0,0 +1,116 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// workbookWith is an XLSX file with one sheet whose sheetData is rows.
+func workbookWith(t *testing.T, rows string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	z := zip.NewWriter(&buf)
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"` +
+			` xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Flights" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+			`<sheetData>` + rows + `</sheetData></worksheet>`,
+	}
+	for name, body := range parts {
+		f, err := z.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(body))
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func inlineRow(r string, cells ...string) string {
+	s := `<row r="` + r + `">`
+	for _, c := range cells {
+		s += `<c t="inlineStr"><is><t>` + c + `</t></is></c>`
+	}
+	return s + `</row>`
+}
+
+func TestReadXLSXRowNumbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    string
+		numbers []int
+		err     string
+	}{
+		{
+			name:    "gaps kept in row numbers",
+			rows:    inlineRow("2", "Date", "Airline") + inlineRow("3", "2025-06-15", "AI 380") + inlineRow("1048576", "2025-06-20", "SQ 422"),
+			numbers: []int{3, 1048576},
+		},
+		{
+			name:    "rows without numbers follow",
+			rows:    `<row><c t="inlineStr"><is><t>Date</t></is></c></row><row><c t="inlineStr"><is><t>x</t></is></c></row>`,
+			numbers: []int{2},
+		},
+		{
+			name: "past the last row",
+			rows: inlineRow("1", "Date") + inlineRow("2000000000", "2025-06-15"),
+			err:  "row 2000000000 is past the last row",
+		},
+		{
+			name: "out of order",
+			rows: inlineRow("1", "Date") + inlineRow("5", "2025-06-15") + inlineRow("4", "2025-06-16"),
+			err:  "row 4 is out of order",
+		},
+		{
+			name: "negative",
+			rows: inlineRow("-3", "Date"),
+			err:  "row -3 is out of order",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := workbookWith(t, tt.rows)
+			sheets, err := ReadXLSX(bytes.NewReader(data), int64(len(data)))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("err = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := sheets[0].RowNumbers
+			if len(got) != len(tt.numbers) || len(sheets[0].Rows) != len(got) {
+				t.Fatalf("row numbers = %v for %d rows, want %v", got, len(sheets[0].Rows), tt.numbers)
+			}
+			for i := range got {
+				if got[i] != tt.numbers[i] {
+					t.Fatalf("row numbers = %v, want %v", got, tt.numbers)
+				}
+			}
+		})
+	}
+}
+
+func TestReadCSVRowNumbers(t *testing.T) {
+	s, err := ReadCSV(strings.NewReader("\nDate,Title\n2025-06-15,\"Fort\nvisit\"\n\n2025-06-16,Beach\n"), "activities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{3, 6}
+	if len(s.RowNumbers) != len(want) || s.RowNumbers[0] != want[0] || s.RowNumbers[1] != want[1] {
+		t.Fatalf("row numbers = %v, want %v", s.RowNumbers, want)
+	}
+
+	many := "Title\n" + strings.Repeat("x\n", maxRows)
+	if _, err := ReadCSV(strings.NewReader(many), "activities"); err == nil || !strings.Contains(err.Error(), "past the last row") {
+		t.Fatalf("err = %v, want a row past the last", err)
+	}
+}
@@ -0,0 +1,172 @@
+// Package spreadsheet exports the tables of an itinerary for reconciling
+// bookings: the activities, the hotel stays and the payment plan, as CSV or
+// as an XLSX workbook with a sheet each. Cells keep their types, so dates
+// sort as dates and amounts add up, instead of the formatted text the PDF
+// prints.
+//
+// It also reads CSV and XLSX files back, and imports the days, flights and
+// hotels of packages planned in spreadsheets.
+package spreadsheet
+
+import (
+	"time"
+
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/markdown"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+	"github.com/monoMonu/travel-itinerary-pdf/utils"
+)
+
+// The sheets, by the names the API selects them with.
+const (
+	Activities = "activities"
+	Hotels     = "hotels"
+	Payment    = "payment"
+)
+
+// Names lists the sheets in workbook order.
+var Names = []string{Activities, Hotels, Payment}
+
+// Sheet is one table. Title is its translated name, used for the XLSX tab.
+type Sheet struct {
+	Name    string
+	Title   string
+	Headers []string
+	Rows    [][]Value
+	// RowNumbers are the row numbers of Rows in the file a sheet was read
+	// from, for reporting problems with a row.
+	RowNumbers []int
+	// lastRow is the number of the last row read, header and blank rows
+	// included, to keep rows in order.
+	lastRow int
+}
+
+// Kind is the type of a cell.
+type Kind int
+
+const (
+	Empty Kind = iota
+	Text
+	Number
+	Date
+	// Amount is a number of rupees.
+	Amount
+)
+
+// Value is a typed cell: Text for Text, Number for Number and Amount, and
+// Time for Date.
+type Value struct {
+	Kind   Kind
+	Text   string
+	Number float64
+	Time   time.Time
+}
+
+func text(s string) Value {
+	if s == "" {
+		return Value{}
+	}
+	return Value{Kind: Text, Text: s}
+}
+
+func number(n float64) Value {
+	return Value{Kind: Number, Number: n}
+}
+
+func amount(n float64) Value {
+	return Value{Kind: Amount, Number: n}
+}
+
+// date is a YYYY-MM-DD date, kept as text when it does not parse.
+func date(s string) Value {
+	t, err := utils.ConvertStringToTime(s)
+	if err != nil {
+		return text(s)
+	}
+	return Value{Kind: Date, Time: t}
+}
+
+// Build returns the sheets of data, with headers in the language of l.
+func Build(data types.BookingData, l *i18n.Locale) []Sheet {
+	return []Sheet{activities(data, l), hotels(data, l), payment(data, l)}
+}
+
+// Get returns the sheet called name, if there is one.
+func Get(sheets []Sheet, name string) (Sheet, bool) {
+	for _, s := range sheets {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Sheet{}, false
+}
+
+// activities has a row per activity, as the PDF's activity table does, with
+// the date and time it is planned for.
+func activities(data types.BookingData, l *i18n.Locale) Sheet {
+	s := Sheet{
+		Name:    Activities,
+		Title:   l.T("Activity Table"),
+		Headers: []string{l.T("Date"), l.T("Time"), l.T("City"), l.T("Activity"), l.T("Type"), l.T("Minutes")},
+	}
+	for _, day := range data.Days {
+		for _, a := range day.Activities {
+			minutes := Value{}
+			if a.Duration > 0 {
+				minutes = number(float64(a.Duration))
+			}
+			city := a.City
+			if city == "" {
+				city = data.Destination
+			}
+			s.Rows = append(s.Rows, []Value{
+				date(day.Date),
+				text(a.Time),
+				text(city),
+				text(markdown.PlainText(a.Title)),
+				text(a.Type),
+				minutes,
+			})
+		}
+	}
+	return s
+}
+
+func hotels(data types.BookingData, l *i18n.Locale) Sheet {
+	s := Sheet{
+		Name:    Hotels,
+		Title:   l.T("Hotel Bookings"),
+		Headers: []string{l.T("City"), l.T("Check In"), l.T("Check Out"), l.T("Nights"), l.T("Hotel Name")},
+	}
+	for _, h := range data.Hotels {
+		s.Rows = append(s.Rows, []Value{
+			text(h.City),
+			date(h.CheckIn),
+			date(h.CheckOut),
+			number(float64(h.Nights)),
+			text(h.Name),
+		})
+	}
+	return s
+}
+
+// payment has the installments of the PDF's payment plan and the total.
+// The last installment's due date is worked out from the departure date.
+func payment(data types.BookingData, l *i18n.Locale) Sheet {
+	const finalDays = 20
+	final := text(l.T("%d Days Before Departure", finalDays))
+	if departure, err := utils.ConvertStringToTime(data.DepartureDate); err == nil {
+		final = Value{Kind: Date, Time: departure.AddDate(0, 0, -finalDays)}
+	}
+	return Sheet{
+		Name:    Payment,
+		Title:   l.T("Payment Plan"),
+		Headers: []string{l.T("Installment"), l.T("Amount"), l.T("Due Date")},
+		Rows: [][]Value{
+			{text(l.T("Installment %d", 1)), amount(data.Installment1), text(l.T("Initial Payment"))},
+			{text(l.T("Installment %d", 2)), amount(data.Installment2), text(l.T("Post Visa Approval"))},
+			{text(l.T("Installment %d", 3)), amount(data.TotalAmount - data.Installment1 - data.Installment2), final},
+			{text(l.T("Total Amount")), amount(data.TotalAmount), {}},
+		},
+	}
+}
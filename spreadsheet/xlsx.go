@@ -0,0 +1,265 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// An XLSX file is a zip of XML parts. Only what Excel, LibreOffice and
+// Google Sheets need to open a workbook is written: strings are inline
+// rather than shared, and the styles are the four the cells use.
+
+// The cell styles, as indexes into cellXfs in styles.xml.
+const (
+	styleDefault = iota
+	styleHeader
+	styleDate
+	styleAmount
+)
+
+const stylesXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd"/><numFmt numFmtId="165" formatCode="[$₹-4009]\ #,##0.00"/></numFmts>
+<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>
+<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>
+<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>
+<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>
+<cellXfs count="4"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/><xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/><xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/></cellXfs>
+<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>
+</styleSheet>
+`
+
+const rootRelsXML = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>
+`
+
+// excelEpoch is day 0 of Excel's date serial numbers, chosen so that they
+// agree with Excel's from March 1900 on.
+var excelEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+// WriteXLSX writes sheets as a workbook with a tab each. Header rows are
+// bold and frozen, with a filter on every column.
+func WriteXLSX(w io.Writer, sheets []Sheet) error {
+	z := zip.NewWriter(w)
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", contentTypes(len(sheets))},
+		{"_rels/.rels", rootRelsXML},
+		{"xl/workbook.xml", workbook(sheets)},
+		{"xl/_rels/workbook.xml.rels", workbookRels(len(sheets))},
+		{"xl/styles.xml", stylesXML},
+	}
+	for i, s := range sheets {
+		parts = append(parts, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheet(s)})
+	}
+	for _, part := range parts {
+		f, err := z.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+func contentTypes(sheets int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">`)
+	b.WriteString(`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>`)
+	b.WriteString(`<Default Extension="xml" ContentType="application/xml"/>`)
+	b.WriteString(`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>`)
+	b.WriteString(`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range sheets {
+		fmt.Fprintf(&b, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	b.WriteString("</Types>\n")
+	return b.String()
+}
+
+func workbook(sheets []Sheet) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	used := map[string]bool{}
+	tabNames := make([]string, len(sheets))
+	for i, s := range sheets {
+		tabNames[i] = tabName(s, used)
+		fmt.Fprintf(&b, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(tabNames[i]), i+1, i+1)
+	}
+	b.WriteString("</sheets>")
+	// Filters need a defined name for their range, or Excel repairs the file.
+	var names strings.Builder
+	for i, s := range sheets {
+		if len(s.Rows) > 0 {
+			fmt.Fprintf(&names, `<definedName name="_xlnm._FilterDatabase" localSheetId="%d" hidden="1">%s</definedName>`,
+				i, escape(fmt.Sprintf("'%s'!%s", strings.ReplaceAll(tabNames[i], "'", "''"), filterRange(s, true))))
+		}
+	}
+	if names.Len() > 0 {
+		b.WriteString("<definedNames>" + names.String() + "</definedNames>")
+	}
+	b.WriteString("</workbook>\n")
+	return b.String()
+}
+
+func workbookRels(sheets int) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range sheets {
+		fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&b, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, sheets+1)
+	b.WriteString("</Relationships>\n")
+	return b.String()
+}
+
+// tabName is the sheet's title as Excel allows it: at most 31 characters,
+// none of []:*?/\ and not the same as a tab in used, which it is added to.
+func tabName(s Sheet, used map[string]bool) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, s.Title)
+	if name == "" {
+		name = s.Name
+	}
+	if utf8.RuneCountInString(name) > 31 {
+		name = string([]rune(name)[:31])
+	}
+	for base, n := name, 2; used[strings.ToLower(name)]; n++ {
+		name = fmt.Sprintf("%s %d", base, n)
+	}
+	used[strings.ToLower(name)] = true
+	return name
+}
+
+func worksheet(s Sheet) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+
+	b.WriteString("<cols>")
+	for i, width := range columnWidths(s) {
+		fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, width)
+	}
+	b.WriteString("</cols>")
+
+	b.WriteString("<sheetData>")
+	header := make([]Value, len(s.Headers))
+	for i, h := range s.Headers {
+		header[i] = text(h)
+	}
+	writeRow(&b, 1, header, styleHeader)
+	for i, row := range s.Rows {
+		writeRow(&b, i+2, row, styleDefault)
+	}
+	b.WriteString("</sheetData>")
+	if len(s.Rows) > 0 {
+		fmt.Fprintf(&b, `<autoFilter ref="%s"/>`, filterRange(s, false))
+	}
+	b.WriteString("</worksheet>\n")
+	return b.String()
+}
+
+func writeRow(b *strings.Builder, r int, row []Value, style int) {
+	fmt.Fprintf(b, `<row r="%d">`, r)
+	for c, v := range row {
+		ref := cellRef(c, r)
+		switch v.Kind {
+		case Text:
+			fmt.Fprintf(b, `<c r="%s" t="inlineStr"%s><is><t xml:space="preserve">%s</t></is></c>`, ref, styleAttr(style), escape(v.Text))
+		case Number:
+			fmt.Fprintf(b, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr(style), strconv.FormatFloat(v.Number, 'f', -1, 64))
+		case Amount:
+			fmt.Fprintf(b, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr(styleAmount), strconv.FormatFloat(v.Number, 'f', -1, 64))
+		case Date:
+			serial := v.Time.Sub(excelEpoch).Hours() / 24
+			fmt.Fprintf(b, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr(styleDate), strconv.FormatFloat(serial, 'f', -1, 64))
+		}
+	}
+	b.WriteString("</row>")
+}
+
+func styleAttr(style int) string {
+	if style == styleDefault {
+		return ""
+	}
+	return fmt.Sprintf(` s="%d"`, style)
+}
+
+// cellRef is the A1 reference of the zero-based column c in row r.
+func cellRef(c, r int) string {
+	return column(c) + strconv.Itoa(r)
+}
+
+func column(c int) string {
+	name := ""
+	for c++; c > 0; c = (c - 1) / 26 {
+		name = string(rune('A'+(c-1)%26)) + name
+	}
+	return name
+}
+
+// filterRange covers the header and all rows, absolute when the workbook
+// defines it.
+func filterRange(s Sheet, absolute bool) string {
+	last := len(s.Headers) - 1
+	rows := len(s.Rows) + 1
+	if absolute {
+		return fmt.Sprintf("$A$1:$%s$%d", column(last), rows)
+	}
+	return fmt.Sprintf("A1:%s%d", column(last), rows)
+}
+
+// columnWidths fits each column to its longest text, within limits, in
+// Excel's units of one character.
+func columnWidths(s Sheet) []int {
+	widths := make([]int, len(s.Headers))
+	for i, h := range s.Headers {
+		widths[i] = utf8.RuneCountInString(h) + 2
+	}
+	for _, row := range s.Rows {
+		for i, v := range row {
+			n := 0
+			switch v.Kind {
+			case Text:
+				n = utf8.RuneCountInString(v.Text)
+			case Date:
+				n = 10
+			case Number, Amount:
+				n = len(strconv.FormatFloat(v.Number, 'f', 2, 64)) + 4
+			}
+			widths[i] = max(widths[i], n+2)
+		}
+	}
+	for i := range widths {
+		widths[i] = min(max(widths[i], 8), 60)
+	}
+	return widths
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
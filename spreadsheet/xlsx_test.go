@@ -0,0 +1,140 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+func day(y int, m time.Month, d int) Value {
+	return Value{Kind: Date, Time: time.Date(y, m, d, 0, 0, 0, 0, time.UTC)}
+}
+
+// roundTripSheet has every kind of cell, and text a spreadsheet could take
+// for a formula or for markup.
+var roundTripSheet = Sheet{
+	Name:    "activities",
+	Title:   "Activity Table",
+	Headers: []string{"Date", "Activity", "Minutes", "Amount"},
+	Rows: [][]Value{
+		{day(2025, time.June, 15), text("Gardens by the Bay"), number(180), amount(1500.5)},
+		{day(2024, time.February, 29), text("=HYPERLINK(\"http://evil.test\")"), number(0.25), amount(-20)},
+		{day(1900, time.March, 1), text("+91 98765 43210"), {}, amount(0)},
+		{{}, text("-Fish & <Chips> \"Night\""), number(1e6), {}},
+		{day(2025, time.December, 31), text("@Marina ✈ मरीना"), number(-3), amount(250000)},
+	},
+}
+
+func TestXLSXRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, []Sheet{roundTripSheet, {Name: "empty", Title: "Empty", Headers: []string{"Only"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if !IsXLSX(buf.Bytes()) {
+		t.Fatal("WriteXLSX did not write a zip file")
+	}
+	sheets, err := ReadXLSX(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sheets) != 2 || sheets[0].Name != "Activity Table" || sheets[1].Name != "Empty" {
+		t.Fatalf("got sheets %+v", sheets)
+	}
+
+	got := sheets[0]
+	if strings.Join(got.Headers, "|") != strings.Join(roundTripSheet.Headers, "|") {
+		t.Fatalf("headers %q", got.Headers)
+	}
+	if len(got.Rows) != len(roundTripSheet.Rows) {
+		t.Fatalf("got %d rows, want %d", len(got.Rows), len(roundTripSheet.Rows))
+	}
+	for i, want := range roundTripSheet.Rows {
+		if got.RowNumbers[i] != i+2 {
+			t.Errorf("row %d read as row %d", i+2, got.RowNumbers[i])
+		}
+		for j, w := range want {
+			// Amounts are numbers in a currency format, and come back as
+			// numbers.
+			if w.Kind == Amount {
+				w.Kind = Number
+			}
+			var g Value
+			if j < len(got.Rows[i]) {
+				g = got.Rows[i][j]
+			}
+			if g.Kind != w.Kind || g.Text != w.Text || g.Number != w.Number || !g.Time.Equal(w.Time) {
+				t.Errorf("row %d column %d: got %+v, want %+v", i+2, j+1, g, w)
+			}
+		}
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, roundTripSheet); err != nil {
+		t.Fatal(err)
+	}
+	raw := buf.String()
+	for _, quoted := range []string{`"'=HYPERLINK(""http://evil.test"")"`, "'+91 98765 43210", `"'-Fish & <Chips> ""Night"""`, "'@Marina"} {
+		if !strings.Contains(raw, quoted) {
+			t.Errorf("formula-like text not quoted as %s in\n%s", quoted, raw)
+		}
+	}
+	if !strings.Contains(raw, "2024-02-29,") || !strings.Contains(raw, ",1500.50\n") || !strings.Contains(raw, ",-20.00\n") {
+		t.Errorf("dates or amounts not written plainly:\n%s", raw)
+	}
+
+	got, err := ReadCSV(strings.NewReader(raw), "activities")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range roundTripSheet.Rows {
+		for j, w := range want {
+			// Every CSV cell comes back as text, without the quote.
+			if g, s := got.Rows[i][j], w.csv(); g.String() != strings.TrimPrefix(s, "'") {
+				t.Errorf("row %d column %d: got %q, want %q", i+2, j+1, g.String(), s)
+			}
+		}
+	}
+}
+
+// An exported workbook imports as it is.
+func TestExportImport(t *testing.T) {
+	data := types.BookingData{
+		Destination:   "Singapore",
+		DepartureDate: "2025-06-15",
+		Days: []types.Day{{Date: "2025-06-15", Activities: []types.Activity{
+			{Time: "09:30", Title: "**Gardens** by the Bay", Duration: 90, Type: "sightseeing", City: "Singapore"},
+		}}},
+		Hotels: []types.Hotel{{City: "Singapore", Name: "Marina Bay Sands", CheckIn: "2025-06-15", CheckOut: "2025-06-18", Nights: 3}},
+	}
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, Build(data, i18n.Get(""))); err != nil {
+		t.Fatal(err)
+	}
+	sheets, err := ReadXLSX(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := Import(sheets, types.ImportMapping{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("errors: %+v", resp.Errors)
+	}
+	if len(resp.Days) != 1 || len(resp.Days[0].Activities) != 1 || len(resp.Hotels) != 1 {
+		t.Fatalf("got %+v", resp)
+	}
+	a, h := resp.Days[0].Activities[0], resp.Hotels[0]
+	if a.Time != "09:30" || a.Title != "Gardens by the Bay" || a.Duration != 90 || a.Type != "sightseeing" {
+		t.Errorf("activity %+v", a)
+	}
+	if want := data.Hotels[0]; h.City != want.City || h.Name != want.Name || h.CheckIn != want.CheckIn || h.CheckOut != want.CheckOut || h.Nights != want.Nights {
+		t.Errorf("hotel %+v, want %+v", h, want)
+	}
+}
@@ -0,0 +1,49 @@
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Color is an RGB colour written as "#RRGGBB" in theme files.
+type Color struct {
+	R, G, B int
+
+	set bool
+}
+
+func RGB(r, g, b int) Color {
+	return Color{R: r, G: g, B: b, set: true}
+}
+
+func (c Color) Hex() string {
+	return fmt.Sprintf("#%02X%02X%02X", c.R, c.G, c.B)
+}
+
+func (c Color) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.Hex())
+}
+
+func (c *Color) UnmarshalJSON(raw []byte) error {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return err
+	}
+
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	if len(hex) != 6 {
+		return fmt.Errorf("invalid colour %q, want #RRGGBB", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return fmt.Errorf("invalid colour %q, want #RRGGBB", s)
+	}
+
+	*c = RGB(int(v>>16), int(v>>8&0xFF), int(v&0xFF))
+	return nil
+}
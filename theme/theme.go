@@ -0,0 +1,242 @@
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/monoMonu/travel-itinerary-pdf/fonts"
+)
+
+// Theme is everything that makes an itinerary look like a particular agency.
+type Theme struct {
+	Name    string  `json:"name"`
+	Palette Palette `json:"palette"`
+	Fonts   Fonts   `json:"fonts"`
+
+	// Wordmark is drawn as text wherever the brand appears and no logo is set.
+	Wordmark string `json:"wordmark"`
+	Tagline  string `json:"tagline"`
+
+	// Logo is a path to a PNG or JPEG, relative to the theme file. It
+	// replaces the wordmark when set.
+	Logo      string `json:"logo,omitempty"`
+	LogoBytes []byte `json:"-"`
+	LogoType  string `json:"-"`
+
+	// Radius is the corner radius of cards, in mm. Table rows use a smaller
+	// radius and buttons a larger one, both derived from it.
+	Radius float64 `json:"radius"`
+
+	Company Company `json:"company"`
+}
+
+type Palette struct {
+	Primary    Color `json:"primary"`
+	Accent     Color `json:"accent"`
+	OnPrimary  Color `json:"onPrimary"`
+	Text       Color `json:"text"`
+	Muted      Color `json:"muted"`
+	Surface    Color `json:"surface"`
+	Background Color `json:"background"`
+	Border     Color `json:"border"`
+	Rule       Color `json:"rule"`
+}
+
+type Fonts struct {
+	Body    string `json:"body"`
+	Heading string `json:"heading"`
+}
+
+// Company is the legal identity printed in the page footer.
+type Company struct {
+	Name    string   `json:"name"`
+	Address []string `json:"address"`
+	Phone   string   `json:"phone"`
+	Email   string   `json:"email"`
+}
+
+// Default is the Vigovia house style.
+var Default = Theme{
+	Name: "vigovia",
+	Palette: Palette{
+		Primary:    RGB(107, 70, 193),
+		Accent:     RGB(63, 45, 123),
+		OnPrimary:  RGB(255, 255, 255),
+		Text:       RGB(55, 65, 81),
+		Muted:      RGB(100, 100, 100),
+		Surface:    RGB(248, 250, 252),
+		Background: RGB(255, 255, 255),
+		Border:     RGB(220, 220, 220),
+		Rule:       RGB(200, 200, 200),
+	},
+	Fonts:    Fonts{Body: fonts.Sans, Heading: fonts.Sans},
+	Wordmark: "vigovia",
+	Tagline:  "PLAN.PACK.GO",
+	Radius:   5,
+	Company: Company{
+		Name: "Vigovia Tech Pvt. Ltd",
+		Address: []string{
+			"Registered Office: Hd-109 Cinnabar Hills,",
+			"Links Business Park, Karnataka, India.",
+		},
+		Phone: "+91-99X9999999",
+		Email: "contact@Vigovia.Com",
+	},
+}
+
+var (
+	mu     sync.RWMutex
+	themes = map[string]Theme{Default.Name: Default}
+)
+
+// Register adds or replaces a theme after filling any unset fields from
+// Default.
+func Register(t Theme) error {
+	t = withDefaults(t)
+	if err := validate(t); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	themes[t.Name] = t
+	return nil
+}
+
+// Get returns the named theme. An empty name returns Default.
+func Get(name string) (Theme, error) {
+	if name == "" {
+		return Default, nil
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+	t, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q", name)
+	}
+	return t, nil
+}
+
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadDir registers every *.json theme in dir. A missing directory is not an
+// error, so deployments without partner themes need no setup.
+func LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		t, err := loadFile(file)
+		if err != nil {
+			return fmt.Errorf("theme %s: %w", filepath.Base(file), err)
+		}
+		if err := Register(t); err != nil {
+			return fmt.Errorf("theme %s: %w", filepath.Base(file), err)
+		}
+	}
+	return nil
+}
+
+func loadFile(file string) (Theme, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return Theme{}, err
+	}
+
+	var t Theme
+	if err := json.Unmarshal(raw, &t); err != nil {
+		return Theme{}, err
+	}
+	if t.Name == "" {
+		t.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+
+	if t.Logo != "" {
+		logo := t.Logo
+		if !filepath.IsAbs(logo) {
+			logo = filepath.Join(filepath.Dir(file), logo)
+		}
+		t.LogoBytes, err = os.ReadFile(logo)
+		if err != nil {
+			return Theme{}, err
+		}
+		switch strings.ToLower(filepath.Ext(logo)) {
+		case ".png":
+			t.LogoType = "PNG"
+		case ".jpg", ".jpeg":
+			t.LogoType = "JPG"
+		default:
+			return Theme{}, fmt.Errorf("logo %s must be a PNG or JPEG", t.Logo)
+		}
+	}
+	return t, nil
+}
+
+func withDefaults(t Theme) Theme {
+	p, d := &t.Palette, Default.Palette
+	for _, pair := range []struct {
+		c   *Color
+		def Color
+	}{
+		{&p.Primary, d.Primary},
+		{&p.Accent, d.Accent},
+		{&p.OnPrimary, d.OnPrimary},
+		{&p.Text, d.Text},
+		{&p.Muted, d.Muted},
+		{&p.Surface, d.Surface},
+		{&p.Background, d.Background},
+		{&p.Border, d.Border},
+		{&p.Rule, d.Rule},
+	} {
+		if !pair.c.set {
+			*pair.c = pair.def
+		}
+	}
+
+	if t.Fonts.Body == "" {
+		t.Fonts.Body = Default.Fonts.Body
+	}
+	if t.Fonts.Heading == "" {
+		t.Fonts.Heading = t.Fonts.Body
+	}
+	if t.Radius <= 0 {
+		t.Radius = Default.Radius
+	}
+	if t.Company.Name == "" && len(t.Company.Address) == 0 {
+		t.Company = Default.Company
+	}
+	return t
+}
+
+func validate(t Theme) error {
+	if t.Name == "" {
+		return fmt.Errorf("theme has no name")
+	}
+	if t.Wordmark == "" && t.LogoBytes == nil {
+		return fmt.Errorf("theme %q needs a wordmark or a logo", t.Name)
+	}
+	for _, family := range []string{t.Fonts.Body, t.Fonts.Heading} {
+		if _, ok := fonts.Lookup(family); !ok {
+			return fmt.Errorf("theme %q: unknown font family %q", t.Name, family)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+// Image is an attached picture. In JSON it is base64, optionally written as a
+// data URI ("data:image/jpeg;base64,...").
+type Image []byte
+
+func (img *Image) UnmarshalJSON(raw []byte) error {
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return err
+	}
+	if rest, ok := strings.CutPrefix(s, "data:"); ok {
+		_, s, _ = strings.Cut(rest, ",")
+	}
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*img = data
+	return nil
+}
+
+func (img Image) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.StdEncoding.EncodeToString(img))
+}
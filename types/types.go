@@ -1,46 +1,235 @@
 package types
 
+// Document statuses. Only a confirmed itinerary is final; the others are
+// watermarked.
+const (
+	StatusQuote       = "quote"
+	StatusProvisional = "provisional"
+	StatusConfirmed   = "confirmed"
+	StatusCancelled   = "cancelled"
+)
+
+// Output variants.
+const (
+	VariantBooklet = "booklet"
+	VariantSummary = "summary"
+)
+
+// Calendar grids.
+const (
+	CalendarWeek  = "week"
+	CalendarMonth = "month"
+)
+
 type BookingData struct {
-	CustomerName  string   `json:"customerName"`
-	Destination   string   `json:"destination"`
-	DepartureFrom string   `json:"departureFrom"`
-	DepartureDate string   `json:"departureDate"`
-	ReturnDate    string   `json:"returnDate"`
-	Travelers     int      `json:"travelers"`
-	Days          []Day    `json:"days"`
-	Flights       []Flight `json:"flights"`
-	Hotels        []Hotel  `json:"hotels"`
-	TotalAmount   float64  `json:"totalAmount"`
-	Installment1  float64  `json:"installment1"`
-	Installment2  float64  `json:"installment2"`
+	BookingReference    string    `json:"bookingReference,omitempty" doc:"Agency booking reference, printed and encoded in the cover QR code."`
+	Status              string    `json:"status,omitempty" enum:"quote|provisional|confirmed|cancelled" binding:"omitempty,oneof=quote provisional confirmed cancelled" doc:"Document status. Anything but confirmed gets a watermark and a badge on the cover. Defaults to confirmed."`
+	IssueDate           string    `json:"issueDate,omitempty" format:"date" binding:"omitempty,datetime=2006-01-02" doc:"When the quote was issued; its valid-until date counts from here. Defaults to today, and is required for deterministic quotes."`
+	CustomerName        string    `json:"customerName"`
+	TravelerDOB         string    `json:"travelerDob,omitempty" format:"date" binding:"omitempty,datetime=2006-01-02" doc:"Lead traveller's date of birth, for PDF password rules that use {dob}."`
+	Destination         string    `json:"destination"`
+	DepartureFrom       string    `json:"departureFrom"`
+	DepartureLocation   *Location `json:"departureLocation,omitempty" doc:"Where the trip starts, for the route map."`
+	DestinationLocation *Location `json:"destinationLocation,omitempty" doc:"Used on the route map for hotels without their own location."`
+	TimeZone            string    `json:"timeZone,omitempty" binding:"omitempty,timezone" doc:"IANA time zone at the destination, like Asia/Singapore, for activities in the calendar export on days whose hotel has none. Defaults from the hotel city or the destination when it is a well-known place."`
+	DepartureDate       string    `json:"departureDate" format:"date"`
+	ReturnDate          string    `json:"returnDate" format:"date"`
+	Travelers           int       `json:"travelers"`
+	Days                []Day     `json:"days"`
+	Flights             []Flight  `json:"flights" binding:"dive"`
+	Hotels              []Hotel   `json:"hotels" binding:"dive"`
+	TotalAmount         float64   `json:"totalAmount"`
+	Installment1        float64   `json:"installment1"`
+	Installment2        float64   `json:"installment2"`
+	CheckoutURL         string    `json:"checkoutUrl,omitempty" format:"uri" binding:"omitempty,url" doc:"Where the Book Now button leads for this booking. Defaults to the API client's checkout URL."`
+	CoverImage          Image     `json:"coverImage,omitempty" doc:"Destination photo for the cover, as base64 or a data URI. JPEG, PNG, GIF and WebP are accepted."`
+	ItineraryURL        string    `json:"itineraryUrl,omitempty" format:"uri" binding:"omitempty,url" doc:"Online version of this itinerary, encoded in the cover QR code. Defaults to the API client's itinerary URL."`
+	Options             Options   `json:"options"`
+	Delivery            *Delivery `json:"delivery,omitempty" doc:"Email the generated PDF. Needs bookingReference, which the delivery is logged under."`
+}
+
+// Options controls how a booking is rendered rather than what it contains.
+type Options struct {
+	Variant         string   `json:"variant,omitempty" enum:"booklet|summary" binding:"omitempty,oneof=booklet summary" doc:"booklet is the full itinerary; summary condenses the trip onto one page and ignores template, sections and tableOfContents. Defaults to booklet."`
+	Template        string   `json:"template,omitempty" doc:"Layout template name; see GET /templates. Defaults to the API client's template, then to the built-in vigovia layout."`
+	TemplateVersion int      `json:"templateVersion,omitempty" binding:"omitempty,min=1" doc:"Template version. Defaults to the latest."`
+	Sections        []string `json:"sections,omitempty" doc:"Section names to render, in order, from the built-in sections and those the template declares. Defaults to the template's own order; see GET /sections."`
+	Calendar        string   `json:"calendar,omitempty" enum:"week|month" binding:"omitempty,oneof=week month" doc:"Grid for the calendar section: week rows covering just the trip, or whole months. Defaults to week."`
+	Theme           string   `json:"theme,omitempty" doc:"Theme name; see GET /themes. Defaults to the API client's theme, then to the house style."`
+	TableOfContents bool     `json:"tableOfContents,omitempty" doc:"Add a contents page with links to each section after the cover."`
+	PageSize        string   `json:"pageSize,omitempty" enum:"A4|Letter|A5|A3|Legal" binding:"omitempty,oneof=A4 Letter A5 A3 Legal" doc:"Paper size. Defaults to A4."`
+	Orientation     string   `json:"orientation,omitempty" enum:"portrait|landscape" binding:"omitempty,oneof=portrait landscape" doc:"Defaults to portrait."`
+	Archival        bool     `json:"archival,omitempty" doc:"Write a PDF/A-2b file for long-term storage."`
+	Deterministic   bool     `json:"deterministic,omitempty" doc:"Pin the creation date and identifiers so the same request always gives the same bytes."`
+	Password        string   `json:"password,omitempty" doc:"Encrypt the PDF with this password instead of the API client's password rule."`
+	Locale          string   `json:"locale,omitempty" enum:"en|hi|fr|de" binding:"omitempty,oneof=en hi fr de" doc:"Language of the labels, dates and numbers. Defaults to English."`
+}
+
+// Delivery is who a generated itinerary is emailed to.
+type Delivery struct {
+	To         []string `json:"to" binding:"required,min=1,dive,email" doc:"Traveller email addresses."`
+	AgentEmail string   `json:"agentEmail,omitempty" binding:"omitempty,email" doc:"The agent's address, copied on the email and where replies go."`
+	AgentName  string   `json:"agentName,omitempty" doc:"Signs the email."`
 }
 
 type Day struct {
-	Date       string     `json:"date"`
+	Date       string     `json:"date" format:"date"`
 	Activities []Activity `json:"activities"`
 }
 
 type Activity struct {
 	Time        string `json:"time"`
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Title       string `json:"title" doc:"Shown in the Activity Table. Supports the same Markdown as description."`
+	Description string `json:"description" doc:"Shown in the daily timeline. Supports **bold**, *italic*, \"- \" and \"1. \" lists and [links](https://example.com)."`
 	Duration    int    `json:"duration"`
 	Type        string `json:"type"`
+	City        string `json:"city,omitempty" doc:"Where the activity takes place, shown in the Activity Table. Defaults to the destination."`
+	Image       Image  `json:"image,omitempty" doc:"Thumbnail shown in the daily timeline, as base64 or a data URI."`
 }
 
 type Flight struct {
-	Date      string `json:"date"`
-	Airline   string `json:"airline"`
-	From      string `json:"from"`
-	To        string `json:"to"`
-	Arrival   string `json:"arrival"`
-	Departure string `json:"departure"`
+	Date         string    `json:"date" format:"date"`
+	Airline      string    `json:"airline"`
+	From         string    `json:"from"`
+	To           string    `json:"to"`
+	Arrival      string    `json:"arrival"`
+	Departure    string    `json:"departure"`
+	FromLocation *Location `json:"fromLocation,omitempty" doc:"Departure airport, for the route map."`
+	ToLocation   *Location `json:"toLocation,omitempty" doc:"Arrival airport, for the route map."`
+	FromTimeZone string    `json:"fromTimeZone,omitempty" binding:"omitempty,timezone" doc:"IANA time zone of the departure time, for the calendar export. Defaults from the from city or airport code when it is a well-known one."`
+	ToTimeZone   string    `json:"toTimeZone,omitempty" binding:"omitempty,timezone" doc:"IANA time zone of the arrival time. Defaults like fromTimeZone."`
 }
 
 type Hotel struct {
-	City     string `json:"city"`
-	CheckIn  string `json:"checkIn"`
-	CheckOut string `json:"checkOut"`
-	Nights   int    `json:"nights"`
-	Name     string `json:"name"`
+	City     string    `json:"city"`
+	CheckIn  string    `json:"checkIn" format:"date"`
+	CheckOut string    `json:"checkOut" format:"date"`
+	Nights   int       `json:"nights"`
+	Name     string    `json:"name"`
+	Image    Image     `json:"image,omitempty" doc:"Hotel photo shown in Hotel Bookings, as base64 or a data URI."`
+	Location *Location `json:"location,omitempty" doc:"For the route map."`
+	TimeZone string    `json:"timeZone,omitempty" binding:"omitempty,timezone" doc:"IANA time zone of the hotel, for activities during the stay in the calendar export. Defaults to the booking's timeZone, then from the city when it is a well-known one."`
+}
+
+// Location is a position in decimal degrees.
+type Location struct {
+	Lat float64 `json:"lat" binding:"min=-90,max=90"`
+	Lon float64 `json:"lon" binding:"min=-180,max=180"`
+}
+
+type GenerateResponse struct {
+	Message string `json:"message"`
+	URL     string `json:"url"`
+	// Delivery is set when the request asked for the PDF to be emailed.
+	Delivery *DeliveryStatus `json:"delivery,omitempty"`
+}
+
+// Delivery statuses.
+const (
+	DeliverySent   = "sent"
+	DeliveryFailed = "failed"
+)
+
+// DeliveryStatus records one attempt to email an itinerary.
+type DeliveryStatus struct {
+	Time      string   `json:"time" format:"date-time"`
+	Status    string   `json:"status" enum:"sent|failed"`
+	To        []string `json:"to"`
+	Cc        []string `json:"cc,omitempty"`
+	Subject   string   `json:"subject"`
+	Attached  bool     `json:"attached" doc:"Whether the PDF was attached. PDFs over the server's size limit are linked at url instead."`
+	URL       string   `json:"url,omitempty"`
+	MessageID string   `json:"messageId,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+type DeliveriesResponse struct {
+	Deliveries []DeliveryStatus `json:"deliveries"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type ThemesResponse struct {
+	Available []string `json:"available"`
+}
+
+type SectionsResponse struct {
+	Available []string `json:"available"`
+	Default   []string `json:"default"`
+}
+
+type TemplatesResponse struct {
+	Available []TemplateInfo `json:"available"`
+}
+
+type TemplateInfo struct {
+	Name        string   `json:"name"`
+	Version     int      `json:"version"`
+	Description string   `json:"description,omitempty"`
+	Sections    []string `json:"sections"`
+}
+
+// ImportForm is the multipart form POST /import takes. Every file is
+// optional, but at least one is needed.
+type ImportForm struct {
+	File          string        `json:"file,omitempty" format:"binary" doc:"An XLSX workbook with sheets of activities, flights and hotels, or a CSV file of activities."`
+	Activities    string        `json:"activities,omitempty" format:"binary" doc:"A CSV file of activities."`
+	Flights       string        `json:"flights,omitempty" format:"binary" doc:"A CSV file of flights."`
+	Hotels        string        `json:"hotels,omitempty" format:"binary" doc:"A CSV file of hotel stays."`
+	Mapping       ImportMapping `json:"mapping,omitempty" doc:"JSON. Which sheets and columns hold which fields, where they differ from the defaults."`
+	DepartureDate string        `json:"departureDate,omitempty" format:"date" doc:"Dates activities given only a day number, day 1 being this date."`
+}
+
+// ImportMapping picks the sheet and columns of each kind of row.
+type ImportMapping struct {
+	Activities SheetMapping `json:"activities,omitempty" doc:"Fields: day, date, time, title, description, duration, type, city."`
+	Flights    SheetMapping `json:"flights,omitempty" doc:"Fields: date, airline, from, to, departure, arrival."`
+	Hotels     SheetMapping `json:"hotels,omitempty" doc:"Fields: city, name, checkIn, checkOut, nights."`
+}
+
+type SheetMapping struct {
+	Sheet   string            `json:"sheet,omitempty" doc:"XLSX sheet name. Defaults to a sheet named like the kind of row, e.g. Activities, Flights or Hotels."`
+	Columns map[string]string `json:"columns,omitempty" doc:"Column header of each field, like {\"title\": \"Activity Name\"}. Fields left out are found by their usual headers."`
+}
+
+// ImportError is a problem with one row, or with a whole sheet when Row is
+// 0.
+type ImportError struct {
+	Sheet  string `json:"sheet"`
+	Row    int    `json:"row,omitempty" doc:"Row number as the spreadsheet shows it."`
+	Column string `json:"column,omitempty"`
+	Error  string `json:"error"`
+}
+
+// ImportResponse has the rows that could be read. Rows with errors are
+// left out and listed in Errors.
+type ImportResponse struct {
+	Days    []Day         `json:"days"`
+	Flights []Flight      `json:"flights"`
+	Hotels  []Hotel       `json:"hotels"`
+	Errors  []ImportError `json:"errors"`
+}
+
+// FlightTextRequest is itinerary text to read flights from.
+type FlightTextRequest struct {
+	Text          string `json:"text" binding:"required" doc:"GDS segment lines, like \"1 AI 380 Y 15JUN 7 DELSIN HK2 0130 0940\", or the flight lines of an e-ticket, one flight a line. Other lines are ignored."`
+	ReferenceDate string `json:"referenceDate,omitempty" format:"date" binding:"omitempty,datetime=2006-01-02" doc:"Dates without a year, like 15JUN, are the first such date on or after this one. Defaults to today."`
+}
+
+// FlightTextProblem is a line that looks like a flight but was not read as
+// one.
+type FlightTextProblem struct {
+	Line  int    `json:"line" doc:"Line number, from 1."`
+	Text  string `json:"text"`
+	Error string `json:"error"`
+}
+
+// FlightTextResponse has the flights that could be read. Lines that could
+// be read more than one way, or miss something, are left out and listed in
+// Problems.
+type FlightTextResponse struct {
+	Flights  []Flight            `json:"flights"`
+	Problems []FlightTextProblem `json:"problems"`
 }
@@ -1,21 +1,12 @@
 package utils
 
 import (
-	"fmt"
 	"log"
+	"strconv"
 	"strings"
 	"time"
 )
 
-func FormatDate(date string) string {
-	parsedDate, err := time.Parse("2006-01-02", date)
-	if err != nil {
-		log.Println("Error parsing date:", err)
-		return ""
-	}
-	return parsedDate.Format("02 Jan, 2006")
-}
-
 func CalculateNights(departureDate string, returnDate string) int {
 	departure, err1 := time.Parse("2006-01-02", departureDate)
 	returnDateParsed, err2 := time.Parse("2006-01-02", returnDate)
@@ -47,33 +38,47 @@ func SanitizeFileName(name string) string {
 	return res
 }
 
-func FormatDuration(duration int, timeSlot string) string {
-	if duration > 0 {
-		if duration >= 60 {
-			hours := duration / 60
-			minutes := duration % 60
-			if minutes == 0 {
-				if hours == 1 {
-					return "1 Hour"
-				}
-				return fmt.Sprintf("%d Hours", hours)
+// ParseClock reads an activity or flight time like "09:30" or "9:30 PM" as
+// the time after midnight. Time slots like "Morning" get a typical hour.
+func ParseClock(clock string) (time.Duration, bool) {
+	s := strings.ToLower(strings.TrimSpace(clock))
+	offset := -1
+	for suffix, hours := range map[string]int{"am": 0, "pm": 12} {
+		if rest, ok := strings.CutSuffix(s, suffix); ok {
+			s, offset = strings.TrimSpace(rest), hours
+		}
+	}
+	h, m, hasMinutes := strings.Cut(s, ":")
+	if hasMinutes || offset >= 0 {
+		if !hasMinutes {
+			m = "0"
+		}
+		hour, err1 := strconv.Atoi(h)
+		minute, err2 := strconv.Atoi(m)
+		if err1 != nil || err2 != nil || minute < 0 || minute > 59 {
+			return 0, false
+		}
+		if offset >= 0 {
+			if hour < 1 || hour > 12 {
+				return 0, false
 			}
-			return fmt.Sprintf("%d:%02d Hours", hours, minutes)
+			hour = hour%12 + offset
+		}
+		if hour < 0 || hour > 23 {
+			return 0, false
 		}
-		return fmt.Sprintf("%d Minutes", duration)
+		return time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute, true
 	}
 
-	// If no duration specified, try to infer from time slot
-	timeSlot = strings.ToLower(timeSlot)
-	if strings.Contains(timeSlot, "morning") || strings.Contains(timeSlot, "afternoon") || strings.Contains(timeSlot, "evening") {
-		return "2-3 Hours"
+	switch {
+	case strings.Contains(s, "morning"):
+		return 9 * time.Hour, true
+	case strings.Contains(s, "afternoon"):
+		return 14 * time.Hour, true
+	case strings.Contains(s, "evening"):
+		return 18 * time.Hour, true
+	case strings.Contains(s, "night"):
+		return 21 * time.Hour, true
 	}
-	if strings.Contains(timeSlot, "full day") || strings.Contains(timeSlot, "all day") {
-		return "Full Day"
-	}
-	if strings.Contains(timeSlot, "half day") {
-		return "Half Day"
-	}
-
-	return "2-3 Hours"
+	return 0, false
 }
@@ -0,0 +1,379 @@
+// Package web writes an itinerary as a single responsive HTML page, for
+// reading on phones where PDFs are painful. It renders the same
+// render.Document the PDF sections draw from, with the theme's colours, so
+// the two formats always say the same thing.
+package web
+
+import (
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/monoMonu/travel-itinerary-pdf/i18n"
+	"github.com/monoMonu/travel-itinerary-pdf/markdown"
+	"github.com/monoMonu/travel-itinerary-pdf/render"
+	"github.com/monoMonu/travel-itinerary-pdf/theme"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// The route map is plotted in the PDF's units, millimetres, and scaled by
+// the SVG's viewBox.
+const (
+	mapWidth  = 180.0
+	mapHeight = 100.0
+)
+
+//go:embed templates/itinerary.html
+var files embed.FS
+
+var page = template.Must(template.New("itinerary.html").Funcs(template.FuncMap{
+	"kind":       kind,
+	"hex":        hex,
+	"markdown":   markdownHTML,
+	"image":      imageURL,
+	"logo":       logoURL,
+	"qr":         qrURL,
+	"trusted":    trusted,
+	"tel":        tel,
+	"plot":       plot,
+	"points":     points,
+	"anchor":     anchor,
+	"align":      align,
+	"alignAt":    alignAt,
+	"localized":  localized,
+	"mapView":    newMapView,
+	"columns":    columns,
+	"fontStyle":  fontStyle,
+	"widths":     widths,
+	"axis":       axis,
+	"span":       span,
+	"markClass":  markClass,
+	"lineClass":  lineClass,
+	"legendKind": legendKind,
+}).ParseFS(files, "templates/itinerary.html"))
+
+// Write writes doc as a standalone page: styles, pictures and the map are
+// inline, so the file can be served or saved as it is.
+func Write(w io.Writer, doc *render.Document) error {
+	var buf bytes.Buffer
+	if err := page.Execute(&buf, doc); err != nil {
+		return fmt.Errorf("html: %w", err)
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// kind names the template that shows a section's content.
+func kind(c render.Content) string {
+	switch c.(type) {
+	case *render.Cover:
+		return "cover"
+	case *render.Contents:
+		return "contents"
+	case *render.TripRoute:
+		return "route"
+	case *render.DailyPlan:
+		return "daily"
+	case *render.FlightSummary:
+		return "flights"
+	case *render.TableContent:
+		return "table"
+	case *render.PaymentPlan:
+		return "payment"
+	case *render.Facts:
+		return "facts"
+	case *render.Link:
+		return "link"
+	case *render.Closing:
+		return "closing"
+	case *render.Calendar:
+		return "calendar"
+	case *render.Timeline:
+		return "timeline"
+	case *render.Summary:
+		return "summary"
+	case *render.Declared:
+		return "declared"
+	}
+	return ""
+}
+
+func hex(c theme.Color) template.CSS {
+	return template.CSS(c.Hex())
+}
+
+// markdownHTML renders the Markdown subset. Links have been checked by the
+// parser, and everything else is escaped.
+func markdownHTML(s string) template.HTML {
+	var b strings.Builder
+	list := ""
+	for _, block := range markdown.Parse(s) {
+		tag := ""
+		switch {
+		case block.Marker == markdown.Bullet:
+			tag = "ul"
+		case block.Marker != "":
+			tag = "ol"
+		}
+		if tag != list {
+			if list != "" {
+				b.WriteString("</" + list + ">")
+			}
+			if tag != "" {
+				b.WriteString("<" + tag + ">")
+			}
+			list = tag
+		}
+		if tag == "" {
+			b.WriteString("<p>")
+		} else {
+			b.WriteString("<li>")
+		}
+		for _, span := range block.Spans {
+			text := template.HTMLEscapeString(span.Text)
+			if span.Bold {
+				text = "<strong>" + text + "</strong>"
+			}
+			if span.Italic {
+				text = "<em>" + text + "</em>"
+			}
+			if span.Link != "" {
+				text = `<a href="` + template.HTMLEscapeString(span.Link) + `" rel="noopener">` + text + "</a>"
+			}
+			b.WriteString(text)
+		}
+		if tag == "" {
+			b.WriteString("</p>")
+		} else {
+			b.WriteString("</li>")
+		}
+	}
+	if list != "" {
+		b.WriteString("</" + list + ">")
+	}
+	return template.HTML(b.String())
+}
+
+// imageURL inlines a picture as a data URL.
+func imageURL(img []byte) template.URL {
+	return dataURL(http.DetectContentType(img), img)
+}
+
+func logoURL(t theme.Theme) template.URL {
+	return dataURL(t.LogoType, t.LogoBytes)
+}
+
+func dataURL(contentType string, data []byte) template.URL {
+	if !strings.Contains(contentType, "/") {
+		contentType = "image/" + strings.ToLower(contentType)
+	}
+	return template.URL("data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(data))
+}
+
+// qrURL is content as a QR code image.
+func qrURL(content string) (template.URL, error) {
+	png, err := qrcode.Encode(content, qrcode.Medium, 256)
+	if err != nil {
+		return "", fmt.Errorf("qr code: %w", err)
+	}
+	return dataURL("image/png", png), nil
+}
+
+// trusted passes the links the document builds itself, like UPI payment
+// requests, which html/template would otherwise reject for their scheme.
+func trusted(url string) template.URL {
+	return template.URL(url)
+}
+
+func tel(phone string) template.URL {
+	return template.URL("tel:" + strings.Join(strings.Fields(phone), ""))
+}
+
+// localizedContent gives a section's template the locale, for sections
+// that format more than their content has formatted already.
+type localizedContent struct {
+	Locale  *i18n.Locale
+	Content render.Content
+}
+
+func localized(l *i18n.Locale, c render.Content) localizedContent {
+	return localizedContent{l, c}
+}
+
+type mapView struct {
+	Locale *i18n.Locale
+	Map    *render.RouteMap
+	Small  bool
+}
+
+func newMapView(l *i18n.Locale, m *render.RouteMap, small bool) mapView {
+	return mapView{l, m, small}
+}
+
+func plot(m *render.RouteMap, l *i18n.Locale, small bool) render.RoutePlot {
+	return m.Plot(l, 0, 0, mapWidth, mapHeight, small)
+}
+
+// points is an SVG points attribute.
+func points(ps []render.RoutePoint) string {
+	s := make([]string, len(ps))
+	for i, p := range ps {
+		s[i] = fmt.Sprintf("%.2f,%.2f", p.X, p.Y)
+	}
+	return strings.Join(s, " ")
+}
+
+// anchor is the fragment a section is linked by.
+func anchor(name string) string {
+	return "section-" + name
+}
+
+func align(a string) string {
+	switch a {
+	case "R":
+		return "right"
+	case "C":
+		return "center"
+	}
+	return "left"
+}
+
+// alignAt is the alignment of column i of a summary line, L by default.
+func alignAt(aligns string, i int) string {
+	if i < len(aligns) {
+		return align(aligns[i : i+1])
+	}
+	return align("L")
+}
+
+// columns is the CSS grid of a summary line.
+func columns(fractions []float64) template.CSS {
+	if len(fractions) == 0 {
+		return "1fr"
+	}
+	s := make([]string, len(fractions))
+	for i, f := range fractions {
+		s[i] = fmt.Sprintf("%gfr", f*100)
+	}
+	return template.CSS(strings.Join(s, " "))
+}
+
+// fontStyle turns B, I and U into CSS.
+func fontStyle(style string) template.CSS {
+	var css []string
+	if strings.Contains(style, "B") {
+		css = append(css, "font-weight: bold")
+	}
+	if strings.Contains(style, "I") {
+		css = append(css, "font-style: italic")
+	}
+	if strings.Contains(style, "U") {
+		css = append(css, "text-decoration: underline")
+	}
+	return template.CSS(strings.Join(css, "; "))
+}
+
+// widths are column weights as percentages.
+func widths(weights []float64) []string {
+	total := 0.0
+	for _, w := range weights {
+		total += w
+	}
+	s := make([]string, len(weights))
+	for i, w := range weights {
+		s[i] = fmt.Sprintf("%.2f%%", w/total*100)
+	}
+	return s
+}
+
+// axisDay is one date column of the timeline.
+type axisDay struct {
+	Span    template.CSS
+	Day     int
+	Month   string
+	Weekend bool
+}
+
+func axis(t *render.Timeline, l *i18n.Locale) []axisDay {
+	days := make([]axisDay, t.Days)
+	for i := range days {
+		date := t.First.AddDate(0, 0, i)
+		days[i] = axisDay{
+			Span:    span(t, date, date.AddDate(0, 0, 1)),
+			Day:     date.Day(),
+			Weekend: date.Weekday() == time.Saturday || date.Weekday() == time.Sunday,
+		}
+		if i == 0 || date.Day() == 1 {
+			days[i].Month = fmt.Sprintf("%s %d", l.Months[date.Month()-1], date.Year())
+		}
+	}
+	return days
+}
+
+// span places a mark on the timeline. Marks without an end are points.
+func span(t *render.Timeline, from, to time.Time) template.CSS {
+	at := func(d time.Time) float64 {
+		return d.Sub(t.First).Hours() / 24 / float64(t.Days) * 100
+	}
+	left := at(from)
+	if to.IsZero() {
+		return template.CSS(fmt.Sprintf("left: %.3f%%", left))
+	}
+	return template.CSS(fmt.Sprintf("left: %.3f%%; width: %.3f%%", left, min(at(to), 100)-left))
+}
+
+func markClass(k render.MarkKind) string {
+	switch k {
+	case render.MarkFlight:
+		return "flight"
+	case render.MarkTransfer:
+		return "transfer"
+	case render.MarkStay:
+		return "stay"
+	case render.MarkStayOverlap:
+		return "stay-overlap"
+	case render.MarkNight:
+		return "night"
+	case render.MarkAloft:
+		return "aloft"
+	case render.MarkOverlap:
+		return "overlap"
+	case render.MarkGap:
+		return "gap"
+	}
+	return "activity"
+}
+
+func lineClass(k render.CalendarLineKind) string {
+	switch k {
+	case render.CalendarFlight:
+		return "flight"
+	case render.CalendarHotel:
+		return "hotel"
+	case render.CalendarProblem:
+		return "problem"
+	}
+	return "activity"
+}
+
+func legendKind(k render.LegendKind) string {
+	switch k {
+	case render.LegendOutline:
+		return "outline"
+	case render.LegendDot:
+		return "dot"
+	case render.LegendArrow:
+		return "arrow"
+	case render.LegendLine:
+		return "line"
+	case render.LegendDash:
+		return "dash"
+	}
+	return "fill"
+}
@@ -0,0 +1,75 @@
+package web
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"testing"
+
+	"github.com/monoMonu/travel-itinerary-pdf/render"
+	"github.com/monoMonu/travel-itinerary-pdf/types"
+)
+
+func TestMarkdownHTML(t *testing.T) {
+	tests := []struct {
+		name, in string
+		want     template.HTML
+	}{
+		{"text", "Tea & <b>cake</b>", "<p>Tea &amp; &lt;b&gt;cake&lt;/b&gt;</p>"},
+		{"emphasis", "**Gardens** by *night*", "<p><strong>Gardens</strong> by <em>night</em></p>"},
+		{"script", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"link", "[Book](https://vigovia.test/?a=1&b=2)", `<p><a href="https://vigovia.test/?a=1&amp;b=2" rel="noopener">Book</a></p>`},
+		{"quote in link", `[x](https://a.test/"onmouseover="alert(1))`, `<p><a href="https://a.test/&#34;onmouseover=&#34;alert(1)" rel="noopener">x</a></p>`},
+		{"javascript link", "[x](javascript:alert(1))", "<p>x</p>"},
+		{"lists", "- a\n- b\n1. c", "<ul><li>a</li><li>b</li></ul><ol><li>c</li></ol>"},
+		{"text then list", "Bring:\n- a", "<p>Bring:</p><ul><li>a</li></ul>"},
+	}
+	for _, tt := range tests {
+		if got := markdownHTML(tt.in); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+// Booking text reaches the page escaped wherever it is shown, and only
+// safe links survive.
+func TestWriteEscapesBooking(t *testing.T) {
+	data := types.BookingData{
+		BookingReference: "VG<1>",
+		CustomerName:     `<script>alert("name")</script>`,
+		Destination:      "Singapore & Bali",
+		DepartureDate:    "2025-06-15",
+		ReturnDate:       "2025-06-16",
+		Days: []types.Day{{Date: "2025-06-15", Activities: []types.Activity{{
+			Time:        "09:00",
+			Title:       `<img src=x onerror="alert(1)">`,
+			Description: "[Tickets](https://vigovia.test/t?a=1&b=2) or [this](javascript:alert(2))",
+		}}}},
+		Options: types.Options{Deterministic: true},
+	}
+	doc, err := render.NewDocument(data, render.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Write(&buf, doc); err != nil {
+		t.Fatal(err)
+	}
+	page := buf.String()
+
+	for _, s := range []string{"<script>alert", "<img src=x", "javascript:", "VG<1>"} {
+		if strings.Contains(page, s) {
+			t.Errorf("page contains %q", s)
+		}
+	}
+	for _, s := range []string{
+		"&lt;script&gt;alert(&#34;name&#34;)&lt;/script&gt;",
+		"&lt;img src=x onerror=&#34;alert(1)&#34;&gt;",
+		"Singapore &amp; Bali",
+		`<a href="https://vigovia.test/t?a=1&amp;b=2" rel="noopener">Tickets</a>`,
+	} {
+		if !strings.Contains(page, s) {
+			t.Errorf("page does not contain %q", s)
+		}
+	}
+}